@@ -1,17 +1,91 @@
 package lib
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync/atomic"
 
 	"github.com/eftil/sudoku-solver.git/lib/logger"
 	"github.com/eftil/sudoku-solver.git/lib/observer"
-	"github.com/eftil/sudoku-solver.git/lib/utils"
 )
 
+// DefaultSize is the side length of a classic board
+const DefaultSize = 9
+
+// boxShapes lists the supported board sizes with the rows and columns of their boxes
+var boxShapes = map[int][2]int{
+	4: {2, 2},
+	6: {2, 3},
+	9: {3, 3},
+}
+
+// BoxShape returns the box dimensions for a supported board size: 2x2 for 4, 2x3 for 6
+// and 3x3 for 9
+func BoxShape(size int) (rows, cols int, err error) {
+	shape, ok := boxShapes[size]
+	if !ok {
+		return 0, 0, &BoardError{Message: fmt.Sprintf("unsupported board size %d (supported: 4, 6, 9)", size)}
+	}
+	return shape[0], shape[1], nil
+}
+
+// Board holds the cells of a size x size grid. Cells are always stored row by row with a
+// stride of 9, so index row*9+col means the same cell on every board size; smaller boards
+// leave the slots outside their grid nil.
 type Board struct {
 	board       [81]*Cell
 	constraints []Constraint
 	observers   []observer.CellObserver
+
+	// size is the number of rows, columns and digits; boxRows x boxCols is the box shape
+	size    int
+	boxRows int
+	boxCols int
+
+	// cellConstraints indexes, for each cell, the constraints that include it
+	cellConstraints [81][]Constraint
+
+	// assumeUnique enables techniques that rely on the puzzle having exactly one solution
+	assumeUnique bool
+
+	// rng drives randomized operations such as MinimalClues; see SetRandomSeed
+	rng *rand.Rand
+
+	// shuffleSearch makes the search try candidates in random order, to generate
+	// random solutions
+	shuffleSearch bool
+
+	// quiet limits the board's logging to errors. Internal work boards (searches, dry
+	// runs) are quiet, so they don't flood the log without touching the global level.
+	quiet bool
+
+	// peers caches which cells share a uniqueness constraint, and peerCells the same as
+	// a list per cell in index order; rebuilt lazily after constraints are added or removed
+	peers      [81][81]bool
+	peerCells  [81][]*Cell
+	peersBuilt bool
+
+	// finnedFishSizes lists the finned fish tried by the advanced techniques;
+	// nil means the defaults, see SetFinnedFishSizes
+	finnedFishSizes []int
+
+	// candidateOrder is the display order of candidates; nil means ascending
+	candidateOrder []int
+
+	// lastSolve is the trace of the most recent logical solve, see StepCount
+	lastSolve SolveResult
+
+	// historyEnabled makes Set record moves for Undo and Redo; see SetHistoryEnabled
+	historyEnabled bool
+	undoStack      []move
+	redoStack      []move
+
+	// validating counts the ValidateAllConcurrent calls in progress; Set refuses to
+	// change the board while it is non-zero
+	validating atomic.Int32
 }
 
 // BoardError represents errors from board operations
@@ -23,39 +97,175 @@ func (e *BoardError) Error() string {
 	return e.Message
 }
 
-// NewBoard creates a new board with all cells initialized
+// NewBoard creates a new classic 9x9 board with all cells initialized
 func NewBoard() *Board {
-	logger.Info("Creating new Sudoku board...")
+	return newLoggedBoard(DefaultSize, 3, 3)
+}
 
+// NewBoardOfSize creates a size x size board using the digits 1 to size, with boxes
+// shaped as returned by BoxShape. Constraints are not added; use
+// constraints.AddStandardConstraints, which follows the board's size.
+func NewBoardOfSize(size int) (*Board, error) {
+	rows, cols, err := BoxShape(size)
+	if err != nil {
+		logger.Error("Cannot create board: %v", err)
+		return nil, err
+	}
+	return newLoggedBoard(size, rows, cols), nil
+}
+
+// newLoggedBoard is newBoard for the public constructors, logging the creation
+func newLoggedBoard(size, boxRows, boxCols int) *Board {
+	logger.Info("Creating new %dx%d Sudoku board...", size, size)
+	b := newBoard(size, boxRows, boxCols, false)
+	logger.Info("Board created successfully with %d cells", size*size)
+	return b
+}
+
+// newBoard creates a board of the given size and box shape with all cells initialized.
+// quiet is set before the cells are created, so a quiet board logs nothing from the start.
+func newBoard(size, boxRows, boxCols int, quiet bool) *Board {
 	b := &Board{
 		observers: make([]observer.CellObserver, 0),
+		size:      size,
+		boxRows:   boxRows,
+		boxCols:   boxCols,
+		quiet:     quiet,
 	}
 
 	// Initialize all cells
-	for row := 0; row < 9; row++ {
-		for col := 0; col < 9; col++ {
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
 			b.board[row*9+col] = NewCell(row, col, b)
 		}
 	}
 
-	logger.Info("Board created successfully with 81 cells")
 	return b
 }
 
+// Size returns the number of rows, columns and digits of the board
+func (b *Board) Size() int {
+	return b.size
+}
+
+// BoxShape returns the number of rows and columns of each box
+func (b *Board) BoxShape() (rows, cols int) {
+	return b.boxRows, b.boxCols
+}
+
+// BoxIndex returns the box (0 to size-1, row by row) containing the cell at (row, col)
+func (b *Board) BoxIndex(row, col int) int {
+	return (row/b.boxRows)*(b.size/b.boxCols) + col/b.boxCols
+}
+
+// fullMask is the candidate mask with every digit of the board set
+func (b *Board) fullMask() uint16 {
+	return allCandidates & (1<<uint(b.size+1) - 1)
+}
+
+// isUnit reports whether a constraint is a unit: a uniqueness constraint covering as many
+// cells as there are digits (a row, column, box or jigsaw region), so every digit must
+// appear in it exactly once
+func (b *Board) isUnit(c Constraint) bool {
+	return c.RequiresUniqueness() && len(c.GetCells()) == b.size
+}
+
+// Set places a value in the cell at (row, col), or clears it with 0, letting constraints
+// propagate. Givens cannot be changed or cleared; see ForceSet.
 func (b *Board) Set(row, col, value int) error {
-	if row < 0 || row > 8 || col < 0 || col > 8 {
-		logger.Error("Invalid board position: row=%d, col=%d", row, col)
+	if row < 0 || row >= b.size || col < 0 || col >= b.size {
+		b.log().Error("Invalid board position: row=%d, col=%d", row, col)
 		return &BoardError{Message: fmt.Sprintf("invalid position: row=%d, col=%d", row, col)}
 	}
 
+	if value > b.size {
+		b.log().Error("Invalid value %d for a %dx%d board", value, b.size, b.size)
+		return &BoardError{Message: fmt.Sprintf("value must be between 0 and %d, got %d", b.size, value)}
+	}
+
+	if b.validating.Load() > 0 {
+		b.log().Error("Cannot set R%dC%d while the board is being validated concurrently", row+1, col+1)
+		return &BoardError{Message: "board is being validated concurrently"}
+	}
+
 	// Initialize cell if it doesn't exist
 	if b.board[row*9+col] == nil {
-		logger.Debug("Initializing missing cell at R%dC%d", row+1, col+1)
+		b.log().Debug("Initializing missing cell at R%dC%d", row+1, col+1)
 		b.board[row*9+col] = NewCell(row, col, b)
 	}
 
-	logger.Info("Setting cell R%dC%d to value %d", row+1, col+1, value)
-	return b.board[row*9+col].SetValue(value)
+	if cell := b.board[row*9+col]; cell.IsGiven() && value != cell.GetValue() {
+		b.log().Warn("Refusing to change given R%dC%d = %d", row+1, col+1, cell.GetValue())
+		return &BoardError{Message: fmt.Sprintf("R%dC%d is a given and cannot be changed without ForceSet", row+1, col+1)}
+	}
+
+	b.log().Info("Setting cell R%dC%d to value %d", row+1, col+1, value)
+	if !b.historyEnabled {
+		return b.board[row*9+col].SetValue(value)
+	}
+
+	before := b.saveState()
+	err := b.board[row*9+col].SetValue(value)
+	b.recordMove(row, col, before)
+	return err
+}
+
+// SetMany places several values, keyed by cell index (0-80), with one Set call each in
+// index order, so observers and constraint propagation run per cell as usual. A value
+// that can't be placed doesn't stop the rest: every failure is collected into the
+// returned error, one "cell N: ..." entry per cell, and errors.As finds the BoardErrors.
+func (b *Board) SetMany(values map[int]int) error {
+	indices := make([]int, 0, len(values))
+	for idx := range values {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	var errs []error
+	for _, idx := range indices {
+		if idx < 0 || idx > 80 {
+			errs = append(errs, fmt.Errorf("cell %d: %w", idx, &BoardError{Message: "invalid cell index (must be 0-80)"}))
+			continue
+		}
+		if err := b.Set(idx/9, idx%9, values[idx]); err != nil {
+			errs = append(errs, fmt.Errorf("cell %d: %w", idx, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		b.log().Warn("SetMany could not place %d of %d value(s)", len(errs), len(values))
+	}
+	return errors.Join(errs...)
+}
+
+// SetGiven places a value like Set and marks it as an original clue of the puzzle
+func (b *Board) SetGiven(row, col, value int) error {
+	if value < 1 || value > b.size {
+		return &BoardError{Message: fmt.Sprintf("given must be between 1 and %d, got %d", b.size, value)}
+	}
+	if err := b.Set(row, col, value); err != nil {
+		return err
+	}
+	b.board[row*9+col].isGiven = true
+	return nil
+}
+
+// ForceSet is Set for cells that may be givens: the cell stops being a given and takes
+// the new value, or is cleared with 0. If Set fails the cell stays a given.
+func (b *Board) ForceSet(row, col, value int) error {
+	cell := b.GetCellAt(row, col)
+	if cell == nil || !cell.IsGiven() {
+		return b.Set(row, col, value)
+	}
+
+	previous := cell.GetValue()
+	cell.isGiven = false
+	if err := b.Set(row, col, value); err != nil {
+		cell.isGiven = true
+		return err
+	}
+	b.log().Info("Overrode given R%dC%d = %d", row+1, col+1, previous)
+	return nil
 }
 
 func (b *Board) Get(row, col int) int {
@@ -84,17 +294,324 @@ func (b *Board) GetCellAt(row, col int) *Cell {
 	return b.board[row*9+col]
 }
 
+// ForEachCell calls fn for every cell in row-major order
+func (b *Board) ForEachCell(fn func(row, col int, cell *Cell)) {
+	for idx, cell := range b.board {
+		if cell != nil {
+			fn(idx/9, idx%9, cell)
+		}
+	}
+}
+
+// ForEachUnsolved calls fn for every cell without a value, in row-major order
+func (b *Board) ForEachUnsolved(fn func(cell *Cell)) {
+	for _, cell := range b.board {
+		if cell != nil && !cell.IsSolved() {
+			fn(cell)
+		}
+	}
+}
+
+// GetEmptyCells returns the cells without a value, in index order
+func (b *Board) GetEmptyCells() []*Cell {
+	var cells []*Cell
+	b.ForEachUnsolved(func(cell *Cell) {
+		cells = append(cells, cell)
+	})
+	return cells
+}
+
+// GetSolvedCells returns the cells with a value, givens included, in index order
+func (b *Board) GetSolvedCells() []*Cell {
+	var cells []*Cell
+	for _, cell := range b.board {
+		if cell != nil && cell.IsSolved() {
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
+
+// EmptyCount returns the number of cells without a value
+func (b *Board) EmptyCount() int {
+	count := 0
+	b.ForEachUnsolved(func(cell *Cell) {
+		count++
+	})
+	return count
+}
+
+// FilledCount returns the number of cells with a value, givens included
+func (b *Board) FilledCount() int {
+	return b.size*b.size - b.EmptyCount()
+}
+
+// Progress returns the fraction of cells filled, from 0.0 for an empty board to 1.0 for
+// a full one, for showing solving progress. TotalCandidates also drops while only
+// candidates are being eliminated.
+func (b *Board) Progress() float64 {
+	return float64(b.FilledCount()) / float64(b.size*b.size)
+}
+
+// LoadString loads a puzzle from 81 characters read row by row (size*size on smaller
+// boards), where 1-9 are givens and 0 or '.' mark empty cells. Whitespace and newlines
+// are skipped, so a grid split over several lines works too. The whole string is
+// checked before anything is set; each given then goes through SetGiven, so
+// constraints propagate, observers fire and the cell is marked as a given.
+// Givens that break a constraint are loaded anyway and logged as warnings; use
+// ValidateAllDetailed to find the conflicting cells.
+func (b *Board) LoadString(s string) error {
+	values := make([]int, 0, 81)
+	for i, ch := range s {
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			continue
+		case ch == '0' || ch == '.':
+			values = append(values, 0)
+		case ch >= '1' && ch <= rune('0'+b.size):
+			values = append(values, int(ch-'0'))
+		default:
+			return &BoardError{Message: fmt.Sprintf("invalid character %q at position %d", ch, i)}
+		}
+	}
+
+	if len(values) != b.size*b.size {
+		return &BoardError{Message: fmt.Sprintf("puzzle must have %d cells, got %d", b.size*b.size, len(values))}
+	}
+
+	b.log().Info("Loading puzzle from string...")
+	for idx, value := range values {
+		if value == 0 {
+			continue
+		}
+		if err := b.SetGiven(idx/b.size, idx%b.size, value); err != nil {
+			return err
+		}
+	}
+
+	if violations, err := b.ValidateAllDetailed(); err == nil && len(violations) > 0 {
+		b.log().Warn("Loaded puzzle breaks %d constraint(s)", len(violations))
+	}
+	return nil
+}
+
+// String returns the current values as 81 characters row by row (size*size on smaller
+// boards), with '.' for empty cells. The result can be loaded again with LoadString.
+func (b *Board) String() string {
+	var sb strings.Builder
+	for _, cell := range b.board {
+		if cell == nil {
+			continue
+		}
+		if cell.GetValue() == 0 {
+			sb.WriteByte('.')
+		} else {
+			sb.WriteByte(byte('0' + cell.GetValue()))
+		}
+	}
+	return sb.String()
+}
+
+// Equals reports whether two boards have the same size and the same value in every cell.
+// Candidates, givens and constraints are not compared; see EqualsWithCandidates. Two nil
+// boards are equal, a nil and a non-nil board are not.
+func (b *Board) Equals(other *Board) bool {
+	return b.equals(other, false)
+}
+
+// EqualsWithCandidates is like Equals but also requires every empty cell to have the same
+// candidates
+func (b *Board) EqualsWithCandidates(other *Board) bool {
+	return b.equals(other, true)
+}
+
+// equals compares the values of two boards, and the candidates of empty cells if asked
+func (b *Board) equals(other *Board, candidates bool) bool {
+	if b == nil || other == nil {
+		return b == other
+	}
+	if b.size != other.size {
+		return false
+	}
+
+	for idx, cell := range b.board {
+		if cell == nil {
+			continue
+		}
+		otherCell := other.board[idx]
+		if cell.value != otherCell.value {
+			return false
+		}
+		if candidates && cell.candidateMask() != otherCell.candidateMask() {
+			return false
+		}
+	}
+	return true
+}
+
+// Print writes the board to stdout as formatted by PrettyString
 func (b *Board) Print() {
-	for i := range 81 {
-		if b.board[i] != nil {
-			fmt.Print(b.board[i].GetValue())
+	fmt.Print(b.PrettyString())
+}
+
+// PrettyString returns the board as a grid with '|' between boxes, +---+---+---+
+// between box bands and '.' for empty cells, for example:
+//
+//	+---+---+---+
+//	|53.|.7.|...|
+//	|6..|195|...|
+//	|.98|...|.6.|
+//	+---+---+---+
+//	...
+func (b *Board) PrettyString() string {
+	separator := "+" + strings.Repeat(strings.Repeat("-", b.boxCols)+"+", b.size/b.boxCols) + "\n"
+
+	var sb strings.Builder
+	sb.WriteString(separator)
+	for row := 0; row < b.size; row++ {
+		for col := 0; col < b.size; col++ {
+			if col%b.boxCols == 0 {
+				sb.WriteByte('|')
+			}
+			if value := b.Get(row, col); value != 0 {
+				sb.WriteByte(byte('0' + value))
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteString("|\n")
+		if row%b.boxRows == b.boxRows-1 {
+			sb.WriteString(separator)
+		}
+	}
+	return sb.String()
+}
+
+// CandidatesString returns a deterministic dump of the candidates of every unsolved cell,
+// one line per cell in index order (e.g. "12 R2C4: [1 5 7]"). Solved cells are omitted.
+// Useful for diffing the candidate state before and after a technique runs.
+func (b *Board) CandidatesString() string {
+	var sb strings.Builder
+	for idx := 0; idx < 81; idx++ {
+		cell := b.board[idx]
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		fmt.Fprintf(&sb, "%d R%dC%d: %v\n", idx, cell.GetRow()+1, cell.GetCol()+1,
+			cell.CandidateSlice())
+	}
+	return sb.String()
+}
+
+// PencilMarkString draws the board as a pencil-mark grid for inspecting why solving
+// stalled. Each cell is a 3x3 block with every candidate in a fixed slot, following the
+// display order of SetCandidateOrder, and blank where it was eliminated. Solved cells
+// show their value centered in parentheses. The layout is always 9x9; smaller boards
+// fill its top left corner. Boxes are separated by double lines:
+//
+//	+===+===+===++===+===+===++===+===+===+
+//	|   |   |12 || 2 |   | 2 ||...
+//	|(5)|(3)|4  ||  6|(7)|4 6||...
+//	|   |   |   ||   |   | 8 ||...
+//	+---+---+---++---+---+---++---+---+---+
+//	...
+func (b *Board) PencilMarkString() string {
+	const (
+		cellLine = "+---+---+---++---+---+---++---+---+---+\n"
+		boxLine  = "+===+===+===++===+===+===++===+===+===+\n"
+	)
+	slots := b.orderCandidates(allCandidates)
+
+	var sb strings.Builder
+	sb.WriteString(boxLine)
+	for row := 0; row < 9; row++ {
+		for line := 0; line < 3; line++ {
+			for col := 0; col < 9; col++ {
+				sb.WriteByte('|')
+				if col > 0 && col%3 == 0 {
+					sb.WriteByte('|')
+				}
+
+				cell := b.board[row*9+col]
+				switch {
+				case cell == nil:
+					sb.WriteString("   ")
+				case cell.IsSolved():
+					if line == 1 {
+						fmt.Fprintf(&sb, "(%d)", cell.GetValue())
+					} else {
+						sb.WriteString("   ")
+					}
+				default:
+					for _, digit := range slots[line*3 : line*3+3] {
+						if cell.HasCandidate(digit) {
+							sb.WriteByte(byte('0' + digit))
+						} else {
+							sb.WriteByte(' ')
+						}
+					}
+				}
+			}
+			sb.WriteString("|\n")
+		}
+
+		if row%3 == 2 {
+			sb.WriteString(boxLine)
 		} else {
-			fmt.Print("0")
+			sb.WriteString(cellLine)
+		}
+	}
+	return sb.String()
+}
+
+// TotalCandidates returns the number of candidates left across all unsolved cells.
+// Techniques only remove candidates or place values, so the count drops whenever one of
+// them makes progress and stays the same when solving has stagnated.
+func (b *Board) TotalCandidates() int {
+	total := 0
+	b.ForEachUnsolved(func(cell *Cell) {
+		total += cell.CandidateCount()
+	})
+	return total
+}
+
+// SetCandidateOrder sets the order in which candidates are listed for display, e.g. to
+// match a 3x3 layout in a UI. order must be a permutation of 1-9; nil restores ascending
+// order. This only affects CandidateSlice and CandidatesString, not solving.
+func (b *Board) SetCandidateOrder(order []int) error {
+	if order == nil {
+		b.candidateOrder = nil
+		return nil
+	}
+
+	if len(order) != 9 {
+		return &BoardError{Message: fmt.Sprintf("candidate order must list 9 digits, got %d", len(order))}
+	}
+	seen := make(map[int]bool, 9)
+	for _, digit := range order {
+		if digit < 1 || digit > 9 || seen[digit] {
+			return &BoardError{Message: fmt.Sprintf("candidate order must be a permutation of 1-9, got %v", order)}
 		}
-		if (i+1)%9 == 0 {
-			fmt.Println()
+		seen[digit] = true
+	}
+
+	b.candidateOrder = append([]int{}, order...)
+	return nil
+}
+
+// orderCandidates lists the candidates in the board's display order
+func (b *Board) orderCandidates(mask uint16) []int {
+	if b.candidateOrder == nil {
+		return maskToSlice(mask)
+	}
+
+	result := make([]int, 0, 9)
+	for _, digit := range b.candidateOrder {
+		if mask&candidateBit(digit) != 0 {
+			result = append(result, digit)
 		}
 	}
+	return result
 }
 
 func (b *Board) GetRow(row int) [9]int {
@@ -117,14 +634,17 @@ func (b *Board) GetColumn(col int) [9]int {
 	return colData
 }
 
+// GetBox returns the values of a box row by row; on boards smaller than 9x9 only the
+// first size entries are used
 func (b *Board) GetBox(box int) [9]int {
 	boxData := [9]int{}
-	boxRow := (box / 3) * 3
-	boxCol := (box % 3) * 3
+	boxesPerRow := b.size / b.boxCols
+	boxRow := (box / boxesPerRow) * b.boxRows
+	boxCol := (box % boxesPerRow) * b.boxCols
 
 	idx := 0
-	for r := 0; r < 3; r++ {
-		for c := 0; c < 3; c++ {
+	for r := 0; r < b.boxRows; r++ {
+		for c := 0; c < b.boxCols; c++ {
 			if b.board[(boxRow+r)*9+(boxCol+c)] != nil {
 				boxData[idx] = b.board[(boxRow+r)*9+(boxCol+c)].GetValue()
 			}
@@ -134,9 +654,52 @@ func (b *Board) GetBox(box int) [9]int {
 	return boxData
 }
 
+// RowCells returns the cells of a row from left to right, or nil if row is out of range
+func (b *Board) RowCells(row int) []*Cell {
+	if row < 0 || row >= b.size {
+		return nil
+	}
+	cells := make([]*Cell, b.size)
+	for col := range cells {
+		cells[col] = b.board[row*9+col]
+	}
+	return cells
+}
+
+// ColCells returns the cells of a column from top to bottom, or nil if col is out of range
+func (b *Board) ColCells(col int) []*Cell {
+	if col < 0 || col >= b.size {
+		return nil
+	}
+	cells := make([]*Cell, b.size)
+	for row := range cells {
+		cells[row] = b.board[row*9+col]
+	}
+	return cells
+}
+
+// BoxCells returns the cells of a box row by row, in the same order as GetBox, or nil if
+// box is out of range. Boxes are numbered like BoxIndex.
+func (b *Board) BoxCells(box int) []*Cell {
+	if box < 0 || box >= b.size {
+		return nil
+	}
+	boxesPerRow := b.size / b.boxCols
+	boxRow := (box / boxesPerRow) * b.boxRows
+	boxCol := (box % boxesPerRow) * b.boxCols
+
+	cells := make([]*Cell, 0, b.size)
+	for r := 0; r < b.boxRows; r++ {
+		for c := 0; c < b.boxCols; c++ {
+			cells = append(cells, b.board[(boxRow+r)*9+(boxCol+c)])
+		}
+	}
+	return cells
+}
+
 // AddConstraint adds a constraint to the board and registers it as an observer of its cells
 func (b *Board) AddConstraint(c Constraint) {
-	logger.Info("Adding constraint: %s - %s", c.GetName(), c.GetDescription())
+	b.log().Info("Adding constraint: %s - %s", c.GetName(), c.GetDescription())
 
 	b.constraints = append(b.constraints, c)
 
@@ -145,121 +708,559 @@ func (b *Board) AddConstraint(c Constraint) {
 		bc.SetBoard(b)
 	}
 
+	// Bind the constraint to its embedded base so observer callbacks reach
+	// the concrete PropagateValueChange rather than the base no-op
+	if bc, ok := c.(interface{ bindSelf(Constraint) }); ok {
+		bc.bindSelf(c)
+	}
+
 	// Register the constraint as an observer of all its cells
 	// This is the elegant observer pattern in action!
 	affectedCount := 0
 	for _, cellIndex := range c.GetCells() {
 		if cellIndex >= 0 && cellIndex <= 80 && b.board[cellIndex] != nil {
 			b.board[cellIndex].AddObserver(c) // Constraint observes the cell
+			b.cellConstraints[cellIndex] = append(b.cellConstraints[cellIndex], c)
 			affectedCount++
 		}
 	}
 
-	logger.Debug("Constraint '%s' observing %d cells", c.GetName(), affectedCount)
+	b.peersBuilt = false
+
+	if init, ok := c.(Initializer); ok {
+		init.Initialize(b)
+	}
+
+	b.log().Debug("Constraint '%s' observing %d cells", c.GetName(), affectedCount)
 }
 
-// ValidateAll checks if all constraints on the board are satisfied
+// initializeConstraints runs the Initializer of every constraint that has one, after
+// candidates were reset
+func (b *Board) initializeConstraints() {
+	for _, constraint := range b.constraints {
+		if init, ok := constraint.(Initializer); ok {
+			init.Initialize(b)
+		}
+	}
+}
+
+// RemoveConstraint detaches a constraint from the board: it is dropped from the constraint
+// list and deregistered as an observer of its cells so it no longer propagates. Candidates
+// it eliminated are restored by recomputing all candidates from the remaining constraints.
+// Returns false if the constraint was not on the board.
+func (b *Board) RemoveConstraint(c Constraint) bool {
+	found := false
+	for i, constraint := range b.constraints {
+		if constraint == c {
+			b.constraints = append(b.constraints[:i], b.constraints[i+1:]...)
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		b.log().Warn("Cannot remove constraint '%s': not on the board", c.GetName())
+		return false
+	}
+
+	b.log().Info("Removing constraint: %s", c.GetName())
+
+	for _, cellIndex := range c.GetCells() {
+		if cellIndex < 0 || cellIndex > 80 || b.board[cellIndex] == nil {
+			continue
+		}
+		if b.board[cellIndex].GetNotifier() != nil {
+			b.board[cellIndex].GetNotifier().RemoveObserver(c)
+		}
+
+		indexed := b.cellConstraints[cellIndex]
+		for i, constraint := range indexed {
+			if constraint == c {
+				b.cellConstraints[cellIndex] = append(indexed[:i], indexed[i+1:]...)
+				break
+			}
+		}
+	}
+
+	b.peersBuilt = false
+
+	// The removed rule no longer forbids the values it eliminated
+	b.RecomputeAllCandidates()
+
+	return true
+}
+
+// RecomputeAllCandidates rebuilds the candidates of every unsolved cell from scratch:
+// each one is reset to every digit of the board, then every solved cell propagates its
+// value again through the constraints containing it. Eliminations made by pencil mark or
+// advanced techniques are discarded and need to be reapplied.
+func (b *Board) RecomputeAllCandidates() {
+	b.log().Info("Recomputing all candidates...")
+
+	for _, cell := range b.board {
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		cell.candidates = b.fullMask()
+	}
+	b.initializeConstraints()
+
+	for idx, cell := range b.board {
+		if cell == nil || !cell.IsSolved() {
+			continue
+		}
+		for _, constraint := range b.cellConstraints[idx] {
+			constraint.PropagateValueChange(cell.GetRow(), cell.GetCol(), cell.GetValue())
+		}
+	}
+}
+
+// PropagateUniqueness runs one synchronous elimination pass: every solved cell's value is
+// removed from the candidates of all cells sharing a uniqueness constraint with it. The
+// observer path normally does this as values are set, but not for values that were on
+// the board before a constraint was added, e.g. after a bulk load. Returns true if any
+// candidates were eliminated.
+func (b *Board) PropagateUniqueness() bool {
+	b.solvingStep("Propagation", "Propagating solved values through uniqueness constraints")
+
+	eliminated := 0
+	for _, cell := range b.board {
+		if cell == nil || !cell.IsSolved() {
+			continue
+		}
+		for _, peer := range b.getVisibleCells(cell) {
+			if !peer.IsSolved() && peer.HasCandidate(cell.GetValue()) {
+				peer.RemoveCandidate(cell.GetValue())
+				eliminated++
+			}
+		}
+	}
+
+	if eliminated > 0 {
+		b.log().Info("Uniqueness propagation eliminated %d candidate(s)", eliminated)
+	}
+	return eliminated > 0
+}
+
+// ValidateCandidates is a self-check for interactive editors, where setting and clearing
+// cells by hand can leave candidates out of sync with values. It returns, in index
+// order, the cells whose candidates are inconsistent: a solved cell that still has
+// candidates, an empty cell listing a value held by a cell sharing a uniqueness
+// constraint with it, or an empty cell with no candidates left.
+// RecomputeAllCandidates repairs the reported cells.
+func (b *Board) ValidateCandidates() []int {
+	inconsistent := make([]int, 0)
+	for idx, cell := range b.board {
+		if cell == nil {
+			continue
+		}
+
+		if cell.IsSolved() {
+			if cell.candidates != 0 {
+				inconsistent = append(inconsistent, idx)
+			}
+			continue
+		}
+
+		if cell.CandidateCount() == 0 {
+			inconsistent = append(inconsistent, idx)
+			continue
+		}
+
+		for _, peer := range b.getVisibleCells(cell) {
+			if peer.IsSolved() && cell.HasCandidate(peer.GetValue()) {
+				inconsistent = append(inconsistent, idx)
+				break
+			}
+		}
+	}
+
+	if len(inconsistent) > 0 {
+		b.log().Warn("Found %d cell(s) with inconsistent candidates", len(inconsistent))
+	}
+	return inconsistent
+}
+
+// ValidateAll checks if all constraints on the board are satisfied. It is a boolean
+// wrapper over ValidateAllDetailed.
 func (b *Board) ValidateAll() (bool, error) {
-	logger.Info("Validating all %d constraints...", len(b.constraints))
+	violations, err := b.ValidateAllDetailed()
+	if err != nil {
+		return false, err
+	}
+	return len(violations) == 0, nil
+}
 
-	for _, constraint := range b.constraints {
+// ValidateAffected checks only the constraints that include the cell at (row, col).
+// This is much cheaper than ValidateAll when a single cell has just changed.
+func (b *Board) ValidateAffected(row, col int) (bool, error) {
+	if row < 0 || row >= b.size || col < 0 || col >= b.size {
+		b.log().Error("Invalid board position: row=%d, col=%d", row, col)
+		return false, &BoardError{Message: fmt.Sprintf("invalid position: row=%d, col=%d", row, col)}
+	}
+
+	affected := b.cellConstraints[row*9+col]
+	b.log().Debug("Validating %d constraint(s) affecting R%dC%d...", len(affected), row+1, col+1)
+
+	for _, constraint := range affected {
 		valid, err := constraint.IsValid(b)
 		if err != nil {
-			logger.Error("Error validating constraint '%s': %v", constraint.GetName(), err)
+			b.log().Error("Error validating constraint '%s': %v", constraint.GetName(), err)
 			return false, fmt.Errorf("error validating %s: %w", constraint.GetName(), err)
 		}
 		if !valid {
-			logger.Warn("Constraint validation failed: %s", constraint.GetName())
+			b.log().Warn("Constraint validation failed: %s", constraint.GetName())
 			return false, nil
 		}
-		logger.Debug("Constraint '%s' validation: PASSED", constraint.GetName())
 	}
 
-	logger.Info("All constraints validated successfully")
 	return true, nil
 }
 
+// ValidateAllDetailed checks every constraint and returns a violation for each way one
+// is broken, with the conflicting cells and value, instead of stopping at the first
+// failure. Constraints that don't implement ViolationReporter are reported as a single
+// violation covering their filled cells. An empty result means the board is valid.
+func (b *Board) ValidateAllDetailed() ([]Violation, error) {
+	b.log().Info("Validating all %d constraints...", len(b.constraints))
+
+	violations := make([]ConstraintViolation, 0)
+	for _, constraint := range b.constraints {
+		valid, err := constraint.IsValid(b)
+		if err != nil {
+			b.log().Error("Error validating constraint '%s': %v", constraint.GetName(), err)
+			return nil, fmt.Errorf("error validating %s: %w", constraint.GetName(), err)
+		}
+		if valid {
+			continue
+		}
+
+		var found []ConstraintViolation
+		if reporter, ok := constraint.(ViolationReporter); ok {
+			found = reporter.Violations(b)
+		}
+		if len(found) == 0 {
+			filled := make([]int, 0)
+			for _, idx := range constraint.GetCells() {
+				if cell := b.GetCell(idx); cell != nil && cell.IsSolved() {
+					filled = append(filled, idx)
+				}
+			}
+			found = []ConstraintViolation{{
+				ConstraintName: constraint.GetName(),
+				Cells:          filled,
+				Message:        "constraint is not satisfied",
+			}}
+		}
+
+		for _, v := range found {
+			b.log().Warn("Constraint violation in %s: %s (cells %v)", v.ConstraintName, v.Message, v.Cells)
+		}
+		violations = append(violations, found...)
+	}
+
+	if len(violations) == 0 {
+		b.log().Info("All constraints validated successfully")
+	}
+	return violations, nil
+}
+
 // GetConstraints returns all constraints on the board
 func (b *Board) GetConstraints() []Constraint {
 	return b.constraints
 }
 
+// GetConstraintsForCell returns the constraints that include the cell at index, in the
+// order they were added, or nil for an invalid index. It reads the index kept up to date
+// by AddConstraint and RemoveConstraint, so it doesn't scan the constraints.
+func (b *Board) GetConstraintsForCell(index int) []Constraint {
+	if index < 0 || index > 80 {
+		return nil
+	}
+	return append([]Constraint{}, b.cellConstraints[index]...)
+}
+
 // ApplyPencilMarkConstraints applies advanced solving techniques (naked/hidden pairs, etc.)
 // to all constraints that enforce uniqueness. Returns true if any candidates were eliminated.
 func (b *Board) ApplyPencilMarkConstraints() bool {
-	logger.SolvingStep("Pencil Mark", "Applying pencil mark constraints (naked/hidden subsets)")
+	// Each naked or hidden subset records its own solving step
+	b.log().Debug("Applying pencil mark constraints (naked/hidden subsets)")
 
 	changed := false
 	constraintsApplied := 0
 
-	for _, constraint := range b.constraints {
-		if constraint.RequiresUniqueness() {
-			constraintsApplied++
-			if constraint.ApplyPencilMarkConstraints(b) {
+	// Visit the constraints with the most potential eliminations first
+	ordered := make([]Constraint, 0, len(b.constraints))
+	scores := make(map[Constraint]int)
+	for _, constraint := range b.constraints {
+		if constraint.RequiresUniqueness() {
+			ordered = append(ordered, constraint)
+			scores[constraint] = constraint.ImpactScore(b)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return scores[ordered[i]] > scores[ordered[j]]
+	})
+
+	for _, constraint := range ordered {
+		constraintsApplied++
+		if constraint.ApplyPencilMarkConstraints(b) {
+			changed = true
+			b.log().Debug("Pencil mark technique found eliminations in: %s", constraint.GetName())
+		}
+	}
+
+	if changed {
+		b.log().Info("Pencil mark constraints eliminated candidates")
+	} else {
+		b.log().Debug("Pencil mark constraints did not find any eliminations")
+	}
+
+	return changed
+}
+
+// DefaultPencilMarkIterations caps ApplyPencilMarkConstraintsUntilStable. Every iteration
+// that continues has removed at least one candidate, so a real board stabilizes long before.
+const DefaultPencilMarkIterations = 100
+
+// ApplyPencilMarkConstraintsUntilStable repeatedly applies pencil mark constraints,
+// placing any naked singles they leave behind, until no more changes occur or
+// DefaultPencilMarkIterations is reached.
+// Returns the number of iterations performed.
+func (b *Board) ApplyPencilMarkConstraintsUntilStable() int {
+	return b.ApplyPencilMarkConstraintsUntilStableN(DefaultPencilMarkIterations)
+}
+
+// ApplyPencilMarkConstraintsUntilStableN is ApplyPencilMarkConstraintsUntilStable with at
+// most maxIterations iterations, protecting long-running callers from boards that keep
+// changing. A warning is logged if the cap is hit before the board stabilizes.
+// Returns the number of iterations performed.
+func (b *Board) ApplyPencilMarkConstraintsUntilStableN(maxIterations int) int {
+	if maxIterations < 1 {
+		b.log().Warn("Pencil mark iteration cap must be at least 1, got %d", maxIterations)
+		return 0
+	}
+
+	b.log().Info("Applying pencil mark constraints until stable...")
+
+	iterations := 0
+	for iterations < maxIterations {
+		before := b.TotalCandidates()
+		b.ApplyPencilMarkConstraints()
+		b.FillNakedSingles()
+		iterations++
+		if b.TotalCandidates() == before {
+			b.log().Info("Pencil mark constraints stabilized after %d iteration(s)", iterations)
+			return iterations
+		}
+		b.log().Debug("Pencil mark iteration %d: Changes detected, continuing...", iterations)
+	}
+
+	b.log().Warn("Pencil mark constraints still changing after %d iteration(s), stopping", iterations)
+	return iterations
+}
+
+// FillNakedSingles places every unsolved cell that has exactly one candidate left.
+// Placing a value propagates through all constraints (including variant ones such as
+// killer cages and thermometers), which may create new singles, so it keeps going
+// until none remain. Returns the number of cells placed.
+func (b *Board) FillNakedSingles() int {
+	placed := 0
+
+	for {
+		progress := false
+		for idx := 0; idx < 81; idx++ {
+			cell := b.board[idx]
+			if cell == nil || cell.IsSolved() || cell.CandidateCount() != 1 {
+				continue
+			}
+
+			value := maskToSlice(cell.candidateMask())[0]
+			b.solvingStep("Naked Single", "R%dC%d has only candidate %d",
+				cell.GetRow()+1, cell.GetCol()+1, value)
+
+			if err := cell.SetValue(value); err != nil {
+				b.log().Error("Failed to place naked single at R%dC%d: %v", cell.GetRow()+1, cell.GetCol()+1, err)
+				continue
+			}
+			placed++
+			progress = true
+		}
+
+		if !progress {
+			break
+		}
+	}
+
+	if placed > 0 {
+		b.log().Info("Placed %d naked single(s)", placed)
+	}
+	return placed
+}
+
+// TechniqueOptions selects the techniques ApplyAdvancedTechniquesWithOptions tries. The
+// zero value tries none; start from AllTechniques and switch off what isn't wanted.
+type TechniqueOptions struct {
+	XWing           bool
+	Swordfish       bool
+	FinnedFish      bool // The sizes set with SetFinnedFishSizes, up to MaxFishSize
+	Intersection    bool
+	Coloring        bool
+	CageLine        bool
+	XYWing          bool
+	WWing           bool
+	XYZWing         bool
+	UniqueRectangle bool // Only tried when SetAssumeUnique is enabled
+	BUG             bool // Only tried when SetAssumeUnique is enabled
+
+	// MaxFishSize caps the fish tried: 2 for X-Wings only, 3 to add Swordfish, 4 to add
+	// finned Jellyfish. 0 means no cap.
+	MaxFishSize int
+}
+
+// AllTechniques returns options with every advanced technique on and no fish size cap,
+// as used by ApplyAdvancedTechniques
+func AllTechniques() TechniqueOptions {
+	return TechniqueOptions{
+		XWing:           true,
+		Swordfish:       true,
+		FinnedFish:      true,
+		Intersection:    true,
+		Coloring:        true,
+		CageLine:        true,
+		XYWing:          true,
+		WWing:           true,
+		XYZWing:         true,
+		UniqueRectangle: true,
+		BUG:             true,
+	}
+}
+
+// allowsFish reports whether a fish of the given size is within MaxFishSize
+func (opts TechniqueOptions) allowsFish(size int) bool {
+	return opts.MaxFishSize == 0 || size <= opts.MaxFishSize
+}
+
+// ApplyAdvancedTechniques applies advanced solving techniques like X-Wings, Swordfish, XY-Wings, W-Wings and XYZ-Wings,
+// plus BUG+1 and Unique Rectangles when SetAssumeUnique is enabled
+// Returns true if any candidates were eliminated
+func (b *Board) ApplyAdvancedTechniques() bool {
+	return b.ApplyAdvancedTechniquesWithOptions(AllTechniques())
+}
+
+// ApplyAdvancedTechniquesWithOptions applies the advanced techniques switched on in opts,
+// in the same order as ApplyAdvancedTechniques, so cheap techniques can run on their own
+// and expensive ones be saved for when those stall.
+// Returns true if any candidates were eliminated
+func (b *Board) ApplyAdvancedTechniquesWithOptions(opts TechniqueOptions) bool {
+	b.solvingStep("Advanced", "Trying advanced solving techniques...")
+
+	changed := false
+
+	// Try BUG+1 first (only valid for puzzles with a unique solution). It needs every
+	// other unsolved cell to be bivalue, which the eliminations below can break
+	if b.assumeUnique && opts.BUG {
+		b.log().Debug("Attempting BUG+1 technique...")
+		if b.applyBUG() {
+			changed = true
+			b.log().Info("BUG+1 technique solved a cell")
+		}
+	}
+
+	// Try Unique Rectangles, also uniqueness-based
+	if b.assumeUnique && opts.UniqueRectangle {
+		b.log().Debug("Attempting Unique Rectangle technique...")
+		if b.applyUniqueRectangles() {
+			changed = true
+			b.log().Info("Unique Rectangle technique found eliminations")
+		}
+	}
+
+	// Try X-Wings (2x2 patterns)
+	if opts.XWing && opts.allowsFish(2) {
+		b.log().Debug("Attempting X-Wing technique...")
+		if b.applyXWings() {
+			changed = true
+			b.log().Info("X-Wing technique found eliminations")
+		}
+	}
+
+	// Try Swordfish (3x3 patterns)
+	if opts.Swordfish && opts.allowsFish(3) {
+		b.log().Debug("Attempting Swordfish technique...")
+		if b.applySwordfish() {
+			changed = true
+			b.log().Info("Swordfish technique found eliminations")
+		}
+	}
+
+	// Try finned fish of the configured sizes
+	if opts.FinnedFish {
+		b.log().Debug("Attempting finned fish techniques...")
+		for _, size := range b.FinnedFishSizes() {
+			if opts.allowsFish(size) && b.applyFinnedFish(size) {
 				changed = true
-				logger.Debug("Pencil mark technique found eliminations in: %s", constraint.GetName())
+				b.log().Info("Finned %s found eliminations", fishName(size))
 			}
 		}
 	}
 
-	if changed {
-		logger.Info("Pencil mark constraints eliminated candidates")
-	} else {
-		logger.Debug("Pencil mark constraints did not find any eliminations")
+	// Try intersection removal (pointing pairs and box-line reduction)
+	if opts.Intersection {
+		b.log().Debug("Attempting intersection removal...")
+		if b.ApplyIntersectionRemoval() {
+			changed = true
+			b.log().Info("Intersection removal found eliminations")
+		}
 	}
 
-	return changed
-}
-
-// ApplyPencilMarkConstraintsUntilStable repeatedly applies pencil mark constraints
-// until no more changes occur. Returns the number of iterations performed.
-func (b *Board) ApplyPencilMarkConstraintsUntilStable() int {
-	logger.Info("Applying pencil mark constraints until stable...")
-
-	iterations := 0
-	for {
-		changed := b.ApplyPencilMarkConstraints()
-		iterations++
-		if !changed {
-			logger.Info("Pencil mark constraints stabilized after %d iteration(s)", iterations)
-			break
+	// Try simple coloring (single-digit chains)
+	if opts.Coloring {
+		b.log().Debug("Attempting simple coloring...")
+		if b.applyColoring() {
+			changed = true
+			b.log().Info("Simple coloring found eliminations")
 		}
-		logger.Debug("Pencil mark iteration %d: Changes detected, continuing...", iterations)
 	}
 
-	return iterations
-}
-
-// ApplyAdvancedTechniques applies advanced solving techniques like X-Wings, Swordfish, and XY-Wings
-// Returns true if any candidates were eliminated
-func (b *Board) ApplyAdvancedTechniques() bool {
-	logger.SolvingStep("Advanced", "Trying advanced solving techniques...")
-
-	changed := false
+	// Try cage line reductions (pointing from killer cages)
+	if opts.CageLine {
+		b.log().Debug("Attempting cage line reduction...")
+		if b.applyCageLineReductions() {
+			changed = true
+			b.log().Info("Cage line reduction found eliminations")
+		}
+	}
 
-	// Try X-Wings (2x2 patterns)
-	logger.Debug("Attempting X-Wing technique...")
-	if b.applyXWings() {
-		changed = true
-		logger.Info("X-Wing technique found eliminations")
+	// Try XY-Wings
+	if opts.XYWing {
+		b.log().Debug("Attempting XY-Wing technique...")
+		if b.applyXYWings() {
+			changed = true
+			b.log().Info("XY-Wing technique found eliminations")
+		}
 	}
 
-	// Try Swordfish (3x3 patterns)
-	logger.Debug("Attempting Swordfish technique...")
-	if b.applySwordfish() {
-		changed = true
-		logger.Info("Swordfish technique found eliminations")
+	// Try W-Wings
+	if opts.WWing {
+		b.log().Debug("Attempting W-Wing technique...")
+		if b.applyWWings() {
+			changed = true
+			b.log().Info("W-Wing technique found eliminations")
+		}
 	}
 
-	// Try XY-Wings
-	logger.Debug("Attempting XY-Wing technique...")
-	if b.applyXYWings() {
-		changed = true
-		logger.Info("XY-Wing technique found eliminations")
+	// Try XYZ-Wings
+	if opts.XYZWing {
+		b.log().Debug("Attempting XYZ-Wing technique...")
+		if b.applyXYZWings() {
+			changed = true
+			b.log().Info("XYZ-Wing technique found eliminations")
+		}
 	}
 
 	if !changed {
-		logger.Debug("No advanced techniques found any eliminations")
+		b.log().Debug("No advanced techniques found any eliminations")
 	}
 
 	return changed
@@ -272,17 +1273,17 @@ func (b *Board) applyXWings() bool {
 	changed := false
 
 	// Try X-Wings in rows (eliminate from columns)
-	logger.Debug("Checking for X-Wings in rows...")
+	b.log().Debug("Checking for X-Wings in rows...")
 	if b.applyXWingsInDirection(true) {
 		changed = true
-		logger.SolvingStep("X-Wing", "Found X-Wing pattern in rows")
+		b.log().SolvingStep("X-Wing", "Found X-Wing pattern in rows")
 	}
 
 	// Try X-Wings in columns (eliminate from rows)
-	logger.Debug("Checking for X-Wings in columns...")
+	b.log().Debug("Checking for X-Wings in columns...")
 	if b.applyXWingsInDirection(false) {
 		changed = true
-		logger.SolvingStep("X-Wing", "Found X-Wing pattern in columns")
+		b.log().SolvingStep("X-Wing", "Found X-Wing pattern in columns")
 	}
 
 	return changed
@@ -296,22 +1297,7 @@ func (b *Board) applyXWingsInDirection(rowBased bool) bool {
 		// Build a map of line -> positions where candidate appears
 		linePositions := make(map[int][]int)
 
-		for line := 0; line < 9; line++ {
-			positions := make([]int, 0)
-
-			for pos := 0; pos < 9; pos++ {
-				var cell *Cell
-				if rowBased {
-					cell = b.GetCellAt(line, pos)
-				} else {
-					cell = b.GetCellAt(pos, line)
-				}
-
-				if cell != nil && !cell.IsSolved() && cell.HasCandidate(candidate) {
-					positions = append(positions, pos)
-				}
-			}
-
+		for line, positions := range b.fishPositions(candidate, rowBased) {
 			// Only interested in lines with exactly 2 positions
 			if len(positions) == 2 {
 				linePositions[line] = positions
@@ -323,6 +1309,8 @@ func (b *Board) applyXWingsInDirection(rowBased bool) bool {
 		for line := range linePositions {
 			lines = append(lines, line)
 		}
+		// Sort so patterns are found, and eliminations made, in a reproducible order
+		sort.Ints(lines)
 
 		// Check all pairs of lines
 		for i := 0; i < len(lines); i++ {
@@ -337,21 +1325,14 @@ func (b *Board) applyXWingsInDirection(rowBased bool) bool {
 					if !rowBased {
 						direction = "columns"
 					}
-					logger.SolvingStep("X-Wing", "Found X-Wing for candidate %d in %s %d and %d at positions %v",
+					b.solvingStep("X-Wing", "Found X-Wing for candidate %d in %s %d and %d at positions %v",
 						candidate, direction, line1+1, line2+1, pos1)
 
 					eliminatedCount := 0
 					for otherLine := 0; otherLine < 9; otherLine++ {
 						if otherLine != line1 && otherLine != line2 {
 							for _, pos := range pos1 {
-								var cell *Cell
-								if rowBased {
-									// Eliminate from column
-									cell = b.GetCellAt(otherLine, pos)
-								} else {
-									// Eliminate from row
-									cell = b.GetCellAt(pos, otherLine)
-								}
+								cell := b.lineCell(otherLine, pos, rowBased)
 
 								if cell != nil && !cell.IsSolved() && cell.HasCandidate(candidate) {
 									cell.RemoveCandidate(candidate)
@@ -361,7 +1342,7 @@ func (b *Board) applyXWingsInDirection(rowBased bool) bool {
 							}
 						}
 					}
-					logger.Info("X-Wing eliminated candidate %d from %d cell(s)", candidate, eliminatedCount)
+					b.log().Info("X-Wing eliminated candidate %d from %d cell(s)", candidate, eliminatedCount)
 				}
 			}
 		}
@@ -375,17 +1356,17 @@ func (b *Board) applySwordfish() bool {
 	changed := false
 
 	// Try Swordfish in rows (eliminate from columns)
-	logger.Debug("Checking for Swordfish in rows...")
+	b.log().Debug("Checking for Swordfish in rows...")
 	if b.applySwordfishInDirection(true) {
 		changed = true
-		logger.SolvingStep("Swordfish", "Found Swordfish pattern in rows")
+		b.log().SolvingStep("Swordfish", "Found Swordfish pattern in rows")
 	}
 
 	// Try Swordfish in columns (eliminate from rows)
-	logger.Debug("Checking for Swordfish in columns...")
+	b.log().Debug("Checking for Swordfish in columns...")
 	if b.applySwordfishInDirection(false) {
 		changed = true
-		logger.SolvingStep("Swordfish", "Found Swordfish pattern in columns")
+		b.log().SolvingStep("Swordfish", "Found Swordfish pattern in columns")
 	}
 
 	return changed
@@ -399,22 +1380,7 @@ func (b *Board) applySwordfishInDirection(rowBased bool) bool {
 		// Build a map of line -> positions where candidate appears
 		linePositions := make(map[int][]int)
 
-		for line := 0; line < 9; line++ {
-			positions := make([]int, 0)
-
-			for pos := 0; pos < 9; pos++ {
-				var cell *Cell
-				if rowBased {
-					cell = b.GetCellAt(line, pos)
-				} else {
-					cell = b.GetCellAt(pos, line)
-				}
-
-				if cell != nil && !cell.IsSolved() && cell.HasCandidate(candidate) {
-					positions = append(positions, pos)
-				}
-			}
-
+		for line, positions := range b.fishPositions(candidate, rowBased) {
 			// Only interested in lines with 2 or 3 positions
 			if len(positions) >= 2 && len(positions) <= 3 {
 				linePositions[line] = positions
@@ -426,6 +1392,8 @@ func (b *Board) applySwordfishInDirection(rowBased bool) bool {
 		for line := range linePositions {
 			lines = append(lines, line)
 		}
+		// Sort so patterns are found, and eliminations made, in a reproducible order
+		sort.Ints(lines)
 
 		// Check all triples of lines
 		for i := 0; i < len(lines); i++ {
@@ -452,24 +1420,20 @@ func (b *Board) applySwordfishInDirection(rowBased bool) bool {
 						for pos := range posUnion {
 							positions = append(positions, pos)
 						}
+						sort.Ints(positions)
 
 						direction := "rows"
 						if !rowBased {
 							direction = "columns"
 						}
-						logger.SolvingStep("Swordfish", "Found Swordfish for candidate %d in %s %d, %d, %d at positions %v",
+						b.solvingStep("Swordfish", "Found Swordfish for candidate %d in %s %d, %d, %d at positions %v",
 							candidate, direction, line1+1, line2+1, line3+1, positions)
 
 						eliminatedCount := 0
 						for otherLine := 0; otherLine < 9; otherLine++ {
 							if otherLine != line1 && otherLine != line2 && otherLine != line3 {
 								for _, pos := range positions {
-									var cell *Cell
-									if rowBased {
-										cell = b.GetCellAt(otherLine, pos)
-									} else {
-										cell = b.GetCellAt(pos, otherLine)
-									}
+									cell := b.lineCell(otherLine, pos, rowBased)
 
 									if cell != nil && !cell.IsSolved() && cell.HasCandidate(candidate) {
 										cell.RemoveCandidate(candidate)
@@ -479,7 +1443,7 @@ func (b *Board) applySwordfishInDirection(rowBased bool) bool {
 								}
 							}
 						}
-						logger.Info("Swordfish eliminated candidate %d from %d cell(s)", candidate, eliminatedCount)
+						b.log().Info("Swordfish eliminated candidate %d from %d cell(s)", candidate, eliminatedCount)
 					}
 				}
 			}
@@ -497,18 +1461,17 @@ func (b *Board) applyXYWings() bool {
 
 	// Find all cells with exactly 2 candidates (potential pivots and wings)
 	cells2Cands := make([]*Cell, 0)
-	for idx := 0; idx < 81; idx++ {
-		cell := b.GetCell(idx)
-		if cell != nil && !cell.IsSolved() && cell.CandidateCount() == 2 {
+	b.ForEachUnsolved(func(cell *Cell) {
+		if cell.CandidateCount() == 2 {
 			cells2Cands = append(cells2Cands, cell)
 		}
-	}
+	})
 
-	logger.Debug("Found %d cells with exactly 2 candidates for XY-Wing analysis", len(cells2Cands))
+	b.log().Debug("Found %d cells with exactly 2 candidates for XY-Wing analysis", len(cells2Cands))
 
 	// Try each cell as a pivot
 	for _, pivot := range cells2Cands {
-		pivotCands := utils.GetCandidatesAsSlice(pivot.GetCandidates())
+		pivotCands := maskToSlice(pivot.candidateMask())
 		if len(pivotCands) != 2 {
 			continue
 		}
@@ -523,7 +1486,7 @@ func (b *Board) applyXYWings() bool {
 				continue
 			}
 
-			wing1Cands := utils.GetCandidatesAsSlice(wing1.GetCandidates())
+			wing1Cands := maskToSlice(wing1.candidateMask())
 			if len(wing1Cands) != 2 {
 				continue
 			}
@@ -552,7 +1515,7 @@ func (b *Board) applyXYWings() bool {
 					continue
 				}
 
-				wing2Cands := utils.GetCandidatesAsSlice(wing2.GetCandidates())
+				wing2Cands := maskToSlice(wing2.candidateMask())
 				if len(wing2Cands) != 2 {
 					continue
 				}
@@ -581,32 +1544,26 @@ func (b *Board) applyXYWings() bool {
 				}
 				Z := Z1
 
-				logger.SolvingStep("XY-Wing", "Found XY-Wing: Pivot R%dC%d {%d,%d}, Wing1 R%dC%d, Wing2 R%dC%d, eliminating %d",
+				// Each wing must cover a different pivot candidate ({X,Z} and {Y,Z}),
+				// otherwise the pivot can avoid both wings and nothing follows
+				if wing1Cands[0] == wing2Cands[0] && wing1Cands[1] == wing2Cands[1] {
+					continue
+				}
+
+				b.solvingStep("XY-Wing", "Found XY-Wing: Pivot R%dC%d {%d,%d}, Wing1 R%dC%d, Wing2 R%dC%d, eliminating %d",
 					pivot.GetRow()+1, pivot.GetCol()+1, X, Y,
 					wing1.GetRow()+1, wing1.GetCol()+1,
 					wing2.GetRow()+1, wing2.GetCol()+1, Z)
 
 				// XY-Wing found! Eliminate Z from cells that see both wings
-				wing1Visible := b.getVisibleCells(wing1)
-				wing2Visible := b.getVisibleCells(wing2)
-
 				eliminatedCount := 0
 
-				for _, cell := range wing1Visible {
+				for _, cell := range b.getVisibleCells(wing1) {
 					if cell == pivot || cell == wing1 || cell == wing2 {
 						continue
 					}
 
-					// Check if this cell also sees wing2
-					seesWing2 := false
-					for _, w2v := range wing2Visible {
-						if w2v == cell {
-							seesWing2 = true
-							break
-						}
-					}
-
-					if seesWing2 && cell.HasCandidate(Z) {
+					if cell.CanSee(wing2) && cell.HasCandidate(Z) {
 						cell.RemoveCandidate(Z)
 						changed = true
 						eliminatedCount++
@@ -614,7 +1571,7 @@ func (b *Board) applyXYWings() bool {
 				}
 
 				if eliminatedCount > 0 {
-					logger.Info("XY-Wing eliminated candidate %d from %d cell(s)", Z, eliminatedCount)
+					b.log().Info("XY-Wing eliminated candidate %d from %d cell(s)", Z, eliminatedCount)
 				}
 			}
 		}
@@ -623,38 +1580,436 @@ func (b *Board) applyXYWings() bool {
 	return changed
 }
 
-// getVisibleCells returns all cells that share at least one constraint with the given cell
-func (b *Board) getVisibleCells(cell *Cell) []*Cell {
-	visibleMap := make(map[*Cell]bool)
+// applyWWings implements the W-Wing technique
+// Two bivalue cells with the same candidates {X,Y} that don't see each other are joined by
+// a strong link on X: a row or column where X appears in exactly two cells, one seeing
+// each bivalue cell. One end of the link must be X, so one of the bivalue cells is Y and
+// Y can be eliminated from cells that see both bivalue cells
+func (b *Board) applyWWings() bool {
+	changed := false
+
+	bivalue := make([]*Cell, 0)
+	b.ForEachUnsolved(func(cell *Cell) {
+		if cell.CandidateCount() == 2 {
+			bivalue = append(bivalue, cell)
+		}
+	})
+
+	for i, cell1 := range bivalue {
+		for _, cell2 := range bivalue[i+1:] {
+			if cell1.candidateMask() != cell2.candidateMask() || cell1.CanSee(cell2) {
+				continue
+			}
+			pair := maskToSlice(cell1.candidateMask())
+
+			// Either candidate can carry the strong link; the other is eliminated
+			for k, X := range pair {
+				Y := pair[1-k]
+				link1, link2, found := b.findWWingLink(X, cell1, cell2)
+				if !found {
+					continue
+				}
+
+				var targets []*Cell
+				for _, cell := range b.getVisibleCells(cell1) {
+					if cell != cell2 && cell.HasCandidate(Y) && cell.CanSee(cell2) {
+						targets = append(targets, cell)
+					}
+				}
+				if len(targets) == 0 {
+					continue
+				}
+
+				b.solvingStep("W-Wing", "Found W-Wing: R%dC%d and R%dC%d {%d,%d}, strong link on %d between R%dC%d and R%dC%d, eliminating %d",
+					cell1.GetRow()+1, cell1.GetCol()+1, cell2.GetRow()+1, cell2.GetCol()+1, pair[0], pair[1],
+					X, link1.GetRow()+1, link1.GetCol()+1, link2.GetRow()+1, link2.GetCol()+1, Y)
+				removeCandidateFrom(targets, Y)
+				changed = true
+				b.log().Info("W-Wing eliminated candidate %d from %d cell(s)", Y, len(targets))
+			}
+		}
+	}
+
+	return changed
+}
+
+// findWWingLink looks for a row or column where the candidate appears in exactly two
+// cells, one seeing cell1 and the other seeing cell2, and returns that pair in order
+func (b *Board) findWWingLink(candidate int, cell1, cell2 *Cell) (*Cell, *Cell, bool) {
+	for _, rowBased := range []bool{true, false} {
+		linePositions := b.fishPositions(candidate, rowBased)
+		for line, positions := range linePositions {
+			if len(positions) != 2 {
+				continue
+			}
+
+			end1 := b.lineCell(line, positions[0], rowBased)
+			end2 := b.lineCell(line, positions[1], rowBased)
+			// Each end must see its bivalue cell without being that cell
+			for _, ends := range [][2]*Cell{{end1, end2}, {end2, end1}} {
+				if ends[0] != cell1 && ends[0].CanSee(cell1) && ends[1] != cell2 && ends[1].CanSee(cell2) {
+					return ends[0], ends[1], true
+				}
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// applyXYZWings implements the XYZ-Wing technique
+// A pivot with candidates {X,Y,Z} sees two wings {X,Z} and {Y,Z}. Whichever value the
+// pivot takes, Z ends up in the pivot or one of the wings, so Z can be eliminated from
+// cells that see the pivot and both wings
+func (b *Board) applyXYZWings() bool {
+	changed := false
+
+	for idx := 0; idx < 81; idx++ {
+		pivot := b.GetCell(idx)
+		if pivot == nil || pivot.IsSolved() || pivot.CandidateCount() != 3 {
+			continue
+		}
+		pivotMask := pivot.candidateMask()
+
+		// Wings are bivalue cells the pivot sees whose candidates are a subset of the pivot's
+		visibleCells := b.getVisibleCells(pivot)
+		wings := make([]*Cell, 0)
+		for _, cell := range visibleCells {
+			mask := cell.candidateMask()
+			if cell.CandidateCount() == 2 && mask&pivotMask == mask {
+				wings = append(wings, cell)
+			}
+		}
+
+		for i, wing1 := range wings {
+			for _, wing2 := range wings[i+1:] {
+				mask1, mask2 := wing1.candidateMask(), wing2.candidateMask()
+				if mask1 == mask2 || mask1|mask2 != pivotMask {
+					continue
+				}
+				Z := maskToSlice(mask1 & mask2)[0]
+
+				var targets []*Cell
+				for _, cell := range visibleCells {
+					if cell == wing1 || cell == wing2 || !cell.HasCandidate(Z) {
+						continue
+					}
+					if cell.CanSee(wing1) && cell.CanSee(wing2) {
+						targets = append(targets, cell)
+					}
+				}
+				if len(targets) == 0 {
+					continue
+				}
+
+				pivotCands := maskToSlice(pivotMask)
+				b.solvingStep("XYZ-Wing", "Found XYZ-Wing: Pivot R%dC%d {%d,%d,%d}, Wing1 R%dC%d, Wing2 R%dC%d, eliminating %d",
+					pivot.GetRow()+1, pivot.GetCol()+1, pivotCands[0], pivotCands[1], pivotCands[2],
+					wing1.GetRow()+1, wing1.GetCol()+1,
+					wing2.GetRow()+1, wing2.GetCol()+1, Z)
+				removeCandidateFrom(targets, Z)
+				changed = true
+				b.log().Info("XYZ-Wing eliminated candidate %d from %d cell(s)", Z, len(targets))
+			}
+		}
+	}
+
+	return changed
+}
+
+// SetAssumeUnique enables or disables techniques that assume the puzzle has a unique
+// solution (such as BUG+1 and Unique Rectangles). They are disabled by default since
+// they can produce wrong deductions on puzzles with multiple solutions.
+func (b *Board) SetAssumeUnique(assume bool) {
+	b.assumeUnique = assume
+}
+
+// AssumeUnique returns whether uniqueness-based techniques are enabled
+func (b *Board) AssumeUnique() bool {
+	return b.assumeUnique
+}
+
+// applyBUG implements the BUG+1 (Bivalue Universal Grave) technique
+// If every unsolved cell has exactly 2 candidates except one cell with 3, then removing
+// the wrong candidate from that cell would leave a deadly pattern with multiple solutions.
+// Assuming a unique solution, the cell must take the candidate that appears an odd number
+// of times in its units.
+// It only fires when the preconditions hold exactly: every constraint is a unit, since
+// other constraints could single out one solution of the grave, and with the surviving
+// candidate taken out of the tri-value cell, every candidate appears exactly twice or not
+// at all in every unit.
+func (b *Board) applyBUG() bool {
+	var triValue *Cell
+
+	for idx := 0; idx < 81; idx++ {
+		cell := b.GetCell(idx)
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+
+		switch cell.CandidateCount() {
+		case 2:
+			// Bivalue cell, part of the grave
+		case 3:
+			if triValue != nil {
+				return false // More than one tri-value cell
+			}
+			triValue = cell
+		default:
+			return false
+		}
+	}
+
+	if triValue == nil {
+		return false
+	}
 
-	// Find all constraints that include this cell
+	units := make([]Constraint, 0, len(b.constraints))
 	for _, constraint := range b.constraints {
-		cellIncluded := false
-		for _, idx := range constraint.GetCells() {
-			if idx == cell.GetIndex() {
-				cellIncluded = true
-				break
+		if !b.isUnit(constraint) {
+			b.log().Debug("BUG+1 skipped: constraint '%s' is not a unit", constraint.GetName())
+			return false
+		}
+		units = append(units, constraint)
+	}
+
+	// The candidate appearing three times in every unit of the tri-value cell survives
+	solution := 0
+	triUnits := make(map[Constraint]bool)
+	for _, constraint := range b.cellConstraints[triValue.GetIndex()] {
+		if !b.isUnit(constraint) {
+			continue
+		}
+		triUnits[constraint] = true
+
+		counts := b.unitCandidateCounts(constraint)
+		oddCandidate := 0
+		for _, candidate := range maskToSlice(triValue.candidateMask()) {
+			if counts[candidate] == 3 {
+				if oddCandidate != 0 {
+					return false // Ambiguous, not a BUG+1 pattern
+				}
+				oddCandidate = candidate
 			}
 		}
 
-		if cellIncluded {
-			// Add all other cells in this constraint
-			for _, idx := range constraint.GetCells() {
-				otherCell := b.GetCell(idx)
-				if otherCell != nil && otherCell != cell {
-					visibleMap[otherCell] = true
+		if oddCandidate == 0 || (solution != 0 && solution != oddCandidate) {
+			return false
+		}
+		solution = oddCandidate
+	}
+
+	if len(triUnits) == 0 || solution == 0 {
+		return false
+	}
+
+	// Without the surviving candidate, the board must be a grave in every unit
+	for _, unit := range units {
+		counts := b.unitCandidateCounts(unit)
+		if triUnits[unit] {
+			counts[solution]--
+		}
+		for candidate, count := range counts {
+			if count != 0 && count != 2 {
+				b.log().Debug("BUG+1 skipped: %d appears %d time(s) in %s", candidate, count, unit.GetName())
+				return false
+			}
+		}
+	}
+
+	b.solvingStep("BUG+1", "Found BUG+1: R%dC%d %v must be %d to avoid a deadly pattern",
+		triValue.GetRow()+1, triValue.GetCol()+1, maskToSlice(triValue.candidateMask()), solution)
+
+	if err := triValue.SetValue(solution); err != nil {
+		b.log().Error("BUG+1 failed to set R%dC%d: %v", triValue.GetRow()+1, triValue.GetCol()+1, err)
+		return false
+	}
+
+	return true
+}
+
+// unitCandidateCounts counts how many unsolved cells of a constraint hold each candidate;
+// candidates held by no cell are left out
+func (b *Board) unitCandidateCounts(c Constraint) map[int]int {
+	counts := make(map[int]int)
+	for _, idx := range c.GetCells() {
+		cell := b.GetCell(idx)
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		for _, candidate := range maskToSlice(cell.candidateMask()) {
+			counts[candidate]++
+		}
+	}
+	return counts
+}
+
+// HiddenSingle describes a cell that is the only place left for a digit within a unit
+type HiddenSingle struct {
+	Index int    // Cell index (0-80)
+	Value int    // The digit that can only go in this cell
+	Unit  string // Name of the constraint that forces it
+}
+
+// HiddenSingleCandidates returns every unsolved cell that is the sole remaining location
+// for some digit in a uniqueness constraint covering 9 cells (rows, columns, boxes and
+// jigsaw regions). Smaller uniqueness constraints such as killer cages are skipped since
+// they don't have to contain every digit. Each cell/digit pair is reported once, for the
+// first unit that forces it, ordered by cell index. The board is not modified.
+func (b *Board) HiddenSingleCandidates() []HiddenSingle {
+	found := make(map[[2]int]string)
+
+	for _, constraint := range b.constraints {
+		cells := constraint.GetCells()
+		if !b.isUnit(constraint) {
+			continue
+		}
+
+		for digit := 1; digit <= b.size; digit++ {
+			place := -1
+			placed := false
+			for _, idx := range cells {
+				cell := b.GetCell(idx)
+				if cell == nil {
+					continue
+				}
+				if cell.IsSolved() {
+					if cell.GetValue() == digit {
+						placed = true
+						break
+					}
+					continue
+				}
+				if cell.HasCandidate(digit) {
+					if place != -1 {
+						place = -2 // More than one location
+						break
+					}
+					place = idx
+				}
+			}
+
+			if placed || place < 0 {
+				continue
+			}
+			key := [2]int{place, digit}
+			if _, exists := found[key]; !exists {
+				found[key] = constraint.GetName()
+			}
+		}
+	}
+
+	singles := make([]HiddenSingle, 0, len(found))
+	for key, unit := range found {
+		singles = append(singles, HiddenSingle{Index: key[0], Value: key[1], Unit: unit})
+	}
+	sort.Slice(singles, func(i, j int) bool {
+		if singles[i].Index != singles[j].Index {
+			return singles[i].Index < singles[j].Index
+		}
+		return singles[i].Value < singles[j].Value
+	})
+
+	return singles
+}
+
+// LastDigitInUnit implements the "full house" check: for a 9-cell uniqueness constraint
+// with exactly one empty cell, it returns that cell's index and the single missing digit.
+// ok is false if the constraint doesn't enforce uniqueness over 9 cells, has more or
+// fewer than one empty cell, or its filled values don't leave exactly one digit.
+func (b *Board) LastDigitInUnit(c Constraint) (cellIndex, value int, ok bool) {
+	cells := c.GetCells()
+	if !b.isUnit(c) {
+		return 0, 0, false
+	}
+
+	empty := -1
+	seen := make(map[int]bool)
+	for _, idx := range cells {
+		cell := b.GetCell(idx)
+		if cell == nil {
+			return 0, 0, false
+		}
+		if !cell.IsSolved() {
+			if empty != -1 {
+				return 0, 0, false // More than one empty cell
+			}
+			empty = idx
+			continue
+		}
+		seen[cell.GetValue()] = true
+	}
+
+	if empty == -1 || len(seen) != b.size-1 {
+		return 0, 0, false
+	}
+
+	for digit := 1; digit <= b.size; digit++ {
+		if !seen[digit] {
+			return empty, digit, true
+		}
+	}
+	return 0, 0, false
+}
+
+// buildPeers fills the peer table and the peer lists from the uniqueness constraints on
+// the board
+func (b *Board) buildPeers() {
+	b.peers = [81][81]bool{}
+	for _, constraint := range b.constraints {
+		if !constraint.RequiresUniqueness() {
+			continue
+		}
+		cells := constraint.GetCells()
+		for _, a := range cells {
+			for _, c := range cells {
+				if a != c && a >= 0 && a <= 80 && c >= 0 && c <= 80 {
+					b.peers[a][c] = true
 				}
 			}
 		}
 	}
 
-	// Convert map to slice
-	visible := make([]*Cell, 0, len(visibleMap))
-	for c := range visibleMap {
-		visible = append(visible, c)
+	for a := range b.peerCells {
+		cells := make([]*Cell, 0, 20)
+		for c, isPeer := range b.peers[a] {
+			if isPeer && b.board[c] != nil {
+				cells = append(cells, b.board[c])
+			}
+		}
+		b.peerCells[a] = cells
+	}
+	b.peersBuilt = true
+}
+
+// AreVisible returns true if the cells at the two indices (0-80) share a uniqueness
+// constraint, so they can't hold the same value. A cell does not see itself.
+func (b *Board) AreVisible(a, c int) bool {
+	if a < 0 || a > 80 || c < 0 || c > 80 {
+		return false
+	}
+	if !b.peersBuilt {
+		b.buildPeers()
+	}
+	return b.peers[a][c]
+}
+
+// getVisibleCells returns all cells that share a uniqueness constraint with the given cell,
+// in index order. The slice is the cached peer list and must not be modified.
+func (b *Board) getVisibleCells(cell *Cell) []*Cell {
+	if !b.peersBuilt {
+		b.buildPeers()
 	}
+	return b.peerCells[cell.GetIndex()]
+}
 
-	return visible
+// VisibleCells returns the cells that share a uniqueness constraint with cell, in index
+// order, or nil if cell is not on this board. The lists are cached until a constraint is
+// added or removed, so repeated calls only copy the cached list.
+func (b *Board) VisibleCells(cell *Cell) []*Cell {
+	if cell == nil || cell.board != b {
+		return nil
+	}
+	return append([]*Cell{}, b.getVisibleCells(cell)...)
 }
 
 // AddObserver adds an observer to all cells on the board
@@ -672,7 +2027,7 @@ func (b *Board) AddObserver(obs observer.CellObserver) {
 		}
 	}
 
-	logger.Debug("Added observer to all board cells")
+	b.log().Debug("Added observer to all board cells")
 }
 
 // RemoveObserver removes an observer from all cells
@@ -692,5 +2047,75 @@ func (b *Board) RemoveObserver(obs observer.CellObserver) {
 		}
 	}
 
-	logger.Debug("Removed observer from all board cells")
+	b.log().Debug("Removed observer from all board cells")
+}
+
+// solvingStep logs a deduction and tells step observers that the cell events that
+// follow belong to it
+func (b *Board) solvingStep(technique string, format string, args ...interface{}) {
+	b.log().SolvingStep(technique, format, args...)
+	if len(b.observers) > 0 {
+		b.notifyStep(technique, fmt.Sprintf(format, args...))
+	}
+}
+
+// boardLogger forwards to the logger package, dropping everything but errors for a
+// quiet board
+type boardLogger struct {
+	quiet bool
+}
+
+// log returns the logger for messages about this board; a nil board logs normally
+func (b *Board) log() boardLogger {
+	return boardLogger{quiet: b != nil && b.quiet}
+}
+
+func (l boardLogger) Debug(format string, args ...interface{}) {
+	if !l.quiet {
+		logger.Debug(format, args...)
+	}
+}
+
+func (l boardLogger) Info(format string, args ...interface{}) {
+	if !l.quiet {
+		logger.Info(format, args...)
+	}
+}
+
+func (l boardLogger) Warn(format string, args ...interface{}) {
+	if !l.quiet {
+		logger.Warn(format, args...)
+	}
+}
+
+func (l boardLogger) Error(format string, args ...interface{}) {
+	logger.Error(format, args...)
+}
+
+func (l boardLogger) DebugCell(row, col int, format string, args ...interface{}) {
+	if !l.quiet {
+		logger.DebugCell(row, col, format, args...)
+	}
+}
+
+func (l boardLogger) InfoCell(row, col int, format string, args ...interface{}) {
+	if !l.quiet {
+		logger.InfoCell(row, col, format, args...)
+	}
+}
+
+func (l boardLogger) SolvingStep(technique string, format string, args ...interface{}) {
+	if !l.quiet {
+		logger.SolvingStep(technique, format, args...)
+	}
+}
+
+// notifyStep passes the current solving step to the observers that implement
+// observer.StepObserver. An empty technique ends the step.
+func (b *Board) notifyStep(technique, reason string) {
+	for _, obs := range b.observers {
+		if stepObs, ok := obs.(observer.StepObserver); ok {
+			stepObs.OnSolvingStep(technique, reason)
+		}
+	}
 }
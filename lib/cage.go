@@ -0,0 +1,82 @@
+package lib
+
+// applyCageLineReductions is the cage version of pointing pairs. When a digit must
+// appear in a cage (see RequiredDigitsReporter) and all of its remaining positions in
+// the cage lie in one row or column, the digit is eliminated from the rest of that
+// row or column outside the cage.
+func (b *Board) applyCageLineReductions() bool {
+	changed := false
+
+	for _, constraint := range b.constraints {
+		reporter, ok := constraint.(RequiredDigitsReporter)
+		if !ok {
+			continue
+		}
+
+		inCage := make(map[int]bool)
+		for _, idx := range constraint.GetCells() {
+			inCage[idx] = true
+		}
+
+		for _, digit := range reporter.RequiredDigits(b) {
+			positions := make([]*Cell, 0)
+			for _, idx := range constraint.GetCells() {
+				cell := b.GetCell(idx)
+				if cell != nil && cell.HasCandidate(digit) {
+					positions = append(positions, cell)
+				}
+			}
+			if len(positions) == 0 {
+				continue
+			}
+
+			sameRow, sameCol := true, true
+			for _, cell := range positions[1:] {
+				sameRow = sameRow && cell.GetRow() == positions[0].GetRow()
+				sameCol = sameCol && cell.GetCol() == positions[0].GetCol()
+			}
+
+			if sameRow {
+				row := positions[0].GetRow()
+				if cells := b.cellsOutsideCage(digit, inCage, true, row); len(cells) > 0 {
+					b.solvingStep("Cage Line Reduction", "%d must be in %s within row %d, removed from the rest of the row",
+						digit, constraint.GetName(), row+1)
+					removeCandidateFrom(cells, digit)
+					changed = true
+				}
+			}
+			if sameCol {
+				col := positions[0].GetCol()
+				if cells := b.cellsOutsideCage(digit, inCage, false, col); len(cells) > 0 {
+					b.solvingStep("Cage Line Reduction", "%d must be in %s within column %d, removed from the rest of the column",
+						digit, constraint.GetName(), col+1)
+					removeCandidateFrom(cells, digit)
+					changed = true
+				}
+			}
+		}
+	}
+
+	return changed
+}
+
+// cellsOutsideCage returns the cells of a row (rowBased) or column that are not part of
+// the cage and still have the digit
+func (b *Board) cellsOutsideCage(digit int, inCage map[int]bool, rowBased bool, line int) []*Cell {
+	var cells []*Cell
+	for pos := 0; pos < 9; pos++ {
+		cell := b.lineCell(line, pos, rowBased)
+		if cell == nil || inCage[cell.GetIndex()] || !cell.HasCandidate(digit) {
+			continue
+		}
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+// removeCandidateFrom removes the candidate from each of the cells
+func removeCandidateFrom(cells []*Cell, candidate int) {
+	for _, cell := range cells {
+		cell.RemoveCandidate(candidate)
+	}
+}
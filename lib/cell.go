@@ -1,9 +1,10 @@
 package lib
 
 import (
-	"github.com/eftil/sudoku-solver.git/lib/logger"
+	"fmt"
+	"math/bits"
+
 	"github.com/eftil/sudoku-solver.git/lib/observer"
-	"github.com/eftil/sudoku-solver.git/lib/utils"
 )
 
 type Cell struct {
@@ -12,17 +13,42 @@ type Cell struct {
 	index      int
 	value      int
 	board      *Board
-	candidates map[int]bool
+	candidates uint16 // Bit n set means n is a candidate (bits 1-9)
 	notifier   *observer.CellNotifier
+
+	// isGiven marks the cell's value as an original clue of the puzzle
+	isGiven bool
 }
 
-func NewCell(row, col int, board *Board) *Cell {
-	candidates := make(map[int]bool)
-	for i := 1; i <= 9; i++ {
-		candidates[i] = true
+// allCandidates is the candidate mask with every digit 1-9 set
+const allCandidates uint16 = 0x3FE
+
+// candidateBit returns the mask bit for a candidate, or 0 if it is out of range
+func candidateBit(candidate int) uint16 {
+	if candidate < 1 || candidate > 9 {
+		return 0
 	}
+	return 1 << uint(candidate)
+}
 
-	logger.DebugCell(row, col, "Cell created with all candidates available")
+// maskToSlice lists the candidates in a mask in ascending order
+func maskToSlice(mask uint16) []int {
+	result := make([]int, 0, bits.OnesCount16(mask))
+	for candidate := 1; candidate <= 9; candidate++ {
+		if mask&candidateBit(candidate) != 0 {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+func NewCell(row, col int, board *Board) *Cell {
+	board.log().DebugCell(row, col, "Cell created with all candidates available")
+
+	candidates := allCandidates
+	if board != nil {
+		candidates = board.fullMask()
+	}
 
 	return &Cell{
 		row:        row,
@@ -55,9 +81,12 @@ func (c *Cell) GetBoard() *Board {
 	return c.board
 }
 
+// SetValue places a value, or clears the cell with 0. Clearing or replacing a value
+// rebuilds the board's candidates with RecomputeAllCandidates, so the old value's
+// eliminations don't linger.
 func (c *Cell) SetValue(value int) error {
 	if value < 0 || value > 9 {
-		logger.Error("Cell R%dC%d: Invalid value %d (must be 0-9)", c.row+1, c.col+1, value)
+		c.board.log().Error("Cell R%dC%d: Invalid value %d (must be 0-9)", c.row+1, c.col+1, value)
 		return &BoardError{Message: "value must be between 0 and 9"}
 	}
 
@@ -65,10 +94,10 @@ func (c *Cell) SetValue(value int) error {
 	c.value = value
 
 	if value != 0 {
-		logger.InfoCell(c.row, c.col, "Value set to %d (previous: %d)", value, oldValue)
+		c.board.log().InfoCell(c.row, c.col, "Value set to %d (previous: %d)", value, oldValue)
 
 		// Clear candidates when a value is set
-		c.candidates = make(map[int]bool)
+		c.candidates = 0
 
 		// Notify observers that cell is solved (including constraints!)
 		// This automatically propagates to all constraints via the observer pattern
@@ -76,33 +105,80 @@ func (c *Cell) SetValue(value int) error {
 			c.notifier.NotifyCellSolved(c.row, c.col, value)
 		}
 
-		logger.DebugCell(c.row, c.col, "Notified observers about value %d", value)
-	} else {
-		logger.DebugCell(c.row, c.col, "Value cleared (was: %d)", oldValue)
+		c.board.log().DebugCell(c.row, c.col, "Notified observers about value %d", value)
+	} else if oldValue != 0 {
+		c.board.log().DebugCell(c.row, c.col, "Value cleared (was: %d)", oldValue)
+	}
+
+	// A replaced value no longer rules out candidates anywhere, and a cleared cell lost its
+	// own candidates when it was set, so rebuild them from scratch
+	if oldValue != 0 && oldValue != value && c.board != nil {
+		c.board.RecomputeAllCandidates()
 	}
 
 	return nil
 }
 
+// MarkGiven records the cell's current value as an original clue of the puzzle.
+// Empty cells cannot be givens.
+func (c *Cell) MarkGiven() {
+	if c.value == 0 {
+		c.board.log().Warn("Cell R%dC%d: cannot mark an empty cell as given", c.row+1, c.col+1)
+		return
+	}
+	c.isGiven = true
+}
+
+// IsGiven returns true if the cell holds an original clue
+func (c *Cell) IsGiven() bool {
+	return c.isGiven && c.value != 0
+}
+
 // Note: AddConstraint and GetConstraints removed!
 // Constraints are now observers and don't need to be tracked separately
 
-// GetCandidates returns the current candidates for this cell
+// GetCandidates returns the current candidates for this cell as a new map.
+// Internal code works on the bitmask directly, see candidateMask.
 func (c *Cell) GetCandidates() map[int]bool {
+	candidates := make(map[int]bool)
+	for _, candidate := range maskToSlice(c.candidateMask()) {
+		candidates[candidate] = true
+	}
+	return candidates
+}
+
+// candidateMask returns the candidates as a bitmask, empty if the cell is solved
+func (c *Cell) candidateMask() uint16 {
 	if c.value != 0 {
-		return make(map[int]bool) // No candidates if value is set
+		return 0
 	}
 	return c.candidates
 }
 
+// SortedCandidates returns the candidates in ascending order, read straight from the
+// bitmask, whatever the board's display order. Use it in solving logic, and instead of
+// sorting the map from GetCandidates.
+func (c *Cell) SortedCandidates() []int {
+	return maskToSlice(c.candidateMask())
+}
+
+// CandidateSlice returns the candidates in the board's display order (ascending by default,
+// see Board.SetCandidateOrder), for showing them. SortedCandidates is always ascending.
+func (c *Cell) CandidateSlice() []int {
+	if c.board == nil {
+		return maskToSlice(c.candidateMask())
+	}
+	return c.board.orderCandidates(c.candidateMask())
+}
+
 // RemoveCandidate removes a candidate from this cell
 func (c *Cell) RemoveCandidate(candidate int) {
-	if c.value == 0 && c.candidates[candidate] {
-		delete(c.candidates, candidate)
-		remainingCount := len(c.candidates)
+	if c.value == 0 && c.candidates&candidateBit(candidate) != 0 {
+		c.candidates &^= candidateBit(candidate)
+		remainingCount := bits.OnesCount16(c.candidates)
 
-		logger.DebugCell(c.row, c.col, "Removed candidate %d (remaining: %v)",
-			candidate, utils.GetCandidatesAsSlice(c.candidates))
+		c.board.log().DebugCell(c.row, c.col, "Removed candidate %d (remaining: %v)",
+			candidate, maskToSlice(c.candidates))
 
 		// Notify observers
 		if c.notifier != nil {
@@ -110,23 +186,127 @@ func (c *Cell) RemoveCandidate(candidate int) {
 
 			// If only one candidate remains, notify that too
 			if remainingCount == 1 {
-				lastCandidate := utils.GetCandidatesAsSlice(c.candidates)[0]
-				logger.InfoCell(c.row, c.col, "Only one candidate remains: %d", lastCandidate)
+				lastCandidate := bits.TrailingZeros16(c.candidates)
+				c.board.log().InfoCell(c.row, c.col, "Only one candidate remains: %d", lastCandidate)
 				c.notifier.NotifySingleCandidate(c.row, c.col, lastCandidate)
 			}
 		}
 	}
 }
 
-// AddCandidate adds a candidate to this cell
+// RemoveCandidates removes several candidates from this cell at once. Observers are
+// notified after all of them are gone: one NotifyCandidatesEliminated for the candidates
+// that were present, then a single candidate notification if one is left, so they never
+// see the cell half updated. Candidates the cell doesn't have are ignored.
+func (c *Cell) RemoveCandidates(cands []int) {
+	if c.value != 0 {
+		return
+	}
+
+	var removed uint16
+	for _, candidate := range cands {
+		removed |= c.candidates & candidateBit(candidate)
+	}
+	if removed == 0 {
+		return
+	}
+
+	c.candidates &^= removed
+	remainingCount := bits.OnesCount16(c.candidates)
+	eliminated := maskToSlice(removed)
+
+	c.board.log().DebugCell(c.row, c.col, "Removed candidates %v (remaining: %v)",
+		eliminated, maskToSlice(c.candidates))
+
+	if c.notifier != nil {
+		c.notifier.NotifyCandidatesEliminated(c.row, c.col, eliminated, remainingCount)
+
+		if remainingCount == 1 {
+			lastCandidate := bits.TrailingZeros16(c.candidates)
+			c.board.log().InfoCell(c.row, c.col, "Only one candidate remains: %d", lastCandidate)
+			c.notifier.NotifySingleCandidate(c.row, c.col, lastCandidate)
+		}
+	}
+}
+
+// RemoveCandidateReversible removes a candidate like RemoveCandidate and returns a
+// function that puts it back. Restoring fires no notifications, so observers never see
+// a spurious elimination or single candidate event. Restoring is a no-op if the
+// candidate wasn't present or the cell has been solved since.
+func (c *Cell) RemoveCandidateReversible(candidate int) func() {
+	if !c.HasCandidate(candidate) {
+		return func() {}
+	}
+
+	c.RemoveCandidate(candidate)
+
+	return func() {
+		if c.value != 0 || c.HasCandidate(candidate) {
+			return
+		}
+		c.candidates |= candidateBit(candidate)
+		c.board.log().DebugCell(c.row, c.col, "Restored candidate %d (total: %v)",
+			candidate, maskToSlice(c.candidates))
+	}
+}
+
+// AddCandidate adds a candidate to this cell. Digits beyond the board's size are ignored.
 func (c *Cell) AddCandidate(candidate int) {
+	if c.board != nil && candidate > c.board.size {
+		return
+	}
 	if c.value == 0 && candidate >= 1 && candidate <= 9 {
-		if !c.candidates[candidate] {
-			c.candidates[candidate] = true
-			logger.DebugCell(c.row, c.col, "Added candidate %d (total: %v)",
-				candidate, utils.GetCandidatesAsSlice(c.candidates))
+		if c.candidates&candidateBit(candidate) == 0 {
+			c.candidates |= candidateBit(candidate)
+			c.board.log().DebugCell(c.row, c.col, "Added candidate %d (total: %v)",
+				candidate, maskToSlice(c.candidates))
+		}
+	}
+}
+
+// SetCandidates replaces the candidates of an empty cell with exactly the given digits,
+// for loading saved pencil marks. New digits are added silently like AddCandidate, then
+// every other digit is removed with RemoveCandidate, so observers see one elimination
+// per removed digit and a single candidate notification if one digit is left. A cell
+// that gains its only candidate without losing any also notifies the single candidate.
+func (c *Cell) SetCandidates(cands []int) error {
+	if c.value != 0 {
+		c.board.log().Error("Cell R%dC%d: cannot set candidates of a solved cell", c.row+1, c.col+1)
+		return &BoardError{Message: fmt.Sprintf("cell R%dC%d is already solved", c.row+1, c.col+1)}
+	}
+	if len(cands) == 0 {
+		return &BoardError{Message: fmt.Sprintf("cell R%dC%d needs at least one candidate", c.row+1, c.col+1)}
+	}
+
+	size := DefaultSize
+	if c.board != nil {
+		size = c.board.size
+	}
+	var mask uint16
+	for _, candidate := range cands {
+		if candidate < 1 || candidate > size {
+			c.board.log().Error("Cell R%dC%d: Invalid candidate %d (must be 1-%d)", c.row+1, c.col+1, candidate, size)
+			return &BoardError{Message: fmt.Sprintf("candidate must be between 1 and %d, got %d", size, candidate)}
 		}
+		mask |= candidateBit(candidate)
+	}
+
+	if mask == c.candidates {
+		return nil
 	}
+
+	added := mask &^ c.candidates
+	removed := c.candidates &^ mask
+	c.candidates |= added
+	for _, candidate := range maskToSlice(removed) {
+		c.RemoveCandidate(candidate)
+	}
+
+	c.board.log().DebugCell(c.row, c.col, "Candidates set to %v", maskToSlice(c.candidates))
+	if removed == 0 && bits.OnesCount16(c.candidates) == 1 && c.notifier != nil {
+		c.notifier.NotifySingleCandidate(c.row, c.col, bits.TrailingZeros16(c.candidates))
+	}
+	return nil
 }
 
 // HasCandidate checks if a candidate is available for this cell
@@ -134,7 +314,7 @@ func (c *Cell) HasCandidate(candidate int) bool {
 	if c.value != 0 {
 		return false
 	}
-	return c.candidates[candidate]
+	return c.candidates&candidateBit(candidate) != 0
 }
 
 // IsSolved returns true if the cell has a value set
@@ -147,7 +327,16 @@ func (c *Cell) CandidateCount() int {
 	if c.value != 0 {
 		return 0
 	}
-	return len(c.candidates)
+	return bits.OnesCount16(c.candidates)
+}
+
+// CanSee returns true if this cell shares a uniqueness constraint with the other cell
+// on the same board. Backed by the board's cached peer table, so it is O(1).
+func (c *Cell) CanSee(other *Cell) bool {
+	if other == nil || c.board == nil || other.board != c.board {
+		return false
+	}
+	return c.board.AreVisible(c.index, other.index)
 }
 
 // GetNotifier returns the cell's notifier for adding observers
@@ -0,0 +1,136 @@
+package lib
+
+// applyColoring implements simple coloring (single-digit chains) for every candidate
+func (b *Board) applyColoring() bool {
+	changed := false
+	for candidate := 1; candidate <= 9; candidate++ {
+		if b.applyColoringForCandidate(candidate) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// applyColoringForCandidate links the cells of every conjugate pair on the candidate, a
+// 9-cell uniqueness constraint where it appears exactly twice, and two-colors each chain
+// of pairs. Exactly one color of a chain holds the candidate, so:
+//   - if two cells of one color see each other, that color is false everywhere (color wrap)
+//   - a cell outside the chain that sees both colors cannot hold it (color trap)
+func (b *Board) applyColoringForCandidate(candidate int) bool {
+	// Find conjugate pairs, scanning each unit the way the fish code scans lines
+	links := make(map[*Cell][]*Cell)
+	for _, constraint := range b.constraints {
+		cells := constraint.GetCells()
+		if !b.isUnit(constraint) {
+			continue
+		}
+
+		positions := make([]*Cell, 0, 2)
+		for _, idx := range cells {
+			cell := b.GetCell(idx)
+			if cell != nil && !cell.IsSolved() && cell.HasCandidate(candidate) {
+				positions = append(positions, cell)
+			}
+		}
+		if len(positions) == 2 {
+			links[positions[0]] = append(links[positions[0]], positions[1])
+			links[positions[1]] = append(links[positions[1]], positions[0])
+		}
+	}
+
+	changed := false
+	colored := make(map[*Cell]bool)
+	for idx := 0; idx < 81; idx++ {
+		start := b.GetCell(idx)
+		if start == nil || len(links[start]) == 0 || colored[start] {
+			continue
+		}
+
+		// Two-color the chain containing start
+		color := map[*Cell]int{start: 0}
+		groups := [2][]*Cell{{start}, nil}
+		queue := []*Cell{start}
+		for len(queue) > 0 {
+			cell := queue[0]
+			queue = queue[1:]
+			for _, next := range links[cell] {
+				if _, seen := color[next]; seen {
+					continue
+				}
+				color[next] = 1 - color[cell]
+				groups[color[next]] = append(groups[color[next]], next)
+				queue = append(queue, next)
+			}
+		}
+		for cell := range color {
+			colored[cell] = true
+		}
+
+		if len(groups[0])+len(groups[1]) < 3 {
+			continue // A single pair eliminates nothing beyond what the pair's unit already does
+		}
+
+		if b.applyColorWrap(candidate, groups) {
+			changed = true
+			continue
+		}
+		if b.applyColorTrap(candidate, color) {
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// applyColorWrap removes the candidate from every cell of a color that has two cells
+// seeing each other, since that color cannot hold the candidate
+func (b *Board) applyColorWrap(candidate int, groups [2][]*Cell) bool {
+	for _, group := range groups {
+		for i, cell1 := range group {
+			for _, cell2 := range group[i+1:] {
+				if !cell1.CanSee(cell2) {
+					continue
+				}
+
+				b.solvingStep("Simple Coloring", "Color wrap on %d: R%dC%d and R%dC%d share a color and see each other, removing %d from %d cell(s)",
+					candidate, cell1.GetRow()+1, cell1.GetCol()+1, cell2.GetRow()+1, cell2.GetCol()+1, candidate, len(group))
+				for _, cell := range group {
+					cell.RemoveCandidate(candidate)
+				}
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyColorTrap removes the candidate from cells outside the chain that see both colors
+func (b *Board) applyColorTrap(candidate int, color map[*Cell]int) bool {
+	changed := false
+	for idx := 0; idx < 81; idx++ {
+		cell := b.GetCell(idx)
+		if cell == nil || cell.IsSolved() || !cell.HasCandidate(candidate) {
+			continue
+		}
+		if _, inChain := color[cell]; inChain {
+			continue
+		}
+
+		var sees [2]*Cell
+		for _, peer := range b.getVisibleCells(cell) {
+			if c, inChain := color[peer]; inChain && sees[c] == nil {
+				sees[c] = peer
+			}
+		}
+		if sees[0] == nil || sees[1] == nil {
+			continue
+		}
+
+		b.solvingStep("Simple Coloring", "Color trap on %d: R%dC%d sees R%dC%d and R%dC%d of opposite colors, removing %d",
+			candidate, cell.GetRow()+1, cell.GetCol()+1,
+			sees[0].GetRow()+1, sees[0].GetCol()+1, sees[1].GetRow()+1, sees[1].GetCol()+1, candidate)
+		cell.RemoveCandidate(candidate)
+		changed = true
+	}
+	return changed
+}
@@ -1,7 +1,10 @@
 package lib
 
 import (
-	"github.com/eftil/sudoku-solver.git/lib/logger"
+	"fmt"
+	"math/bits"
+	"strings"
+
 	"github.com/eftil/sudoku-solver.git/lib/observer"
 	"github.com/eftil/sudoku-solver.git/lib/utils"
 )
@@ -34,6 +37,69 @@ type Constraint interface {
 	// RequiresUniqueness returns true if this constraint enforces uniqueness
 	// (used to determine if pencil mark techniques apply)
 	RequiresUniqueness() bool
+
+	// ImpactScore estimates how many candidate eliminations the constraint could produce
+	// on the current board. It is a cheap heuristic for ordering, not an exact count.
+	ImpactScore(board *Board) int
+}
+
+// ConstraintViolation describes one specific way a constraint is broken on the board
+type ConstraintViolation struct {
+	ConstraintName string
+	Cells          []int  // Indices (0-80) of the conflicting cells
+	Value          int    // The violating value: the duplicate, a cage's actual sum, a whisper pair's difference
+	Message        string // Human-readable explanation
+}
+
+// Violation is the name used by Board.ValidateAllDetailed for a ConstraintViolation
+type Violation = ConstraintViolation
+
+// ViolationReporter is implemented by constraints that can explain which cells break them.
+// Violations is only meaningful when IsValid returns false.
+type ViolationReporter interface {
+	Violations(board *Board) []ConstraintViolation
+}
+
+// Initializer is an optional interface for constraints that restrict candidates on their
+// own, before any value is placed, such as an even-digit cell or the position bounds of a
+// thermometer. AddConstraint calls Initialize after setting the board reference and
+// registering the observers, and the board calls it again whenever it rebuilds candidates
+// from scratch. BaseConstraint provides a no-op.
+type Initializer interface {
+	Initialize(board *Board)
+}
+
+// RequiredDigitsReporter is implemented by constraints that know digits which must
+// appear among their cells even though the region has fewer than 9 cells, such as a
+// killer cage whose every possible sum combination contains them
+type RequiredDigitsReporter interface {
+	RequiredDigits(board *Board) []int
+}
+
+// DuplicateViolations reports every value that appears more than once among the given
+// cells, one violation per value listing all cells holding it
+func DuplicateViolations(board *Board, name string, cellIndices []int) []ConstraintViolation {
+	positions := make(map[int][]int)
+	for _, idx := range cellIndices {
+		cell := board.GetCell(idx)
+		if cell == nil || cell.GetValue() == 0 {
+			continue
+		}
+		positions[cell.GetValue()] = append(positions[cell.GetValue()], idx)
+	}
+
+	violations := make([]ConstraintViolation, 0)
+	for value := 1; value <= 9; value++ {
+		if len(positions[value]) > 1 {
+			violations = append(violations, ConstraintViolation{
+				ConstraintName: name,
+				Cells:          positions[value],
+				Value:          value,
+				Message:        fmt.Sprintf("%d appears %d times", value, len(positions[value])),
+			})
+		}
+	}
+	return violations
 }
 
 // BaseConstraint provides common functionality for all constraints
@@ -41,6 +107,10 @@ type BaseConstraint struct {
 	Cells []int
 	Name  string
 	Board *Board // Exported so embedded constraints can access it
+
+	// self is the concrete constraint embedding this base, so observer
+	// callbacks can dispatch to its PropagateValueChange override
+	self Constraint
 }
 
 func (bc *BaseConstraint) GetCells() []int {
@@ -56,15 +126,27 @@ func (bc *BaseConstraint) SetBoard(board *Board) {
 	bc.Board = board
 }
 
+// bindSelf records the concrete constraint that embeds this base
+func (bc *BaseConstraint) bindSelf(c Constraint) {
+	bc.self = c
+}
+
+// Initialize does nothing by default; see Initializer
+func (bc *BaseConstraint) Initialize(board *Board) {}
+
 // PropagateValueChange is called when a cell value changes (via observer pattern)
 // Subclasses should override this to implement specific propagation logic
 func (bc *BaseConstraint) PropagateValueChange(row, col, value int) {
 	// Base implementation does nothing
-	logger.Debug("BaseConstraint: PropagateValueChange called for R%dC%d = %d", row+1, col+1, value)
+	bc.Board.log().Debug("BaseConstraint: PropagateValueChange called for R%dC%d = %d", row+1, col+1, value)
 }
 
 // OnCellSolved is called when a cell is solved (observer interface)
 func (bc *BaseConstraint) OnCellSolved(row, col, value int) {
+	if bc.self != nil {
+		bc.self.PropagateValueChange(row, col, value)
+		return
+	}
 	bc.PropagateValueChange(row, col, value)
 }
 
@@ -78,6 +160,18 @@ func (bc *BaseConstraint) OnCandidateEliminated(row, col, candidate, remainingCo
 	// Base implementation doesn't need to do anything
 }
 
+// OnCandidatesEliminated is called when several candidates are eliminated at once
+// (observer.CandidatesObserver). It hands each one to the concrete constraint's
+// OnCandidateEliminated, so constraints only need to override that.
+func (bc *BaseConstraint) OnCandidatesEliminated(row, col int, candidates []int, remainingCount int) {
+	if bc.self == nil {
+		return
+	}
+	for _, candidate := range candidates {
+		bc.self.OnCandidateEliminated(row, col, candidate, remainingCount)
+	}
+}
+
 func (bc *BaseConstraint) RequiresUniqueness() bool {
 	// Base constraint doesn't require uniqueness by default
 	return false
@@ -88,6 +182,58 @@ func (bc *BaseConstraint) ApplyPencilMarkConstraints(board *Board) bool {
 	return false
 }
 
+func (bc *BaseConstraint) ImpactScore(board *Board) int {
+	// Base implementation has no estimate
+	return 0
+}
+
+// UniquenessImpact estimates the eliminations a uniqueness constraint over the given
+// cells can produce: candidates that repeat a value already placed in the cells, plus,
+// for 9-cell units, the other candidates of any cell that is the only place for a digit
+func UniquenessImpact(board *Board, cellIndices []int) int {
+	if board == nil {
+		return 0
+	}
+
+	placed := make(map[int]bool)
+	unsolved := make([]*Cell, 0, len(cellIndices))
+	for _, idx := range cellIndices {
+		cell := board.GetCell(idx)
+		if cell == nil {
+			continue
+		}
+		if cell.IsSolved() {
+			placed[cell.GetValue()] = true
+		} else {
+			unsolved = append(unsolved, cell)
+		}
+	}
+
+	score := 0
+	locations := make(map[int][]*Cell)
+	for _, cell := range unsolved {
+		for _, candidate := range maskToSlice(cell.candidateMask()) {
+			if placed[candidate] {
+				score++ // Pending elimination
+				continue
+			}
+			locations[candidate] = append(locations[candidate], cell)
+		}
+	}
+
+	if len(cellIndices) != board.size {
+		return score // Smaller regions don't have to contain every digit
+	}
+
+	for _, cells := range locations {
+		if len(cells) == 1 {
+			score += cells[0].CandidateCount() - 1 // Hidden single clears the rest of the cell
+		}
+	}
+
+	return score
+}
+
 // HasUniqueNonZeros checks if all non-zero values in a slice are unique
 // Deprecated: Use utils.HasUniqueNonZeros instead
 func HasUniqueNonZeros(values []int) bool {
@@ -102,7 +248,7 @@ func ApplyNakedSubsets(board *Board, cellIndices []int, maxSubsetSize int) bool
 		return false
 	}
 
-	logger.Debug("Applying naked subsets (max size: %d) to %d cells", maxSubsetSize, len(cellIndices))
+	board.log().Debug("Applying naked subsets (max size: %d) to %d cells", maxSubsetSize, len(cellIndices))
 	changed := false
 
 	// Get all unsolved cells
@@ -130,40 +276,41 @@ func ApplyNakedSubsets(board *Board, cellIndices []int, maxSubsetSize int) bool
 
 		for _, combo := range combinations {
 			// Get the union of candidates for this subset
-			candidateUnion := make(map[int]bool)
+			var candidateUnion uint16
 			subsetCells := make([]*Cell, 0, subsetSize)
 
 			for _, idx := range combo {
 				cell := unsolvedCells[idx]
 				subsetCells = append(subsetCells, cell)
-				candidates := cell.GetCandidates()
-				for candidate := range candidates {
-					candidateUnion[candidate] = true
-				}
+				candidateUnion |= cell.candidateMask()
 			}
 
 			// If the union has exactly subsetSize candidates, we found a naked subset
-			if len(candidateUnion) == subsetSize {
-				logger.Debug("Found naked subset of size %d with candidates: %v",
-					subsetSize, utils.GetCandidatesAsSlice(candidateUnion))
+			if bits.OnesCount16(candidateUnion) == subsetSize {
+				board.log().Debug("Found naked subset of size %d with candidates: %v",
+					subsetSize, maskToSlice(candidateUnion))
 
-				// Remove these candidates from all cells NOT in the subset
-				eliminatedCount := 0
+				// These candidates can be removed from all cells NOT in the subset
+				targets := make([]*Cell, 0)
 				for _, cell := range unsolvedCells {
-					if !contains(subsetCells, cell) {
-						for candidate := range candidateUnion {
-							if cell.HasCandidate(candidate) {
-								cell.RemoveCandidate(candidate)
-								changed = true
-								eliminatedCount++
-							}
-						}
+					if !contains(subsetCells, cell) && cell.candidateMask()&candidateUnion != 0 {
+						targets = append(targets, cell)
 					}
 				}
+				if len(targets) == 0 {
+					continue
+				}
 
-				if eliminatedCount > 0 {
-					logger.Info("Naked subset eliminated %d candidate(s)", eliminatedCount)
+				board.solvingStep("Naked "+subsetName(subsetSize), "%s can only hold %v, removed from %s",
+					cellNames(subsetCells), maskToSlice(candidateUnion), cellNames(targets))
+				eliminatedCount := 0
+				for _, cell := range targets {
+					removed := cell.candidateMask() & candidateUnion
+					cell.RemoveCandidates(maskToSlice(removed))
+					eliminatedCount += bits.OnesCount16(removed)
 				}
+				changed = true
+				board.log().Info("Naked subset eliminated %d candidate(s)", eliminatedCount)
 			}
 		}
 	}
@@ -173,13 +320,15 @@ func ApplyNakedSubsets(board *Board, cellIndices []int, maxSubsetSize int) bool
 
 // ApplyHiddenSubsets implements the hidden pairs/triples/quads technique
 // When n candidates appear in exactly n cells (and nowhere else in the constraint),
-// those cells can't contain any other candidates
+// those cells can't contain any other candidates.
+// This relies on every digit having to appear in the region, so regions with fewer
+// than 9 cells (killer cages, Renban lines, ...) are skipped.
 func ApplyHiddenSubsets(board *Board, cellIndices []int, maxSubsetSize int) bool {
-	if board == nil || len(cellIndices) == 0 {
+	if board == nil || len(cellIndices) < board.size {
 		return false
 	}
 
-	logger.Debug("Applying hidden subsets (max size: %d) to %d cells", maxSubsetSize, len(cellIndices))
+	board.log().Debug("Applying hidden subsets (max size: %d) to %d cells", maxSubsetSize, len(cellIndices))
 	changed := false
 
 	// Get all unsolved cells
@@ -195,23 +344,23 @@ func ApplyHiddenSubsets(board *Board, cellIndices []int, maxSubsetSize int) bool
 		return false
 	}
 
-	// Build a map of candidate -> cells that have it
-	candidateLocations := make(map[int][]*Cell)
-	for candidate := 1; candidate <= 9; candidate++ {
-		candidateLocations[candidate] = make([]*Cell, 0)
+	// A uniqueness region holds at most 9 distinct digits, so cell positions fit a mask
+	if len(unsolvedCells) > 9 {
+		return false
 	}
 
-	for _, cell := range unsolvedCells {
-		candidates := cell.GetCandidates()
-		for candidate := range candidates {
-			candidateLocations[candidate] = append(candidateLocations[candidate], cell)
+	// Build a mask of candidate -> positions (in unsolvedCells) of the cells that have it
+	var candidateLocations [10]uint16
+	for pos, cell := range unsolvedCells {
+		for _, candidate := range maskToSlice(cell.candidateMask()) {
+			candidateLocations[candidate] |= 1 << uint(pos)
 		}
 	}
 
 	// Get candidates that appear in at least 2 cells
 	activeCandidates := make([]int, 0)
 	for candidate := 1; candidate <= 9; candidate++ {
-		if len(candidateLocations[candidate]) >= 2 {
+		if bits.OnesCount16(candidateLocations[candidate]) >= 2 {
 			activeCandidates = append(activeCandidates, candidate)
 		}
 	}
@@ -230,42 +379,43 @@ func ApplyHiddenSubsets(board *Board, cellIndices []int, maxSubsetSize int) bool
 		combinations := utils.GenerateCombinations(len(activeCandidates), subsetSize)
 
 		for _, combo := range combinations {
-			// Get the candidates in this subset
-			subsetCandidates := make([]int, 0, subsetSize)
+			// Get the candidates in this subset and the union of cells containing any of them
+			var subsetCandidates, cellUnion uint16
 			for _, idx := range combo {
-				subsetCandidates = append(subsetCandidates, activeCandidates[idx])
-			}
-
-			// Get the union of cells that contain any of these candidates
-			cellUnion := make(map[*Cell]bool)
-			for _, candidate := range subsetCandidates {
-				for _, cell := range candidateLocations[candidate] {
-					cellUnion[cell] = true
-				}
+				candidate := activeCandidates[idx]
+				subsetCandidates |= candidateBit(candidate)
+				cellUnion |= candidateLocations[candidate]
 			}
 
 			// If exactly subsetSize cells contain these candidates, it's a hidden subset
-			if len(cellUnion) == subsetSize {
-				logger.Debug("Found hidden subset of size %d with candidates: %v",
-					subsetSize, subsetCandidates)
+			if bits.OnesCount16(cellUnion) == subsetSize {
+				board.log().Debug("Found hidden subset of size %d with candidates: %v",
+					subsetSize, maskToSlice(subsetCandidates))
 
 				// These cells can only contain these candidates
-				eliminatedCount := 0
-				for cell := range cellUnion {
-					for candidate := 1; candidate <= 9; candidate++ {
-						if !utils.ContainsInt(subsetCandidates, candidate) {
-							if cell.HasCandidate(candidate) {
-								cell.RemoveCandidate(candidate)
-								changed = true
-								eliminatedCount++
-							}
-						}
+				subsetCells := make([]*Cell, 0, subsetSize)
+				extra := false
+				for pos, cell := range unsolvedCells {
+					if cellUnion&(1<<uint(pos)) != 0 {
+						subsetCells = append(subsetCells, cell)
+						extra = extra || cell.candidateMask()&^subsetCandidates != 0
 					}
 				}
+				if !extra {
+					continue
+				}
 
-				if eliminatedCount > 0 {
-					logger.Info("Hidden subset eliminated %d candidate(s)", eliminatedCount)
+				board.solvingStep("Hidden "+subsetName(subsetSize), "%v only fit in %s, other candidates removed from them",
+					maskToSlice(subsetCandidates), cellNames(subsetCells))
+				eliminatedCount := 0
+				for _, cell := range subsetCells {
+					if removed := cell.candidateMask() &^ subsetCandidates; removed != 0 {
+						cell.RemoveCandidates(maskToSlice(removed))
+						eliminatedCount += bits.OnesCount16(removed)
+					}
 				}
+				changed = true
+				board.log().Info("Hidden subset eliminated %d candidate(s)", eliminatedCount)
 			}
 		}
 	}
@@ -273,6 +423,29 @@ func ApplyHiddenSubsets(board *Board, cellIndices []int, maxSubsetSize int) bool
 	return changed
 }
 
+// subsetName names a subset of the given size for solving steps
+func subsetName(size int) string {
+	switch size {
+	case 2:
+		return "Pair"
+	case 3:
+		return "Triple"
+	case 4:
+		return "Quad"
+	default:
+		return fmt.Sprintf("Subset (%d)", size)
+	}
+}
+
+// cellNames lists cells in R1C1 notation, for solving step reasons
+func cellNames(cells []*Cell) string {
+	names := make([]string, len(cells))
+	for i, cell := range cells {
+		names[i] = fmt.Sprintf("R%dC%d", cell.row+1, cell.col+1)
+	}
+	return strings.Join(names, ", ")
+}
+
 // Helper function to check if a cell is in a slice of cells
 func contains(cells []*Cell, target *Cell) bool {
 	for _, cell := range cells {
@@ -0,0 +1,117 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// AllDifferentConstraint requires every listed cell to hold a distinct value. It is the
+// uniqueness primitive behind many variant groups: used on its own for custom groups, and
+// embedded by the region, cage and jigsaw constraints, which only add their name,
+// description and saved form.
+type AllDifferentConstraint struct {
+	lib.BaseConstraint
+}
+
+// newAllDifferent returns the uniqueness base for a named group of cells, for embedding.
+// The caller validates the cells.
+func newAllDifferent(name string, cells []int) AllDifferentConstraint {
+	return AllDifferentConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: cells,
+			Name:  name,
+		},
+	}
+}
+
+// NewAllDifferentConstraint creates a uniqueness group of 2 to 9 distinct cells
+func NewAllDifferentConstraint(cells []int) (*AllDifferentConstraint, error) {
+	if len(cells) < 2 {
+		return nil, fmt.Errorf("all-different group must have at least 2 cells, got %d", len(cells))
+	}
+
+	if len(cells) > 9 {
+		return nil, fmt.Errorf("all-different group cannot have more than 9 cells, got %d", len(cells))
+	}
+
+	seen := make(map[int]bool, len(cells))
+	for _, cell := range cells {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+		if seen[cell] {
+			return nil, fmt.Errorf("all-different group lists cell %d more than once", cell)
+		}
+		seen[cell] = true
+	}
+
+	ad := newAllDifferent("All Different", cells)
+	return &ad, nil
+}
+
+func (ad *AllDifferentConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	values := make([]int, len(ad.Cells))
+	for i, cellIdx := range ad.Cells {
+		values[i] = board.Get(cellIdx/9, cellIdx%9)
+	}
+	return lib.HasUniqueNonZeros(values), nil
+}
+
+// Violations reports each duplicated value and the cells holding it
+func (ad *AllDifferentConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	return lib.DuplicateViolations(board, ad.GetName(), ad.Cells)
+}
+
+func (ad *AllDifferentConstraint) GetDescription() string {
+	return fmt.Sprintf("All Different with %d cells - values must be unique", len(ad.Cells))
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (ad *AllDifferentConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeAllDifferent, ad.Cells, nil)
+}
+
+// PropagateValueChange removes a placed value from the candidates of the other cells
+// in the group
+func (ad *AllDifferentConstraint) PropagateValueChange(row, col, value int) {
+	if value == 0 || ad.Board == nil {
+		return
+	}
+
+	for _, cellIndex := range ad.Cells {
+		otherRow, otherCol := cellIndex/9, cellIndex%9
+		if otherRow == row && otherCol == col {
+			continue
+		}
+		otherCell := ad.Board.GetCellAt(otherRow, otherCol)
+		if otherCell != nil && !otherCell.IsSolved() {
+			otherCell.RemoveCandidate(value)
+		}
+	}
+}
+
+func (ad *AllDifferentConstraint) RequiresUniqueness() bool {
+	return true
+}
+
+func (ad *AllDifferentConstraint) ImpactScore(board *lib.Board) int {
+	return lib.UniquenessImpact(board, ad.Cells)
+}
+
+func (ad *AllDifferentConstraint) ApplyPencilMarkConstraints(board *lib.Board) bool {
+	// Naked and hidden subsets up to quads, or the size of a smaller group
+	maxSize := 4
+	if len(ad.Cells) < maxSize {
+		maxSize = len(ad.Cells)
+	}
+
+	changed := false
+	changed = lib.ApplyNakedSubsets(board, ad.Cells, maxSize) || changed
+	changed = lib.ApplyHiddenSubsets(board, ad.Cells, maxSize) || changed
+	return changed
+}
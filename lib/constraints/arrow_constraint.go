@@ -0,0 +1,189 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// ArrowConstraint ensures the shaft values sum to the number in the bulb.
+// A single bulb cell holds a digit; a bulb of two cells holds a two-digit number
+// read from the first cell to the second.
+type ArrowConstraint struct {
+	lib.BaseConstraint
+	bulb  []int
+	shaft []int
+}
+
+// NewArrowConstraint creates an arrow. Most arrows have a single bulb cell; pass two
+// bulb cells for a two-digit bulb.
+func NewArrowConstraint(bulbCells []int, shaftCells []int) (*ArrowConstraint, error) {
+	if len(bulbCells) < 1 || len(bulbCells) > 2 {
+		return nil, fmt.Errorf("arrow bulb must have one or two cells, got %d", len(bulbCells))
+	}
+
+	if len(shaftCells) == 0 {
+		return nil, fmt.Errorf("arrow shaft must have at least one cell")
+	}
+
+	seen := make(map[int]bool)
+	cells := make([]int, 0, len(bulbCells)+len(shaftCells))
+	for _, cell := range append(append([]int{}, bulbCells...), shaftCells...) {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+		if seen[cell] {
+			return nil, fmt.Errorf("cell %d appears more than once in the arrow", cell)
+		}
+		seen[cell] = true
+		cells = append(cells, cell)
+	}
+
+	return &ArrowConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: cells,
+			Name:  "Arrow",
+		},
+		bulb:  append([]int{}, bulbCells...),
+		shaft: append([]int{}, shaftCells...),
+	}, nil
+}
+
+// bulbRange returns the smallest and largest number the bulb can still hold.
+// If override is a bulb cell, digit is used for it instead of its current value.
+func (ac *ArrowConstraint) bulbRange(board *lib.Board, override, digit int) (int, int) {
+	low, high := 0, 0
+	for _, idx := range ac.bulb {
+		val := board.Get(idx/9, idx%9)
+		if idx == override {
+			val = digit
+		}
+
+		low, high = low*10, high*10
+		if val == 0 {
+			low, high = low+1, high+9
+		} else {
+			low, high = low+val, high+val
+		}
+	}
+	return low, high
+}
+
+// shaftRange returns the smallest and largest sum the shaft can still reach.
+// If override is a shaft cell, digit is used for it instead of its current value.
+func (ac *ArrowConstraint) shaftRange(board *lib.Board, override, digit int) (int, int) {
+	low, high := 0, 0
+	for _, idx := range ac.shaft {
+		val := board.Get(idx/9, idx%9)
+		if idx == override {
+			val = digit
+		}
+
+		if val == 0 {
+			low, high = low+1, high+9
+		} else {
+			low, high = low+val, high+val
+		}
+	}
+	return low, high
+}
+
+func (ac *ArrowConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	// Once everything is filled both ranges collapse to single values
+	bulbLow, bulbHigh := ac.bulbRange(board, -1, 0)
+	shaftLow, shaftHigh := ac.shaftRange(board, -1, 0)
+	return shaftLow <= bulbHigh && shaftHigh >= bulbLow, nil
+}
+
+// Violations reports the filled arrow cells when the shaft can no longer match the bulb,
+// with the shaft's current sum as the value
+func (ac *ArrowConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	if valid, err := ac.IsValid(board); err != nil || valid {
+		return nil
+	}
+
+	sum := 0
+	filled := make([]int, 0, len(ac.Cells))
+	for _, idx := range ac.Cells {
+		if val := board.Get(idx/9, idx%9); val != 0 {
+			filled = append(filled, idx)
+		}
+	}
+	for _, idx := range ac.shaft {
+		sum += board.Get(idx/9, idx%9)
+	}
+
+	bulbLow, bulbHigh := ac.bulbRange(board, -1, 0)
+	message := fmt.Sprintf("shaft sum %d cannot match bulb %d", sum, bulbLow)
+	if bulbLow != bulbHigh {
+		message = fmt.Sprintf("shaft sum %d cannot match a bulb between %d and %d", sum, bulbLow, bulbHigh)
+	}
+
+	return []lib.ConstraintViolation{{
+		ConstraintName: ac.GetName(),
+		Cells:          filled,
+		Value:          sum,
+		Message:        message,
+	}}
+}
+
+func (ac *ArrowConstraint) GetDescription() string {
+	return fmt.Sprintf("Arrow with a %d-cell bulb and %d-cell shaft - shaft values must sum to the bulb",
+		len(ac.bulb), len(ac.shaft))
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (ac *ArrowConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeArrow, ac.Cells, arrowParams{Bulb: len(ac.bulb)})
+}
+
+// PropagateValueChange removes bulb and shaft candidates that would leave the shaft's
+// possible sums and the bulb's possible values without overlap.
+// This is called automatically via the observer pattern when a cell is solved
+func (ac *ArrowConstraint) PropagateValueChange(row, col, value int) {
+	if value == 0 {
+		return // No value set, nothing to propagate
+	}
+
+	// Get the board from the base constraint
+	if ac.Board == nil {
+		return
+	}
+
+	for _, idx := range ac.shaft {
+		cell := ac.Board.GetCell(idx)
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		bulbLow, bulbHigh := ac.bulbRange(ac.Board, -1, 0)
+		for candidate := 1; candidate <= 9; candidate++ {
+			low, high := ac.shaftRange(ac.Board, idx, candidate)
+			if low > bulbHigh || high < bulbLow {
+				cell.RemoveCandidate(candidate)
+			}
+		}
+	}
+
+	for _, idx := range ac.bulb {
+		cell := ac.Board.GetCell(idx)
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		shaftLow, shaftHigh := ac.shaftRange(ac.Board, -1, 0)
+		for candidate := 1; candidate <= 9; candidate++ {
+			low, high := ac.bulbRange(ac.Board, idx, candidate)
+			if shaftLow > high || shaftHigh < low {
+				cell.RemoveCandidate(candidate)
+			}
+		}
+	}
+}
+
+func (ac *ArrowConstraint) RequiresUniqueness() bool {
+	// Shaft digits may repeat when the shaft crosses regions
+	return false
+}
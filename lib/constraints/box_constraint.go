@@ -6,24 +6,37 @@ import (
 	"github.com/eftil/sudoku-solver.git/lib"
 )
 
-// BoxConstraint ensures all values in a 3x3 box are unique
+// BoxConstraint ensures all values in a box are unique
 type BoxConstraint struct {
 	lib.BaseConstraint
-	box int
+	box        int
+	rows, cols int
 }
 
+// NewBoxConstraint creates the constraint for a 3x3 box of a classic 9x9 board
 func NewBoxConstraint(box int) (*BoxConstraint, error) {
-	if box < 0 || box > 8 {
-		return nil, fmt.Errorf("box must be between 0 and 8, got %d", box)
+	return NewBoxConstraintOfSize(box, lib.DefaultSize)
+}
+
+// NewBoxConstraintOfSize creates the constraint for a box of a size x size board, shaped
+// as returned by lib.BoxShape. Boxes are numbered row by row from the top left.
+func NewBoxConstraintOfSize(box, size int) (*BoxConstraint, error) {
+	rows, cols, err := lib.BoxShape(size)
+	if err != nil {
+		return nil, err
+	}
+	if box < 0 || box >= size {
+		return nil, fmt.Errorf("box must be between 0 and %d, got %d", size-1, box)
 	}
 
-	cells := make([]int, 9)
-	boxRow := (box / 3) * 3
-	boxCol := (box % 3) * 3
+	cells := make([]int, size)
+	boxesPerRow := size / cols
+	boxRow := (box / boxesPerRow) * rows
+	boxCol := (box % boxesPerRow) * cols
 
 	idx := 0
-	for r := 0; r < 3; r++ {
-		for c := 0; c < 3; c++ {
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
 			cells[idx] = (boxRow+r)*9 + (boxCol + c)
 			idx++
 		}
@@ -34,7 +47,9 @@ func NewBoxConstraint(box int) (*BoxConstraint, error) {
 			Cells: cells,
 			Name:  fmt.Sprintf("Box %d", box+1),
 		},
-		box: box,
+		box:  box,
+		rows: rows,
+		cols: cols,
 	}, nil
 }
 
@@ -43,12 +58,25 @@ func (bc *BoxConstraint) IsValid(board *lib.Board) (bool, error) {
 		return false, fmt.Errorf("board cannot be nil")
 	}
 
-	boxData := board.GetBox(bc.box)
-	return lib.HasUniqueNonZeros(boxData[:]), nil
+	values := make([]int, len(bc.Cells))
+	for i, cellIdx := range bc.Cells {
+		values[i] = board.Get(cellIdx/9, cellIdx%9)
+	}
+	return lib.HasUniqueNonZeros(values), nil
+}
+
+// Violations reports each duplicated value and the cells holding it
+func (bc *BoxConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	return lib.DuplicateViolations(board, bc.GetName(), bc.Cells)
 }
 
 func (bc *BoxConstraint) GetDescription() string {
-	return fmt.Sprintf("All values in 3x3 box %d must be unique (1-9)", bc.box+1)
+	return fmt.Sprintf("All values in %dx%d box %d must be unique (1-%d)", bc.rows, bc.cols, bc.box+1, len(bc.Cells))
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (bc *BoxConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeBox, bc.Cells, boxParams{Box: bc.box})
 }
 
 // PropagateValueChange propagates the value change to other cells in the box
@@ -79,6 +107,10 @@ func (bc *BoxConstraint) RequiresUniqueness() bool {
 	return true
 }
 
+func (bc *BoxConstraint) ImpactScore(board *lib.Board) int {
+	return lib.UniquenessImpact(board, bc.Cells)
+}
+
 func (bc *BoxConstraint) ApplyPencilMarkConstraints(board *lib.Board) bool {
 	// Apply both naked and hidden subset techniques up to quads (size 4)
 	changed := false
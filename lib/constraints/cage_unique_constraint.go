@@ -0,0 +1,45 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// CageUniqueConstraint is a cage drawn without a sum clue: its values must be unique,
+// but their total is unknown. The uniqueness rules come from AllDifferentConstraint.
+type CageUniqueConstraint struct {
+	AllDifferentConstraint
+}
+
+func NewCageUniqueConstraint(cells []int) (*CageUniqueConstraint, error) {
+	if len(cells) == 0 {
+		return nil, fmt.Errorf("cage must have at least one cell")
+	}
+
+	if len(cells) > 9 {
+		return nil, fmt.Errorf("cage cannot have more than 9 cells, got %d", len(cells))
+	}
+
+	seen := make(map[int]bool, len(cells))
+	for _, cell := range cells {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+		if seen[cell] {
+			return nil, fmt.Errorf("cage lists cell %d more than once", cell)
+		}
+		seen[cell] = true
+	}
+
+	return &CageUniqueConstraint{AllDifferentConstraint: newAllDifferent("Cage", cells)}, nil
+}
+
+func (cu *CageUniqueConstraint) GetDescription() string {
+	return fmt.Sprintf("Cage with %d cells and no sum - values must be unique", len(cu.GetCells()))
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (cu *CageUniqueConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeCageUnique, cu.Cells, nil)
+}
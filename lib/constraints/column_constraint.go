@@ -9,16 +9,26 @@ import (
 // ColumnConstraint ensures all values in a column are unique
 type ColumnConstraint struct {
 	lib.BaseConstraint
-	col int
+	col  int
+	size int
 }
 
+// NewColumnConstraint creates the constraint for a column of a classic 9x9 board
 func NewColumnConstraint(col int) (*ColumnConstraint, error) {
-	if col < 0 || col > 8 {
-		return nil, fmt.Errorf("column must be between 0 and 8, got %d", col)
+	return NewColumnConstraintOfSize(col, lib.DefaultSize)
+}
+
+// NewColumnConstraintOfSize creates the constraint for a column of a size x size board
+func NewColumnConstraintOfSize(col, size int) (*ColumnConstraint, error) {
+	if _, _, err := lib.BoxShape(size); err != nil {
+		return nil, err
+	}
+	if col < 0 || col >= size {
+		return nil, fmt.Errorf("column must be between 0 and %d, got %d", size-1, col)
 	}
 
-	cells := make([]int, 9)
-	for row := 0; row < 9; row++ {
+	cells := make([]int, size)
+	for row := 0; row < size; row++ {
 		cells[row] = row*9 + col
 	}
 
@@ -27,7 +37,8 @@ func NewColumnConstraint(col int) (*ColumnConstraint, error) {
 			Cells: cells,
 			Name:  fmt.Sprintf("Column %d", col+1),
 		},
-		col: col,
+		col:  col,
+		size: size,
 	}, nil
 }
 
@@ -40,8 +51,18 @@ func (cc *ColumnConstraint) IsValid(board *lib.Board) (bool, error) {
 	return lib.HasUniqueNonZeros(colData[:]), nil
 }
 
+// Violations reports each duplicated value and the cells holding it
+func (cc *ColumnConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	return lib.DuplicateViolations(board, cc.GetName(), cc.Cells)
+}
+
 func (cc *ColumnConstraint) GetDescription() string {
-	return fmt.Sprintf("All values in column %d must be unique (1-9)", cc.col+1)
+	return fmt.Sprintf("All values in column %d must be unique (1-%d)", cc.col+1, cc.size)
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (cc *ColumnConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeColumn, cc.Cells, columnParams{Column: cc.col})
 }
 
 // PropagateValueChange propagates the value change to other cells in the column
@@ -72,6 +93,10 @@ func (cc *ColumnConstraint) RequiresUniqueness() bool {
 	return true
 }
 
+func (cc *ColumnConstraint) ImpactScore(board *lib.Board) int {
+	return lib.UniquenessImpact(board, cc.Cells)
+}
+
 func (cc *ColumnConstraint) ApplyPencilMarkConstraints(board *lib.Board) bool {
 	// Apply both naked and hidden subset techniques up to quads (size 4)
 	changed := false
@@ -0,0 +1,135 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// EvenOddConstraint restricts its cells to even digits {2,4,6,8} or to odd digits
+// {1,3,5,7,9}. Candidates of the wrong parity are removed as soon as it is added to a
+// board, so it needs no propagation afterwards.
+type EvenOddConstraint struct {
+	lib.BaseConstraint
+	even bool
+}
+
+// NewEvenConstraint restricts the cells to even digits
+func NewEvenConstraint(cells []int) (*EvenOddConstraint, error) {
+	return newEvenOddConstraint(cells, true)
+}
+
+// NewOddConstraint restricts the cells to odd digits
+func NewOddConstraint(cells []int) (*EvenOddConstraint, error) {
+	return newEvenOddConstraint(cells, false)
+}
+
+func newEvenOddConstraint(cells []int, even bool) (*EvenOddConstraint, error) {
+	if len(cells) == 0 {
+		return nil, fmt.Errorf("even/odd constraint must have at least one cell")
+	}
+
+	for _, cell := range cells {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+	}
+
+	name := "Odd"
+	if even {
+		name = "Even"
+	}
+
+	return &EvenOddConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: cells,
+			Name:  name,
+		},
+		even: even,
+	}, nil
+}
+
+// IsEven reports whether the cells must hold even digits
+func (eo *EvenOddConstraint) IsEven() bool {
+	return eo.even
+}
+
+// allows reports whether a digit has the required parity
+func (eo *EvenOddConstraint) allows(digit int) bool {
+	return (digit%2 == 0) == eo.even
+}
+
+// Initialize removes the candidates of the wrong parity from the cells
+func (eo *EvenOddConstraint) Initialize(board *lib.Board) {
+	for _, cellIdx := range eo.Cells {
+		cell := board.GetCell(cellIdx)
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		for candidate := 1; candidate <= 9; candidate++ {
+			if !eo.allows(candidate) {
+				cell.RemoveCandidate(candidate)
+			}
+		}
+	}
+}
+
+func (eo *EvenOddConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	return len(eo.wrong(board)) == 0, nil
+}
+
+// wrong returns the filled cells holding a digit of the wrong parity
+func (eo *EvenOddConstraint) wrong(board *lib.Board) []int {
+	cells := make([]int, 0)
+	for _, cellIdx := range eo.Cells {
+		val := board.Get(cellIdx/9, cellIdx%9)
+		if val != 0 && !eo.allows(val) {
+			cells = append(cells, cellIdx)
+		}
+	}
+	return cells
+}
+
+// Violations reports each filled cell holding a digit of the wrong parity
+func (eo *EvenOddConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	violations := make([]lib.ConstraintViolation, 0)
+	for _, cellIdx := range eo.wrong(board) {
+		val := board.Get(cellIdx/9, cellIdx%9)
+		violations = append(violations, lib.ConstraintViolation{
+			ConstraintName: eo.GetName(),
+			Cells:          []int{cellIdx},
+			Value:          val,
+			Message:        fmt.Sprintf("%d is not %s", val, eo.parity()),
+		})
+	}
+	return violations
+}
+
+// parity returns "even" or "odd"
+func (eo *EvenOddConstraint) parity() string {
+	if eo.even {
+		return "even"
+	}
+	return "odd"
+}
+
+func (eo *EvenOddConstraint) GetDescription() string {
+	return fmt.Sprintf("%d cell(s) that must hold %s digits", len(eo.Cells), eo.parity())
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (eo *EvenOddConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	if eo.even {
+		return newSpec(TypeEven, eo.Cells, nil)
+	}
+	return newSpec(TypeOdd, eo.Cells, nil)
+}
+
+func (eo *EvenOddConstraint) RequiresUniqueness() bool {
+	// Cells of the same parity may repeat
+	return false
+}
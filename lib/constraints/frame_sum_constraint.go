@@ -0,0 +1,229 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// FrameSumConstraint is an outside clue of a frame sudoku: the first few digits of a row
+// or column, read in from the clue's edge, sum to the clue. The cells share a row or
+// column, so the digits are distinct.
+type FrameSumConstraint struct {
+	lib.BaseConstraint
+	sum int
+}
+
+// NewFrameSumConstraint creates a frame clue over the first count cells of cells, given in
+// order from the edge the clue sits on. cells may be the whole line or just its start, but
+// must run straight in from the edge. count must be 1-3 and the sum reachable with count
+// distinct digits.
+func NewFrameSumConstraint(cells []int, sum int, count int) (*FrameSumConstraint, error) {
+	if count < 1 || count > 3 {
+		return nil, fmt.Errorf("frame clue must cover 1 to 3 cells, got %d", count)
+	}
+
+	if len(cells) < count {
+		return nil, fmt.Errorf("frame clue over %d cells needs at least %d cells, got %d", count, count, len(cells))
+	}
+
+	for _, cell := range cells {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+	}
+
+	if !isEdgeSegment(cells) {
+		return nil, fmt.Errorf("frame clue cells must run straight along a row or column from its edge")
+	}
+
+	lower, upper := distinctSumRange(0, count)
+	if sum < lower || sum > upper {
+		return nil, fmt.Errorf("frame sum %d is not reachable with %d distinct digits (%d-%d)",
+			sum, count, lower, upper)
+	}
+
+	return &FrameSumConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: append([]int{}, cells[:count]...),
+			Name:  fmt.Sprintf("Frame Sum (%d)", sum),
+		},
+		sum: sum,
+	}, nil
+}
+
+// isEdgeSegment reports whether cells step one at a time along a row or column, starting
+// on the edge they move away from. A single cell only has to be on an edge.
+func isEdgeSegment(cells []int) bool {
+	first := cells[0]
+	row, col := first/9, first%9
+	if len(cells) == 1 {
+		return row == 0 || row == 8 || col == 0 || col == 8
+	}
+
+	step := cells[1] - first
+	switch step {
+	case 1:
+		if col != 0 {
+			return false
+		}
+	case -1:
+		if col != 8 {
+			return false
+		}
+	case 9:
+		if row != 0 {
+			return false
+		}
+	case -9:
+		if row != 8 {
+			return false
+		}
+	default:
+		return false
+	}
+
+	for i := 1; i < len(cells); i++ {
+		if cells[i]-cells[i-1] != step {
+			return false
+		}
+	}
+	// A horizontal step must not wrap onto the next row
+	return cells[len(cells)-1]/9 == row || step == 9 || step == -9
+}
+
+// distinctSumRange returns the smallest and largest sums of n distinct digits, none of
+// them in used (bit d set for digit d). If fewer than n digits are free the range is
+// empty, with lower above upper.
+func distinctSumRange(used uint16, n int) (lower, upper int) {
+	picked := 0
+	for digit := 1; digit <= 9 && picked < n; digit++ {
+		if used&(1<<digit) == 0 {
+			lower += digit
+			picked++
+		}
+	}
+	if picked < n {
+		return 1, 0
+	}
+
+	picked = 0
+	for digit := 9; digit >= 1 && picked < n; digit-- {
+		if used&(1<<digit) == 0 {
+			upper += digit
+			picked++
+		}
+	}
+	return lower, upper
+}
+
+// total returns the sum of the filled cells, the mask of their digits and the number of
+// empty cells
+func (fs *FrameSumConstraint) total(board *lib.Board) (sum int, used uint16, empty int) {
+	for _, cellIdx := range fs.Cells {
+		val := board.Get(cellIdx/9, cellIdx%9)
+		if val == 0 {
+			empty++
+			continue
+		}
+		sum += val
+		used |= 1 << val
+	}
+	return sum, used, empty
+}
+
+func (fs *FrameSumConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	sum, used, empty := fs.total(board)
+	if empty == 0 {
+		return sum == fs.sum, nil
+	}
+
+	// The empty cells still add distinct digits not yet used in the clue
+	lower, upper := distinctSumRange(used, empty)
+	return sum+lower <= fs.sum && sum+upper >= fs.sum, nil
+}
+
+// Violations reports the filled cells when the clue can no longer be reached, with their
+// current sum as the value
+func (fs *FrameSumConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	if valid, err := fs.IsValid(board); err != nil || valid {
+		return nil
+	}
+
+	sum, _, _ := fs.total(board)
+	filled := make([]int, 0, len(fs.Cells))
+	for _, cellIdx := range fs.Cells {
+		if board.Get(cellIdx/9, cellIdx%9) != 0 {
+			filled = append(filled, cellIdx)
+		}
+	}
+
+	return []lib.ConstraintViolation{{
+		ConstraintName: fs.GetName(),
+		Cells:          filled,
+		Value:          sum,
+		Message:        fmt.Sprintf("frame sum is %d, clue is %d", sum, fs.sum),
+	}}
+}
+
+func (fs *FrameSumConstraint) GetDescription() string {
+	return fmt.Sprintf("Frame clue - the first %d digits from the edge must sum to %d", len(fs.Cells), fs.sum)
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON. Only the counted
+// cells are saved, so the count is their number.
+func (fs *FrameSumConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeFrameSum, fs.Cells, frameSumParams{Sum: fs.sum})
+}
+
+// Initialize bounds the candidates by the clue before any value is placed
+func (fs *FrameSumConstraint) Initialize(board *lib.Board) {
+	fs.restrict(board)
+}
+
+// PropagateValueChange tightens the candidates of the empty cells to what the clue still allows
+// This is called automatically via the observer pattern when a cell is solved
+func (fs *FrameSumConstraint) PropagateValueChange(row, col, value int) {
+	if value == 0 {
+		return // No value set, nothing to propagate
+	}
+
+	// Get the board from the base constraint
+	if fs.Board == nil {
+		return
+	}
+
+	fs.restrict(fs.Board)
+}
+
+// restrict removes candidates that would leave the other empty cells unable to make up
+// the rest of the clue with distinct unused digits
+func (fs *FrameSumConstraint) restrict(board *lib.Board) {
+	sum, used, empty := fs.total(board)
+	if empty == 0 {
+		return
+	}
+
+	remaining := fs.sum - sum
+	for _, cellIdx := range fs.Cells {
+		cell := board.GetCell(cellIdx)
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		for _, candidate := range cell.SortedCandidates() {
+			lower, upper := distinctSumRange(used|1<<candidate, empty-1)
+			if used&(1<<candidate) != 0 || candidate+lower > remaining || candidate+upper < remaining {
+				cell.RemoveCandidate(candidate)
+			}
+		}
+	}
+}
+
+func (fs *FrameSumConstraint) RequiresUniqueness() bool {
+	// The row or column constraint of the line enforces uniqueness
+	return false
+}
@@ -0,0 +1,27 @@
+package constraints
+
+import (
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/logger"
+)
+
+// GenerateBoard creates a new puzzle with a unique solution: the standard constraints
+// plus any extra ones are added to an empty board, a random solution is filled in by the
+// backtracking search, and clues are removed one at a time while CountSolutions(2) stays
+// 1. With symmetrical set, clues are removed in pairs mirrored through the center. The
+// same seed always gives the same puzzle. Without extra constraints this only generates
+// classic sudoku; variant puzzles need their constraints passed in. Returns nil if the
+// constraints admit no solution.
+func GenerateBoard(symmetrical bool, seed int64, extra ...lib.Constraint) *lib.Board {
+	board := lib.NewBoard()
+	if err := AddStandardConstraints(board); err != nil {
+		logger.Error("Failed to add standard constraints: %v", err)
+		return nil
+	}
+	for _, c := range extra {
+		board.AddConstraint(c)
+	}
+
+	board.SetRandomSeed(seed)
+	return board.GeneratePuzzle(symmetrical)
+}
@@ -64,10 +64,45 @@ func (gw *GermanWhispersConstraint) IsValid(board *lib.Board) (bool, error) {
 	return true, nil
 }
 
+// Violations reports each adjacent pair on the line whose difference is below 5
+func (gw *GermanWhispersConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	violations := make([]lib.ConstraintViolation, 0)
+
+	cells := gw.GetCells()
+	for i := 0; i < len(cells)-1; i++ {
+		val1 := board.Get(cells[i]/9, cells[i]%9)
+		val2 := board.Get(cells[i+1]/9, cells[i+1]%9)
+		if val1 == 0 || val2 == 0 {
+			continue
+		}
+
+		diff := val1 - val2
+		if diff < 0 {
+			diff = -diff
+		}
+
+		if diff < 5 {
+			violations = append(violations, lib.ConstraintViolation{
+				ConstraintName: gw.GetName(),
+				Cells:          []int{cells[i], cells[i+1]},
+				Value:          diff,
+				Message:        fmt.Sprintf("adjacent values %d and %d differ by %d, need at least 5", val1, val2, diff),
+			})
+		}
+	}
+
+	return violations
+}
+
 func (gw *GermanWhispersConstraint) GetDescription() string {
 	return fmt.Sprintf("German whispers line with %d cells - adjacent values must differ by at least 5", len(gw.GetCells()))
 }
 
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (gw *GermanWhispersConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeGermanWhispers, gw.Cells, nil)
+}
+
 // PropagateValueChange propagates the value change to adjacent cells in the German Whispers line
 // This is called automatically via the observer pattern when a cell is solved
 func (gw *GermanWhispersConstraint) PropagateValueChange(row, col, value int) {
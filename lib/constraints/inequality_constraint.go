@@ -0,0 +1,149 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// InequalityConstraint is a greater-than clue between two cells: the value of the greater
+// cell must exceed the value of the lesser one. Chained clues make a thermometer.
+type InequalityConstraint struct {
+	lib.BaseConstraint
+}
+
+// NewInequalityConstraint creates a clue requiring value[greaterCell] > value[lesserCell].
+// The cells need not be adjacent but must differ.
+func NewInequalityConstraint(greaterCell, lesserCell int) (*InequalityConstraint, error) {
+	for _, cell := range []int{greaterCell, lesserCell} {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+	}
+
+	if greaterCell == lesserCell {
+		return nil, fmt.Errorf("inequality cells must differ, got %d twice", greaterCell)
+	}
+
+	return &InequalityConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: []int{greaterCell, lesserCell},
+			Name:  "Inequality",
+		},
+	}, nil
+}
+
+// Greater returns the index of the cell that holds the larger value
+func (ic *InequalityConstraint) Greater() int {
+	return ic.Cells[0]
+}
+
+// Lesser returns the index of the cell that holds the smaller value
+func (ic *InequalityConstraint) Lesser() int {
+	return ic.Cells[1]
+}
+
+func (ic *InequalityConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	greater := board.Get(ic.Greater()/9, ic.Greater()%9)
+	lesser := board.Get(ic.Lesser()/9, ic.Lesser()%9)
+
+	// Skip if either cell is empty
+	if greater == 0 || lesser == 0 {
+		return true, nil
+	}
+
+	return greater > lesser, nil
+}
+
+// Violations reports the pair when both values are set and the greater cell's isn't larger
+func (ic *InequalityConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	if valid, err := ic.IsValid(board); err != nil || valid {
+		return nil
+	}
+
+	greater := board.Get(ic.Greater()/9, ic.Greater()%9)
+	lesser := board.Get(ic.Lesser()/9, ic.Lesser()%9)
+
+	return []lib.ConstraintViolation{{
+		ConstraintName: ic.GetName(),
+		Cells:          []int{ic.Greater(), ic.Lesser()},
+		Value:          greater,
+		Message:        fmt.Sprintf("cell %d holds %d, which is not greater than %d in cell %d", ic.Greater(), greater, lesser, ic.Lesser()),
+	}}
+}
+
+func (ic *InequalityConstraint) GetDescription() string {
+	return fmt.Sprintf("Inequality - cell %d must be greater than cell %d", ic.Greater(), ic.Lesser())
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON. The greater cell
+// comes first.
+func (ic *InequalityConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeInequality, ic.Cells, nil)
+}
+
+// Initialize removes the smallest digit from the greater cell and the largest from the
+// lesser, then applies any values already placed
+func (ic *InequalityConstraint) Initialize(board *lib.Board) {
+	if cell := board.GetCell(ic.Greater()); cell != nil && !cell.IsSolved() {
+		cell.RemoveCandidate(1)
+	}
+	if cell := board.GetCell(ic.Lesser()); cell != nil && !cell.IsSolved() {
+		cell.RemoveCandidate(board.Size())
+	}
+
+	for _, cellIdx := range ic.Cells {
+		if value := board.Get(cellIdx/9, cellIdx%9); value != 0 {
+			ic.restrict(board, cellIdx, value)
+		}
+	}
+}
+
+// PropagateValueChange keeps the partner's candidates on the right side of the value
+// This is called automatically via the observer pattern when a cell is solved
+func (ic *InequalityConstraint) PropagateValueChange(row, col, value int) {
+	if value == 0 {
+		return // No value set, nothing to propagate
+	}
+
+	// Get the board from the base constraint
+	if ic.Board == nil {
+		return
+	}
+
+	ic.restrict(ic.Board, row*9+col, value)
+}
+
+// restrict removes the partner candidates that conflict with value in cellIndex: those
+// not below it if cellIndex is the greater cell, those not above it otherwise
+func (ic *InequalityConstraint) restrict(board *lib.Board, cellIndex, value int) {
+	var partnerIndex int
+	switch cellIndex {
+	case ic.Greater():
+		partnerIndex = ic.Lesser()
+	case ic.Lesser():
+		partnerIndex = ic.Greater()
+	default:
+		return // Cell not in this constraint
+	}
+
+	partner := board.GetCell(partnerIndex)
+	if partner == nil || partner.IsSolved() {
+		return
+	}
+
+	for _, candidate := range partner.SortedCandidates() {
+		if (cellIndex == ic.Greater() && candidate >= value) || (cellIndex == ic.Lesser() && candidate <= value) {
+			partner.RemoveCandidate(candidate)
+		}
+	}
+}
+
+func (ic *InequalityConstraint) RequiresUniqueness() bool {
+	// The values differ, but the clue only compares them and doesn't act as a uniqueness region
+	return false
+}
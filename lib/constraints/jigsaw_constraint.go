@@ -0,0 +1,124 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// JigsawRegionConstraint ensures all values in an irregular 9-cell region are unique
+type JigsawRegionConstraint struct {
+	AllDifferentConstraint
+	region int
+}
+
+// NewJigsawConstraints builds the nine region constraints of a jigsaw sudoku.
+// regionMap assigns each cell (by index 0-80) to a region 0-8, and each region
+// must contain exactly 9 cells. The returned constraints replace the standard boxes.
+func NewJigsawConstraints(regionMap [81]int) ([]lib.Constraint, error) {
+	regionCells, err := jigsawRegionCells(regionMap)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]lib.Constraint, 0, 9)
+	for region, cells := range regionCells {
+		jc, err := newJigsawRegionConstraint(cells, region)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, jc)
+	}
+
+	return result, nil
+}
+
+// AddJigsawRegions sets up a jigsaw sudoku on a 9x9 board: the nine row and nine column
+// constraints, and the region constraints of NewJigsawConstraints in place of the
+// standard boxes. The regions are JigsawRegionConstraints rather than RegionConstraints
+// so a jigsaw has one in-memory form and one saved type (jigsaw_region), whether it was
+// built here, with NewJigsawConstraints or loaded from JSON. layout[i] is the region
+// (0-8) of cell i, and every region must have exactly 9 cells. Nothing is added if the
+// layout is invalid.
+func AddJigsawRegions(board *lib.Board, layout [81]int) error {
+	if board == nil {
+		return fmt.Errorf("board cannot be nil")
+	}
+	if board.Size() != lib.DefaultSize {
+		return fmt.Errorf("jigsaw regions need a 9x9 board, got %dx%d", board.Size(), board.Size())
+	}
+
+	regions, err := NewJigsawConstraints(layout)
+	if err != nil {
+		return err
+	}
+
+	result := make([]lib.Constraint, 0, 27)
+	for i := 0; i < 9; i++ {
+		rc, err := NewRowConstraint(i)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", i+1, err)
+		}
+		cc, err := NewColumnConstraint(i)
+		if err != nil {
+			return fmt.Errorf("column %d: %w", i+1, err)
+		}
+		result = append(result, rc, cc, regions[i])
+	}
+
+	for _, c := range result {
+		board.AddConstraint(c)
+	}
+	return nil
+}
+
+// jigsawRegionCells splits a layout into the cells of each region, checking that every
+// cell names a region 0-8 and every region has exactly 9 cells
+func jigsawRegionCells(layout [81]int) ([][]int, error) {
+	regionCells := make([][]int, 9)
+	for cellIndex, region := range layout {
+		if region < 0 || region > 8 {
+			return nil, fmt.Errorf("cell %d has invalid region %d (must be 0-8)", cellIndex, region)
+		}
+		regionCells[region] = append(regionCells[region], cellIndex)
+	}
+
+	for region, cells := range regionCells {
+		if len(cells) != 9 {
+			return nil, fmt.Errorf("region %d has %d cells, expected 9", region, len(cells))
+		}
+	}
+	return regionCells, nil
+}
+
+// newJigsawRegionConstraint builds a single region; used by NewJigsawConstraints and
+// when loading a saved board, where regions are rebuilt one at a time
+func newJigsawRegionConstraint(cells []int, region int) (*JigsawRegionConstraint, error) {
+	if region < 0 || region > 8 {
+		return nil, fmt.Errorf("region must be between 0 and 8, got %d", region)
+	}
+
+	if len(cells) != 9 {
+		return nil, fmt.Errorf("region %d has %d cells, expected 9", region, len(cells))
+	}
+
+	for _, cell := range cells {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+	}
+
+	return &JigsawRegionConstraint{
+		AllDifferentConstraint: newAllDifferent(fmt.Sprintf("Jigsaw Region %d", region+1), cells),
+		region:                 region,
+	}, nil
+}
+
+func (jc *JigsawRegionConstraint) GetDescription() string {
+	return fmt.Sprintf("All values in jigsaw region %d must be unique (1-9)", jc.region+1)
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (jc *JigsawRegionConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeJigsawRegion, jc.Cells, jigsawParams{Region: jc.region})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/utils"
 )
 
 // KillerCageConstraint ensures values sum to a target and are unique
@@ -71,10 +72,42 @@ func (kc *KillerCageConstraint) IsValid(board *lib.Board) (bool, error) {
 	return sum <= kc.targetSum, nil
 }
 
+// Violations reports duplicated values in the cage and, when the sum is broken,
+// the filled cells with the actual sum compared to the target
+func (kc *KillerCageConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	violations := lib.DuplicateViolations(board, kc.GetName(), kc.Cells)
+
+	sum := 0
+	filled := make([]int, 0, len(kc.Cells))
+	for _, cellIdx := range kc.Cells {
+		if val := board.Get(cellIdx/9, cellIdx%9); val != 0 {
+			sum += val
+			filled = append(filled, cellIdx)
+		}
+	}
+
+	complete := len(filled) == len(kc.Cells)
+	if (complete && sum != kc.targetSum) || sum > kc.targetSum {
+		violations = append(violations, lib.ConstraintViolation{
+			ConstraintName: kc.GetName(),
+			Cells:          filled,
+			Value:          sum,
+			Message:        fmt.Sprintf("sum is %d, target is %d", sum, kc.targetSum),
+		})
+	}
+
+	return violations
+}
+
 func (kc *KillerCageConstraint) GetDescription() string {
 	return fmt.Sprintf("Killer cage with %d cells - values must sum to %d and be unique", len(kc.GetCells()), kc.targetSum)
 }
 
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (kc *KillerCageConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeKillerCage, kc.Cells, killerCageParams{Sum: kc.targetSum})
+}
+
 // PropagateValueChange propagates the value change to other cells in the killer cage
 // This is called automatically via the observer pattern when a cell is solved
 func (kc *KillerCageConstraint) PropagateValueChange(row, col, value int) {
@@ -101,60 +134,236 @@ func (kc *KillerCageConstraint) PropagateValueChange(row, col, value int) {
 		}
 	}
 
-	// Second: Calculate current sum and apply sum constraints
-	currentSum := 0
-	filledCount := 0
-	for _, idx := range cells {
-		r, c := idx/9, idx%9
-		otherCell := kc.Board.GetCellAt(r, c)
-		if otherCell != nil && otherCell.GetValue() != 0 {
-			currentSum += otherCell.GetValue()
-			filledCount++
-		}
-	}
-
-	remainingCells := len(cells) - filledCount
-	remainingSum := kc.targetSum - currentSum
-
-	// Update candidates for empty cells based on sum constraints
-	for _, idx := range cells {
-		r, c := idx/9, idx%9
-		otherCell := kc.Board.GetCellAt(r, c)
-		if otherCell != nil && otherCell.GetValue() == 0 {
-			// Remove candidates that would violate sum constraint
-			for candidate := 1; candidate <= 9; candidate++ {
-				// Check if this candidate would make the sum impossible
-				if remainingCells == 1 {
-					// Last cell must equal remaining sum
-					if candidate != remainingSum {
-						otherCell.RemoveCandidate(candidate)
-					}
-				} else {
-					// Check if remaining sum is achievable with remaining cells
-					minPossibleSum := remainingCells - 1
-					maxPossibleSum := (remainingCells - 1) * 9
-					if remainingSum-candidate < minPossibleSum || remainingSum-candidate > maxPossibleSum {
-						otherCell.RemoveCandidate(candidate)
-					}
-				}
+	// Second: Keep only candidates that can still complete the sum
+	kc.restrictToSumCombinations(kc.Board)
+}
+
+// sumCombinationDigits returns the empty cage cells and the digits (bit d for digit d)
+// that appear in at least one combination of distinct digits able to fill them with the
+// remaining sum. Digits already placed in the cage are excluded.
+func (kc *KillerCageConstraint) sumCombinationDigits(board *lib.Board) (empty []*lib.Cell, possible uint16) {
+	sum := 0
+	placed := make(map[int]bool)
+	empty = make([]*lib.Cell, 0, len(kc.Cells))
+	for _, idx := range kc.Cells {
+		cell := board.GetCell(idx)
+		if cell == nil {
+			continue
+		}
+		if cell.IsSolved() {
+			sum += cell.GetValue()
+			placed[cell.GetValue()] = true
+			continue
+		}
+		empty = append(empty, cell)
+	}
+	if len(empty) == 0 {
+		return empty, 0
+	}
+
+	digits := make([]int, 0, 9)
+	for digit := 1; digit <= 9; digit++ {
+		if !placed[digit] {
+			digits = append(digits, digit)
+		}
+	}
+
+	// Collect every digit that is part of at least one combination reaching the sum
+	for _, combo := range utils.GenerateCombinations(len(digits), len(empty)) {
+		total := 0
+		for _, i := range combo {
+			total += digits[i]
+		}
+		if total != kc.targetSum-sum {
+			continue
+		}
+		for _, i := range combo {
+			possible |= 1 << digits[i]
+		}
+	}
+	return empty, possible
+}
+
+// restrictToSumCombinations removes every candidate of an empty cage cell that appears in
+// no combination of distinct digits able to fill the empty cells with the remaining sum.
+// Returns true if any candidates were removed.
+func (kc *KillerCageConstraint) restrictToSumCombinations(board *lib.Board) bool {
+	empty, possible := kc.sumCombinationDigits(board)
+
+	changed := false
+	for _, cell := range empty {
+		for _, candidate := range cell.SortedCandidates() {
+			if possible&(1<<candidate) == 0 {
+				cell.RemoveCandidate(candidate)
+				changed = true
 			}
 		}
 	}
+	return changed
+}
+
+// cellRange returns the smallest and largest digits the cage cell at cellIndex can still
+// hold: its value if it is filled, otherwise its candidates that fit some combination of
+// distinct digits completing the sum with the current fills. ok is false if the cell is
+// not in the cage or no digit fits.
+func (kc *KillerCageConstraint) cellRange(board *lib.Board, cellIndex int) (lower, upper int, ok bool) {
+	if board == nil || !utils.ContainsInt(kc.Cells, cellIndex) {
+		return 0, 0, false
+	}
+	cell := board.GetCell(cellIndex)
+	if cell == nil {
+		return 0, 0, false
+	}
+	if cell.IsSolved() {
+		return cell.GetValue(), cell.GetValue(), true
+	}
+
+	_, possible := kc.sumCombinationDigits(board)
+	for _, candidate := range cell.SortedCandidates() {
+		if possible&(1<<candidate) == 0 {
+			continue
+		}
+		if !ok {
+			lower = candidate
+		}
+		upper, ok = candidate, true
+	}
+	return lower, upper, ok
+}
+
+// MinCellValue returns the smallest digit the cage cell at cellIndex can hold given the
+// sum, the cage's uniqueness and the values already placed, for techniques that compare
+// bounds across constraints. Returns 0 if the cell is not in the cage or nothing fits.
+func (kc *KillerCageConstraint) MinCellValue(board *lib.Board, cellIndex int) int {
+	lower, _, _ := kc.cellRange(board, cellIndex)
+	return lower
+}
+
+// MaxCellValue returns the largest digit the cage cell at cellIndex can hold, like
+// MinCellValue. Returns 0 if the cell is not in the cage or nothing fits.
+func (kc *KillerCageConstraint) MaxCellValue(board *lib.Board, cellIndex int) int {
+	_, upper, _ := kc.cellRange(board, cellIndex)
+	return upper
 }
 
 func (kc *KillerCageConstraint) RequiresUniqueness() bool {
 	return true
 }
 
+// ImpactScore adds the candidates ruled out by the remaining sum to the uniqueness estimate
+func (kc *KillerCageConstraint) ImpactScore(board *lib.Board) int {
+	if board == nil {
+		return 0
+	}
+	score := lib.UniquenessImpact(board, kc.Cells)
+
+	sum, empty := 0, 0
+	for _, idx := range kc.Cells {
+		if val := board.Get(idx/9, idx%9); val != 0 {
+			sum += val
+		} else {
+			empty++
+		}
+	}
+	if empty == 0 {
+		return score
+	}
+
+	remaining := kc.targetSum - sum
+	for _, idx := range kc.Cells {
+		cell := board.GetCell(idx)
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		for _, candidate := range cell.SortedCandidates() {
+			// The other empty cells must make up the rest with values 1-9
+			rest := remaining - candidate
+			if rest < empty-1 || rest > (empty-1)*9 {
+				score++
+			}
+		}
+	}
+
+	return score
+}
+
+// RequiredDigits returns the digits, not yet placed, that appear in every combination
+// of distinct digits that could still complete the cage sum. A combination is only
+// kept if each of its digits is a candidate somewhere in the empty part of the cage.
+func (kc *KillerCageConstraint) RequiredDigits(board *lib.Board) []int {
+	if board == nil {
+		return nil
+	}
+
+	sum := 0
+	placed := make(map[int]bool)
+	available := make(map[int]bool)
+	empty := 0
+	for _, idx := range kc.Cells {
+		cell := board.GetCell(idx)
+		if cell == nil {
+			continue
+		}
+		if cell.IsSolved() {
+			sum += cell.GetValue()
+			placed[cell.GetValue()] = true
+			continue
+		}
+		empty++
+		for _, candidate := range cell.SortedCandidates() {
+			available[candidate] = true
+		}
+	}
+	if empty == 0 {
+		return nil
+	}
+
+	digits := make([]int, 0, 9)
+	for digit := 1; digit <= 9; digit++ {
+		if available[digit] && !placed[digit] {
+			digits = append(digits, digit)
+		}
+	}
+
+	// Count how many feasible combinations each digit appears in
+	counts := make(map[int]int)
+	combos := 0
+	for _, combo := range utils.GenerateCombinations(len(digits), empty) {
+		total := 0
+		for _, i := range combo {
+			total += digits[i]
+		}
+		if total != kc.targetSum-sum {
+			continue
+		}
+		combos++
+		for _, i := range combo {
+			counts[digits[i]]++
+		}
+	}
+	if combos == 0 {
+		return nil
+	}
+
+	required := make([]int, 0)
+	for _, digit := range digits {
+		if counts[digit] == combos {
+			required = append(required, digit)
+		}
+	}
+	return required
+}
+
 func (kc *KillerCageConstraint) ApplyPencilMarkConstraints(board *lib.Board) bool {
-	// Apply both naked and hidden subset techniques
+	// Restrict to the sum combinations first; a cage with no values set yet
+	// has had nothing propagated. Then apply naked and hidden subset techniques
 	// Use smaller max size for killer cages since they're often smaller than 9 cells
 	maxSize := 4
 	if len(kc.Cells) < maxSize {
 		maxSize = len(kc.Cells)
 	}
 
-	changed := false
+	changed := kc.restrictToSumCombinations(board)
 	changed = lib.ApplyNakedSubsets(board, kc.Cells, maxSize) || changed
 	changed = lib.ApplyHiddenSubsets(board, kc.Cells, maxSize) || changed
 	return changed
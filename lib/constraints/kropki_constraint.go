@@ -0,0 +1,159 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// KropkiKind is the colour of a Kropki dot
+type KropkiKind int
+
+const (
+	// White dots join consecutive values
+	White KropkiKind = iota
+	// Black dots join values where one is double the other
+	Black
+)
+
+func (k KropkiKind) String() string {
+	if k == Black {
+		return "black"
+	}
+	return "white"
+}
+
+// KropkiConstraint is a dot between two adjacent cells
+type KropkiConstraint struct {
+	lib.BaseConstraint
+	kind KropkiKind
+}
+
+func NewKropkiConstraint(cellA, cellB int, kind KropkiKind) (*KropkiConstraint, error) {
+	for _, cell := range []int{cellA, cellB} {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+	}
+
+	rowA, colA, rowB, colB := cellA/9, cellA%9, cellB/9, cellB%9
+	sameRow := rowA == rowB && (colA-colB == 1 || colB-colA == 1)
+	sameCol := colA == colB && (rowA-rowB == 1 || rowB-rowA == 1)
+	if !sameRow && !sameCol {
+		return nil, fmt.Errorf("kropki cells %d and %d are not orthogonally adjacent", cellA, cellB)
+	}
+
+	if kind != White && kind != Black {
+		return nil, fmt.Errorf("invalid kropki kind: %d", kind)
+	}
+
+	return &KropkiConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: []int{cellA, cellB},
+			Name:  "Kropki",
+		},
+		kind: kind,
+	}, nil
+}
+
+// Kind returns the colour of the dot
+func (kc *KropkiConstraint) Kind() KropkiKind {
+	return kc.kind
+}
+
+// compatible reports whether two values may sit either side of the dot
+func (kc *KropkiConstraint) compatible(a, b int) bool {
+	if kc.kind == Black {
+		return a == 2*b || b == 2*a
+	}
+	return a-b == 1 || b-a == 1
+}
+
+func (kc *KropkiConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	valA := board.Get(kc.Cells[0]/9, kc.Cells[0]%9)
+	valB := board.Get(kc.Cells[1]/9, kc.Cells[1]%9)
+
+	// Skip if either cell is empty
+	if valA == 0 || valB == 0 {
+		return true, nil
+	}
+
+	return kc.compatible(valA, valB), nil
+}
+
+// Violations reports the pair when both values are set and don't match the dot
+func (kc *KropkiConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	if valid, err := kc.IsValid(board); err != nil || valid {
+		return nil
+	}
+
+	valA := board.Get(kc.Cells[0]/9, kc.Cells[0]%9)
+	valB := board.Get(kc.Cells[1]/9, kc.Cells[1]%9)
+
+	relation := "consecutive"
+	if kc.kind == Black {
+		relation = "in a 1:2 ratio"
+	}
+
+	return []lib.ConstraintViolation{{
+		ConstraintName: kc.GetName(),
+		Cells:          []int{kc.Cells[0], kc.Cells[1]},
+		Message:        fmt.Sprintf("values %d and %d on a %s dot are not %s", valA, valB, kc.kind, relation),
+	}}
+}
+
+func (kc *KropkiConstraint) GetDescription() string {
+	if kc.kind == Black {
+		return fmt.Sprintf("Black Kropki dot between cells %d and %d - one value must be double the other",
+			kc.Cells[0], kc.Cells[1])
+	}
+	return fmt.Sprintf("White Kropki dot between cells %d and %d - values must be consecutive",
+		kc.Cells[0], kc.Cells[1])
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (kc *KropkiConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeKropki, kc.Cells, kropkiParams{Kind: kc.kind.String()})
+}
+
+// PropagateValueChange keeps only the partner candidates that fit the dot with the new value.
+// A black 1 leaves {2}, a black 2 leaves {1, 4}, and black 5, 7 and 9 leave nothing.
+// This is called automatically via the observer pattern when a cell is solved
+func (kc *KropkiConstraint) PropagateValueChange(row, col, value int) {
+	if value == 0 {
+		return // No value set, nothing to propagate
+	}
+
+	// Get the board from the base constraint
+	if kc.Board == nil {
+		return
+	}
+
+	cellIndex := row*9 + col
+	partnerIndex := kc.Cells[0]
+	if partnerIndex == cellIndex {
+		partnerIndex = kc.Cells[1]
+	} else if kc.Cells[1] != cellIndex {
+		return // Cell not in this constraint
+	}
+
+	partner := kc.Board.GetCell(partnerIndex)
+	if partner == nil || partner.IsSolved() {
+		return
+	}
+
+	for candidate := 1; candidate <= 9; candidate++ {
+		if !kc.compatible(value, candidate) {
+			partner.RemoveCandidate(candidate)
+		}
+	}
+}
+
+func (kc *KropkiConstraint) RequiresUniqueness() bool {
+	// The dot relates two cells; uniqueness comes from the rows and columns they share
+	return false
+}
@@ -0,0 +1,170 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// LittleKillerConstraint requires the cells along a diagonal to sum to a clue. The
+// diagonal crosses several rows and boxes, so digits may repeat.
+type LittleKillerConstraint struct {
+	lib.BaseConstraint
+	sum int
+}
+
+// NewLittleKillerConstraint creates a little killer clue for cells given in order along
+// one diagonal direction. The sum must be reachable: between 1 and 9 per cell.
+func NewLittleKillerConstraint(cells []int, sum int) (*LittleKillerConstraint, error) {
+	if len(cells) == 0 {
+		return nil, fmt.Errorf("little killer must have at least one cell")
+	}
+
+	for _, cell := range cells {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+	}
+
+	if !isDiagonal(cells) {
+		return nil, fmt.Errorf("little killer cells must follow one diagonal in order")
+	}
+
+	if sum < len(cells) || sum > 9*len(cells) {
+		return nil, fmt.Errorf("little killer sum %d is not reachable with %d cells (%d-%d)",
+			sum, len(cells), len(cells), 9*len(cells))
+	}
+
+	return &LittleKillerConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: cells,
+			Name:  fmt.Sprintf("Little Killer (%d)", sum),
+		},
+		sum: sum,
+	}, nil
+}
+
+// isDiagonal reports whether each cell is the diagonal neighbour of the one before it,
+// always in the same direction
+func isDiagonal(cells []int) bool {
+	if len(cells) < 2 {
+		return true
+	}
+
+	dRow, dCol := cells[1]/9-cells[0]/9, cells[1]%9-cells[0]%9
+	if (dRow != 1 && dRow != -1) || (dCol != 1 && dCol != -1) {
+		return false
+	}
+	for i := 1; i < len(cells); i++ {
+		if cells[i]/9-cells[i-1]/9 != dRow || cells[i]%9-cells[i-1]%9 != dCol {
+			return false
+		}
+	}
+	return true
+}
+
+// total returns the sum of the filled cells and the number of empty ones
+func (lk *LittleKillerConstraint) total(board *lib.Board) (sum, empty int) {
+	for _, cellIdx := range lk.Cells {
+		val := board.Get(cellIdx/9, cellIdx%9)
+		if val == 0 {
+			empty++
+		}
+		sum += val
+	}
+	return sum, empty
+}
+
+func (lk *LittleKillerConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	sum, empty := lk.total(board)
+	if empty == 0 {
+		return sum == lk.sum, nil
+	}
+
+	// The empty cells still add between 1 and 9 each
+	return sum+empty <= lk.sum && sum+9*empty >= lk.sum, nil
+}
+
+// Violations reports the filled cells when the clue can no longer be reached, with their
+// current sum as the value
+func (lk *LittleKillerConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	if valid, err := lk.IsValid(board); err != nil || valid {
+		return nil
+	}
+
+	sum, _ := lk.total(board)
+	filled := make([]int, 0, len(lk.Cells))
+	for _, cellIdx := range lk.Cells {
+		if board.Get(cellIdx/9, cellIdx%9) != 0 {
+			filled = append(filled, cellIdx)
+		}
+	}
+
+	return []lib.ConstraintViolation{{
+		ConstraintName: lk.GetName(),
+		Cells:          filled,
+		Value:          sum,
+		Message:        fmt.Sprintf("diagonal sum is %d, clue is %d", sum, lk.sum),
+	}}
+}
+
+func (lk *LittleKillerConstraint) GetDescription() string {
+	return fmt.Sprintf("Little killer with %d cells - values along the diagonal must sum to %d", len(lk.Cells), lk.sum)
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (lk *LittleKillerConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeLittleKiller, lk.Cells, littleKillerParams{Sum: lk.sum})
+}
+
+// Initialize bounds the candidates by the clue before any value is placed
+func (lk *LittleKillerConstraint) Initialize(board *lib.Board) {
+	lk.restrict(board)
+}
+
+// PropagateValueChange tightens the bounds of the empty cells to what the clue still allows
+// This is called automatically via the observer pattern when a cell is solved
+func (lk *LittleKillerConstraint) PropagateValueChange(row, col, value int) {
+	if value == 0 {
+		return // No value set, nothing to propagate
+	}
+
+	// Get the board from the base constraint
+	if lk.Board == nil {
+		return
+	}
+
+	lk.restrict(lk.Board)
+}
+
+// restrict removes candidates that would leave the other empty cells unable to make up
+// the rest of the clue, each of them adding between 1 and 9
+func (lk *LittleKillerConstraint) restrict(board *lib.Board) {
+	sum, empty := lk.total(board)
+	if empty == 0 {
+		return
+	}
+
+	remaining := lk.sum - sum
+	lower, upper := remaining-9*(empty-1), remaining-(empty-1)
+	for _, cellIdx := range lk.Cells {
+		cell := board.GetCell(cellIdx)
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		for candidate := 1; candidate <= 9; candidate++ {
+			if candidate < lower || candidate > upper {
+				cell.RemoveCandidate(candidate)
+			}
+		}
+	}
+}
+
+func (lk *LittleKillerConstraint) RequiresUniqueness() bool {
+	// Digits may repeat along the diagonal
+	return false
+}
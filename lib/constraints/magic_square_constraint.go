@@ -0,0 +1,199 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// MagicSum is the sum of every row, column and diagonal of a 3x3 magic square using 1-9
+const MagicSum = 15
+
+// MagicSquareConstraint turns a 3x3 block into a magic square: the digits 1-9 appear
+// once each, and every row, column and both diagonals of the block sum to 15
+type MagicSquareConstraint struct {
+	lib.BaseConstraint
+	lines [8][3]int // Rows, columns, then the two diagonals
+}
+
+// NewMagicSquareConstraint creates a magic square on the 3x3 block whose top left cell is
+// topLeft. The block does not have to be a box, but it must fit on the board.
+func NewMagicSquareConstraint(topLeft int) (*MagicSquareConstraint, error) {
+	if topLeft < 0 || topLeft > 80 {
+		return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", topLeft)
+	}
+
+	row, col := topLeft/9, topLeft%9
+	if row > 6 || col > 6 {
+		return nil, fmt.Errorf("magic square at R%dC%d does not fit on the board", row+1, col+1)
+	}
+
+	cells := make([]int, 0, 9)
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			cells = append(cells, (row+r)*9+(col+c))
+		}
+	}
+
+	var lines [8][3]int
+	for i := 0; i < 3; i++ {
+		lines[i] = [3]int{cells[i*3], cells[i*3+1], cells[i*3+2]}
+		lines[3+i] = [3]int{cells[i], cells[3+i], cells[6+i]}
+	}
+	lines[6] = [3]int{cells[0], cells[4], cells[8]}
+	lines[7] = [3]int{cells[2], cells[4], cells[6]}
+
+	return &MagicSquareConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: cells,
+			Name:  fmt.Sprintf("Magic Square R%dC%d", row+1, col+1),
+		},
+		lines: lines,
+	}, nil
+}
+
+// lineTotal returns the sum of the filled cells of a line and the number of empty ones
+func lineTotal(board *lib.Board, line [3]int) (sum, empty int) {
+	for _, cellIdx := range line {
+		val := board.Get(cellIdx/9, cellIdx%9)
+		if val == 0 {
+			empty++
+		}
+		sum += val
+	}
+	return sum, empty
+}
+
+// lineValid checks a complete line sums to 15, and a partial one leaves at least 1 for
+// each empty cell
+func lineValid(sum, empty int) bool {
+	if empty == 0 {
+		return sum == MagicSum
+	}
+	return sum+empty <= MagicSum
+}
+
+func (ms *MagicSquareConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	values := make([]int, len(ms.Cells))
+	for i, cellIdx := range ms.Cells {
+		values[i] = board.Get(cellIdx/9, cellIdx%9)
+	}
+	if !lib.HasUniqueNonZeros(values) {
+		return false, nil
+	}
+
+	for _, line := range ms.lines {
+		if !lineValid(lineTotal(board, line)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Violations reports duplicated values in the square and, for each broken line, its
+// filled cells with their current sum as the value
+func (ms *MagicSquareConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	violations := lib.DuplicateViolations(board, ms.GetName(), ms.Cells)
+
+	for _, line := range ms.lines {
+		sum, empty := lineTotal(board, line)
+		if lineValid(sum, empty) {
+			continue
+		}
+
+		filled := make([]int, 0, len(line))
+		for _, cellIdx := range line {
+			if board.Get(cellIdx/9, cellIdx%9) != 0 {
+				filled = append(filled, cellIdx)
+			}
+		}
+		violations = append(violations, lib.ConstraintViolation{
+			ConstraintName: ms.GetName(),
+			Cells:          filled,
+			Value:          sum,
+			Message:        fmt.Sprintf("line sum is %d, must be %d", sum, MagicSum),
+		})
+	}
+
+	return violations
+}
+
+func (ms *MagicSquareConstraint) GetDescription() string {
+	return fmt.Sprintf("Magic square - rows, columns and diagonals of the 3x3 block must sum to %d", MagicSum)
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (ms *MagicSquareConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeMagicSquare, ms.Cells, nil)
+}
+
+// Initialize applies the layout every 1-9 magic square shares: 5 in the center, even
+// digits in the corners and odd digits on the edges
+func (ms *MagicSquareConstraint) Initialize(board *lib.Board) {
+	for i, cellIdx := range ms.Cells {
+		cell := board.GetCell(cellIdx)
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		for candidate := 1; candidate <= 9; candidate++ {
+			switch {
+			case i == 4 && candidate != 5:
+				cell.RemoveCandidate(candidate)
+			case i != 4 && i%2 == 0 && candidate%2 != 0:
+				cell.RemoveCandidate(candidate)
+			case i%2 == 1 && (candidate%2 == 0 || candidate == 5):
+				cell.RemoveCandidate(candidate)
+			}
+		}
+	}
+}
+
+// PropagateValueChange removes the placed value from the rest of the square and fills in
+// the last cell of any line that has only one empty cell left
+// This is called automatically via the observer pattern when a cell is solved
+func (ms *MagicSquareConstraint) PropagateValueChange(row, col, value int) {
+	if value == 0 {
+		return // No value set, nothing to propagate
+	}
+
+	// Get the board from the base constraint
+	if ms.Board == nil {
+		return
+	}
+
+	cellIndex := row*9 + col
+	for _, otherIndex := range ms.Cells {
+		if otherIndex == cellIndex {
+			continue
+		}
+		if otherCell := ms.Board.GetCell(otherIndex); otherCell != nil && !otherCell.IsSolved() {
+			otherCell.RemoveCandidate(value)
+		}
+	}
+
+	for _, line := range ms.lines {
+		sum, empty := lineTotal(ms.Board, line)
+		if empty != 1 {
+			continue
+		}
+		for _, cellIdx := range line {
+			cell := ms.Board.GetCell(cellIdx)
+			if cell == nil || cell.IsSolved() {
+				continue
+			}
+			for _, candidate := range cell.SortedCandidates() {
+				if candidate != MagicSum-sum {
+					cell.RemoveCandidate(candidate)
+				}
+			}
+		}
+	}
+}
+
+func (ms *MagicSquareConstraint) RequiresUniqueness() bool {
+	return true
+}
@@ -0,0 +1,143 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// NonConsecutiveConstraint is the non-consecutive variant rule: no two orthogonally
+// adjacent cells anywhere on the board may hold consecutive digits. It covers the whole
+// board, so it observes every cell.
+type NonConsecutiveConstraint struct {
+	lib.BaseConstraint
+}
+
+// NewNonConsecutiveConstraint creates the board-wide non-consecutive rule
+func NewNonConsecutiveConstraint() *NonConsecutiveConstraint {
+	cells := make([]int, 81)
+	for i := range cells {
+		cells[i] = i
+	}
+
+	return &NonConsecutiveConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: cells,
+			Name:  "Non-Consecutive",
+		},
+	}
+}
+
+// orthogonalNeighbors returns the indices of the cells directly above, below, left and
+// right of a cell, within the 9x9 grid
+func orthogonalNeighbors(cellIndex int) []int {
+	row, col := cellIndex/9, cellIndex%9
+	neighbors := make([]int, 0, 4)
+	if row > 0 {
+		neighbors = append(neighbors, cellIndex-9)
+	}
+	if row < 8 {
+		neighbors = append(neighbors, cellIndex+9)
+	}
+	if col > 0 {
+		neighbors = append(neighbors, cellIndex-1)
+	}
+	if col < 8 {
+		neighbors = append(neighbors, cellIndex+1)
+	}
+	return neighbors
+}
+
+// consecutive reports whether two filled values differ by exactly one
+func consecutive(a, b int) bool {
+	return a != 0 && b != 0 && (a-b == 1 || b-a == 1)
+}
+
+func (nc *NonConsecutiveConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	return len(nc.Violations(board)) == 0, nil
+}
+
+// Violations reports every adjacent pair holding consecutive values. Each pair is listed
+// once, from the cell to the right or below.
+func (nc *NonConsecutiveConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	violations := make([]lib.ConstraintViolation, 0)
+	if board == nil {
+		return violations
+	}
+
+	for cellIdx := 0; cellIdx < 81; cellIdx++ {
+		val := board.Get(cellIdx/9, cellIdx%9)
+		if val == 0 {
+			continue
+		}
+		for _, neighbor := range orthogonalNeighbors(cellIdx) {
+			if neighbor < cellIdx {
+				continue // Checked from the other cell
+			}
+			if other := board.Get(neighbor/9, neighbor%9); consecutive(val, other) {
+				violations = append(violations, lib.ConstraintViolation{
+					ConstraintName: nc.GetName(),
+					Cells:          []int{cellIdx, neighbor},
+					Value:          other,
+					Message:        fmt.Sprintf("adjacent cells %d and %d hold consecutive values %d and %d", cellIdx, neighbor, val, other),
+				})
+			}
+		}
+	}
+
+	return violations
+}
+
+func (nc *NonConsecutiveConstraint) GetDescription() string {
+	return "Non-consecutive - orthogonally adjacent cells must not hold consecutive digits"
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (nc *NonConsecutiveConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeNonConsecutive, nc.Cells, nil)
+}
+
+// Initialize applies the values already on the board when the rule is added
+func (nc *NonConsecutiveConstraint) Initialize(board *lib.Board) {
+	for cellIdx := 0; cellIdx < 81; cellIdx++ {
+		if value := board.Get(cellIdx/9, cellIdx%9); value != 0 {
+			nc.restrict(board, cellIdx, value)
+		}
+	}
+}
+
+// PropagateValueChange removes value-1 and value+1 from the orthogonal neighbors
+// This is called automatically via the observer pattern when a cell is solved
+func (nc *NonConsecutiveConstraint) PropagateValueChange(row, col, value int) {
+	if value == 0 {
+		return // No value set, nothing to propagate
+	}
+
+	// Get the board from the base constraint
+	if nc.Board == nil {
+		return
+	}
+
+	nc.restrict(nc.Board, row*9+col, value)
+}
+
+// restrict removes the digits consecutive to value from the neighbors of cellIndex
+func (nc *NonConsecutiveConstraint) restrict(board *lib.Board, cellIndex, value int) {
+	for _, neighbor := range orthogonalNeighbors(cellIndex) {
+		cell := board.GetCell(neighbor)
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		cell.RemoveCandidate(value - 1)
+		cell.RemoveCandidate(value + 1)
+	}
+}
+
+func (nc *NonConsecutiveConstraint) RequiresUniqueness() bool {
+	// Adjacent cells may not be consecutive, but may repeat unless a row or column forbids it
+	return false
+}
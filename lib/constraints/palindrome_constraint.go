@@ -0,0 +1,116 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// PalindromeConstraint requires a line to read the same forwards and backwards: the cell
+// at position i holds the same value as the cell at position len-1-i. On an odd-length
+// line the center cell mirrors itself and is unrestricted.
+type PalindromeConstraint struct {
+	lib.BaseConstraint
+}
+
+func NewPalindromeConstraint(cells []int) (*PalindromeConstraint, error) {
+	if len(cells) < 2 {
+		return nil, fmt.Errorf("palindrome must have at least two cells")
+	}
+
+	for _, cell := range cells {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+	}
+
+	return &PalindromeConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: cells,
+			Name:  "Palindrome",
+		},
+	}, nil
+}
+
+// mismatches returns the mirrored pairs of filled cells holding different values
+func (pc *PalindromeConstraint) mismatches(board *lib.Board) [][2]int {
+	pairs := make([][2]int, 0)
+	cells := pc.GetCells()
+	for i := 0; i < len(cells)/2; i++ {
+		a, b := cells[i], cells[len(cells)-1-i]
+		valA, valB := board.Get(a/9, a%9), board.Get(b/9, b%9)
+		if valA != 0 && valB != 0 && valA != valB {
+			pairs = append(pairs, [2]int{a, b})
+		}
+	}
+	return pairs
+}
+
+func (pc *PalindromeConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	return len(pc.mismatches(board)) == 0, nil
+}
+
+// Violations reports each mirrored pair holding different values
+func (pc *PalindromeConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	violations := make([]lib.ConstraintViolation, 0)
+	for _, pair := range pc.mismatches(board) {
+		valA, valB := board.Get(pair[0]/9, pair[0]%9), board.Get(pair[1]/9, pair[1]%9)
+		violations = append(violations, lib.ConstraintViolation{
+			ConstraintName: pc.GetName(),
+			Cells:          []int{pair[0], pair[1]},
+			Value:          valA,
+			Message:        fmt.Sprintf("mirrored cells hold %d and %d", valA, valB),
+		})
+	}
+	return violations
+}
+
+func (pc *PalindromeConstraint) GetDescription() string {
+	return fmt.Sprintf("Palindrome with %d cells - values read the same in both directions", len(pc.GetCells()))
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (pc *PalindromeConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypePalindrome, pc.Cells, nil)
+}
+
+// PropagateValueChange restricts the mirrored partner of the solved cell to the same value.
+// A partner already holding a different value is left to IsValid.
+// This is called automatically via the observer pattern when a cell is solved
+func (pc *PalindromeConstraint) PropagateValueChange(row, col, value int) {
+	if value == 0 {
+		return // No value set, nothing to propagate
+	}
+
+	// Get the board from the base constraint
+	if pc.Board == nil {
+		return
+	}
+
+	cells := pc.GetCells()
+	cellIndex := row*9 + col
+	for pos, idx := range cells {
+		if idx != cellIndex {
+			continue
+		}
+
+		partner := pc.Board.GetCell(cells[len(cells)-1-pos])
+		if partner == nil || partner.GetIndex() == cellIndex || partner.IsSolved() {
+			continue // The center of an odd line mirrors itself
+		}
+		for candidate := 1; candidate <= 9; candidate++ {
+			if candidate != value {
+				partner.RemoveCandidate(candidate)
+			}
+		}
+	}
+}
+
+func (pc *PalindromeConstraint) RequiresUniqueness() bool {
+	// Mirrored cells repeat values by design
+	return false
+}
@@ -0,0 +1,97 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// ParityCountConstraint requires a digit to appear an even (or odd) number of times
+// within a region. It is only checked once every cell in the region is filled and does
+// no propagation, so it never removes candidates on its own.
+type ParityCountConstraint struct {
+	lib.BaseConstraint
+	digit    int
+	wantEven bool
+}
+
+func NewParityCountConstraint(cells []int, digit int, wantEven bool) (*ParityCountConstraint, error) {
+	if len(cells) == 0 {
+		return nil, fmt.Errorf("parity count region must have at least one cell")
+	}
+
+	for _, cell := range cells {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+	}
+
+	if digit < 1 || digit > 9 {
+		return nil, fmt.Errorf("digit must be between 1 and 9, got %d", digit)
+	}
+
+	return &ParityCountConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: cells,
+			Name:  fmt.Sprintf("Parity Count (%d)", digit),
+		},
+		digit:    digit,
+		wantEven: wantEven,
+	}, nil
+}
+
+func (pc *ParityCountConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	count := 0
+	for _, cellIdx := range pc.Cells {
+		val := board.Get(cellIdx/9, cellIdx%9)
+		if val == 0 {
+			return true, nil // Only checked once the region is complete
+		}
+		if val == pc.digit {
+			count++
+		}
+	}
+
+	return (count%2 == 0) == pc.wantEven, nil
+}
+
+// Violations reports the cells holding the digit when the count has the wrong parity
+func (pc *ParityCountConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	if valid, err := pc.IsValid(board); err != nil || valid {
+		return nil
+	}
+
+	holding := make([]int, 0)
+	for _, cellIdx := range pc.Cells {
+		if board.Get(cellIdx/9, cellIdx%9) == pc.digit {
+			holding = append(holding, cellIdx)
+		}
+	}
+
+	return []lib.ConstraintViolation{{
+		ConstraintName: pc.GetName(),
+		Cells:          holding,
+		Value:          pc.digit,
+		Message:        fmt.Sprintf("%d appears %d times, expected an %s count", pc.digit, len(holding), pc.parity()),
+	}}
+}
+
+func (pc *ParityCountConstraint) GetDescription() string {
+	return fmt.Sprintf("Region with %d cells - %d must appear an %s number of times", len(pc.GetCells()), pc.digit, pc.parity())
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (pc *ParityCountConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeParityCount, pc.Cells, parityCountParams{Digit: pc.digit, Even: pc.wantEven})
+}
+
+func (pc *ParityCountConstraint) parity() string {
+	if pc.wantEven {
+		return "even"
+	}
+	return "odd"
+}
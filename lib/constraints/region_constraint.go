@@ -0,0 +1,54 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// RegionConstraint is an arbitrary group of cells whose values must be unique, like a
+// row, column or box: extra regions such as windoku windows, or the irregular regions
+// of a jigsaw sudoku. A region of 9 cells is a full unit and takes part in hidden
+// singles and the other unit-based techniques. The uniqueness rules come from
+// AllDifferentConstraint.
+type RegionConstraint struct {
+	AllDifferentConstraint
+}
+
+// NewRegionConstraint creates a named region of 1 to 9 distinct cells. An empty name
+// defaults to "Region".
+func NewRegionConstraint(name string, cells []int) (*RegionConstraint, error) {
+	if len(cells) == 0 {
+		return nil, fmt.Errorf("region must have at least one cell")
+	}
+
+	if len(cells) > 9 {
+		return nil, fmt.Errorf("region cannot have more than 9 cells, got %d", len(cells))
+	}
+
+	seen := make(map[int]bool, len(cells))
+	for _, cell := range cells {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+		if seen[cell] {
+			return nil, fmt.Errorf("region lists cell %d more than once", cell)
+		}
+		seen[cell] = true
+	}
+
+	if name == "" {
+		name = "Region"
+	}
+
+	return &RegionConstraint{AllDifferentConstraint: newAllDifferent(name, cells)}, nil
+}
+
+func (rc *RegionConstraint) GetDescription() string {
+	return fmt.Sprintf("%s with %d cells - values must be unique", rc.GetName(), len(rc.Cells))
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (rc *RegionConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeRegion, rc.Cells, regionParams{Name: rc.GetName()})
+}
@@ -0,0 +1,280 @@
+package constraints
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// Type names used in saved boards. They are part of the JSON format and must not change.
+const (
+	TypeRow            = "row"
+	TypeColumn         = "column"
+	TypeBox            = "box"
+	TypeKillerCage     = "killer_cage"
+	TypeCageUnique     = "cage_unique"
+	TypeRenban         = "renban"
+	TypeGermanWhispers = "german_whispers"
+	TypeArrow          = "arrow"
+	TypeThermo         = "thermo"
+	TypeSameParity     = "same_parity"
+	TypeParityCount    = "parity_count"
+	TypeJigsawRegion   = "jigsaw_region"
+	TypeKropki         = "kropki"
+	TypeSandwich       = "sandwich"
+	TypeEven           = "even"
+	TypeOdd            = "odd"
+	TypePalindrome     = "palindrome"
+	TypeLittleKiller   = "little_killer"
+	TypeXV             = "xv"
+	TypeMagicSquare    = "magic_square"
+	TypeFrameSum       = "frame_sum"
+	TypeRegion         = "region"
+	TypeInequality     = "inequality"
+	TypeNonConsecutive = "non_consecutive"
+	TypeAllDifferent   = "all_different"
+)
+
+// Parameters of the constraint types that need more than their cells
+type (
+	rowParams struct {
+		Row int `json:"row"`
+	}
+	columnParams struct {
+		Column int `json:"column"`
+	}
+	boxParams struct {
+		Box int `json:"box"`
+	}
+	killerCageParams struct {
+		Sum int `json:"sum"`
+	}
+	thermoParams struct {
+		Slow bool `json:"slow,omitempty"` // Values may repeat; strict when absent
+	}
+	arrowParams struct {
+		Bulb int `json:"bulb"` // Number of leading cells that form the bulb
+	}
+	jigsawParams struct {
+		Region int `json:"region"`
+	}
+	kropkiParams struct {
+		Kind string `json:"kind"` // "white" or "black"
+	}
+	sandwichParams struct {
+		Sum int `json:"sum"`
+	}
+	littleKillerParams struct {
+		Sum int `json:"sum"`
+	}
+	regionParams struct {
+		Name string `json:"name"`
+	}
+	frameSumParams struct {
+		Sum int `json:"sum"`
+	}
+	xvParams struct {
+		Target int `json:"target"` // 5 for V, 10 for X
+	}
+	parityCountParams struct {
+		Digit int  `json:"digit"`
+		Even  bool `json:"even"`
+	}
+)
+
+func init() {
+	lib.RegisterConstraintType(TypeRow, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		var p rowParams
+		if err := decodeParams(spec, &p); err != nil {
+			return nil, err
+		}
+		return NewRowConstraintOfSize(p.Row, unitSize(spec))
+	})
+	lib.RegisterConstraintType(TypeColumn, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		var p columnParams
+		if err := decodeParams(spec, &p); err != nil {
+			return nil, err
+		}
+		return NewColumnConstraintOfSize(p.Column, unitSize(spec))
+	})
+	lib.RegisterConstraintType(TypeBox, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		var p boxParams
+		if err := decodeParams(spec, &p); err != nil {
+			return nil, err
+		}
+		return NewBoxConstraintOfSize(p.Box, unitSize(spec))
+	})
+	lib.RegisterConstraintType(TypeKillerCage, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		var p killerCageParams
+		if err := decodeParams(spec, &p); err != nil {
+			return nil, err
+		}
+		return NewKillerCageConstraint(spec.Cells, p.Sum)
+	})
+	lib.RegisterConstraintType(TypeCageUnique, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		return NewCageUniqueConstraint(spec.Cells)
+	})
+	lib.RegisterConstraintType(TypeRenban, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		return NewRenbanConstraint(spec.Cells)
+	})
+	lib.RegisterConstraintType(TypeGermanWhispers, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		return NewGermanWhispersConstraint(spec.Cells)
+	})
+	lib.RegisterConstraintType(TypeArrow, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		var p arrowParams
+		if err := decodeParams(spec, &p); err != nil {
+			return nil, err
+		}
+		if p.Bulb < 0 || p.Bulb > len(spec.Cells) {
+			return nil, fmt.Errorf("arrow bulb of %d cells does not fit %d cells", p.Bulb, len(spec.Cells))
+		}
+		return NewArrowConstraint(spec.Cells[:p.Bulb], spec.Cells[p.Bulb:])
+	})
+	lib.RegisterConstraintType(TypeThermo, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		// Thermometers saved before slow ones existed have no params and are strict
+		var p thermoParams
+		if len(spec.Params) > 0 {
+			if err := decodeParams(spec, &p); err != nil {
+				return nil, err
+			}
+		}
+		return NewThermoConstraint(spec.Cells, !p.Slow)
+	})
+	lib.RegisterConstraintType(TypeSameParity, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		return NewSameParityConstraint(spec.Cells)
+	})
+	lib.RegisterConstraintType(TypeParityCount, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		var p parityCountParams
+		if err := decodeParams(spec, &p); err != nil {
+			return nil, err
+		}
+		return NewParityCountConstraint(spec.Cells, p.Digit, p.Even)
+	})
+	lib.RegisterConstraintType(TypeJigsawRegion, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		var p jigsawParams
+		if err := decodeParams(spec, &p); err != nil {
+			return nil, err
+		}
+		return newJigsawRegionConstraint(spec.Cells, p.Region)
+	})
+	lib.RegisterConstraintType(TypeKropki, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		var p kropkiParams
+		if err := decodeParams(spec, &p); err != nil {
+			return nil, err
+		}
+		if len(spec.Cells) != 2 {
+			return nil, fmt.Errorf("kropki dot must have two cells, got %d", len(spec.Cells))
+		}
+		var kind KropkiKind
+		switch p.Kind {
+		case White.String():
+			kind = White
+		case Black.String():
+			kind = Black
+		default:
+			return nil, fmt.Errorf("invalid kropki kind %q", p.Kind)
+		}
+		return NewKropkiConstraint(spec.Cells[0], spec.Cells[1], kind)
+	})
+	lib.RegisterConstraintType(TypeSandwich, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		var p sandwichParams
+		if err := decodeParams(spec, &p); err != nil {
+			return nil, err
+		}
+		return NewSandwichConstraint(spec.Cells, p.Sum)
+	})
+	lib.RegisterConstraintType(TypeEven, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		return NewEvenConstraint(spec.Cells)
+	})
+	lib.RegisterConstraintType(TypeOdd, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		return NewOddConstraint(spec.Cells)
+	})
+	lib.RegisterConstraintType(TypePalindrome, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		return NewPalindromeConstraint(spec.Cells)
+	})
+	lib.RegisterConstraintType(TypeLittleKiller, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		var p littleKillerParams
+		if err := decodeParams(spec, &p); err != nil {
+			return nil, err
+		}
+		return NewLittleKillerConstraint(spec.Cells, p.Sum)
+	})
+	lib.RegisterConstraintType(TypeNonConsecutive, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		return NewNonConsecutiveConstraint(), nil
+	})
+	lib.RegisterConstraintType(TypeAllDifferent, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		return NewAllDifferentConstraint(spec.Cells)
+	})
+	lib.RegisterConstraintType(TypeInequality, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		if len(spec.Cells) != 2 {
+			return nil, fmt.Errorf("inequality clue must have two cells, got %d", len(spec.Cells))
+		}
+		return NewInequalityConstraint(spec.Cells[0], spec.Cells[1])
+	})
+	lib.RegisterConstraintType(TypeXV, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		var p xvParams
+		if err := decodeParams(spec, &p); err != nil {
+			return nil, err
+		}
+		if len(spec.Cells) != 2 {
+			return nil, fmt.Errorf("xv clue must have two cells, got %d", len(spec.Cells))
+		}
+		return NewXVConstraint(spec.Cells[0], spec.Cells[1], p.Target)
+	})
+	lib.RegisterConstraintType(TypeMagicSquare, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		if len(spec.Cells) != 9 {
+			return nil, fmt.Errorf("magic square must have nine cells, got %d", len(spec.Cells))
+		}
+		return NewMagicSquareConstraint(spec.Cells[0])
+	})
+	lib.RegisterConstraintType(TypeFrameSum, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		var p frameSumParams
+		if err := decodeParams(spec, &p); err != nil {
+			return nil, err
+		}
+		return NewFrameSumConstraint(spec.Cells, p.Sum, len(spec.Cells))
+	})
+	lib.RegisterConstraintType(TypeRegion, func(spec lib.ConstraintSpec) (lib.Constraint, error) {
+		var p regionParams
+		if err := decodeParams(spec, &p); err != nil {
+			return nil, err
+		}
+		return NewRegionConstraint(p.Name, spec.Cells)
+	})
+}
+
+// unitSize returns the board size a saved row, column or box belongs to: its cell count,
+// or 9 if no cells were saved
+func unitSize(spec lib.ConstraintSpec) int {
+	if len(spec.Cells) == 0 {
+		return lib.DefaultSize
+	}
+	return len(spec.Cells)
+}
+
+// newSpec builds the serialized form of a constraint, encoding params if there are any
+func newSpec(typeName string, cells []int, params any) (lib.ConstraintSpec, error) {
+	spec := lib.ConstraintSpec{Type: typeName, Cells: append([]int{}, cells...)}
+	if params == nil {
+		return spec, nil
+	}
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return lib.ConstraintSpec{}, err
+	}
+	spec.Params = raw
+	return spec, nil
+}
+
+// decodeParams reads a spec's parameters into p
+func decodeParams(spec lib.ConstraintSpec, p any) error {
+	if len(spec.Params) == 0 {
+		return fmt.Errorf("missing params for %s", spec.Type)
+	}
+	if err := json.Unmarshal(spec.Params, p); err != nil {
+		return fmt.Errorf("invalid params for %s: %w", spec.Type, err)
+	}
+	return nil
+}
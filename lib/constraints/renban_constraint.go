@@ -78,10 +78,49 @@ func (rc *RenbanConstraint) IsValid(board *lib.Board) (bool, error) {
 	return true, nil
 }
 
+// Violations reports duplicated values and, once the line is full, a set of values
+// that isn't consecutive
+func (rc *RenbanConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	violations := lib.DuplicateViolations(board, rc.GetName(), rc.Cells)
+	if len(violations) > 0 {
+		return violations
+	}
+
+	minVal, maxVal := 10, 0
+	for _, cellIdx := range rc.Cells {
+		val := board.Get(cellIdx/9, cellIdx%9)
+		if val == 0 {
+			return violations // Partial lines are only checked for repeats
+		}
+		if val < minVal {
+			minVal = val
+		}
+		if val > maxVal {
+			maxVal = val
+		}
+	}
+
+	// Distinct values are consecutive exactly when they span len(cells) digits
+	if maxVal-minVal+1 != len(rc.Cells) {
+		violations = append(violations, lib.ConstraintViolation{
+			ConstraintName: rc.GetName(),
+			Cells:          rc.Cells,
+			Message:        fmt.Sprintf("values %d-%d span %d digits, expected a consecutive run of %d", minVal, maxVal, maxVal-minVal+1, len(rc.Cells)),
+		})
+	}
+
+	return violations
+}
+
 func (rc *RenbanConstraint) GetDescription() string {
 	return fmt.Sprintf("Renban line with %d cells - values must form a consecutive set with no gaps or repeats", len(rc.GetCells()))
 }
 
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (rc *RenbanConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeRenban, rc.Cells, nil)
+}
+
 // PropagateValueChange propagates the value change to other cells in the Renban line
 // This is called automatically via the observer pattern when a cell is solved
 func (rc *RenbanConstraint) PropagateValueChange(row, col, value int) {
@@ -163,6 +202,10 @@ func (rc *RenbanConstraint) RequiresUniqueness() bool {
 	return true
 }
 
+func (rc *RenbanConstraint) ImpactScore(board *lib.Board) int {
+	return lib.UniquenessImpact(board, rc.Cells)
+}
+
 func (rc *RenbanConstraint) ApplyPencilMarkConstraints(board *lib.Board) bool {
 	// Apply both naked and hidden subset techniques
 	// Use smaller max size since renban constraints are often smaller than 9 cells
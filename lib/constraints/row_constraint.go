@@ -9,16 +9,26 @@ import (
 // RowConstraint ensures all values in a row are unique
 type RowConstraint struct {
 	lib.BaseConstraint
-	row int
+	row  int
+	size int
 }
 
+// NewRowConstraint creates the constraint for a row of a classic 9x9 board
 func NewRowConstraint(row int) (*RowConstraint, error) {
-	if row < 0 || row > 8 {
-		return nil, fmt.Errorf("row must be between 0 and 8, got %d", row)
+	return NewRowConstraintOfSize(row, lib.DefaultSize)
+}
+
+// NewRowConstraintOfSize creates the constraint for a row of a size x size board
+func NewRowConstraintOfSize(row, size int) (*RowConstraint, error) {
+	if _, _, err := lib.BoxShape(size); err != nil {
+		return nil, err
+	}
+	if row < 0 || row >= size {
+		return nil, fmt.Errorf("row must be between 0 and %d, got %d", size-1, row)
 	}
 
-	cells := make([]int, 9)
-	for col := 0; col < 9; col++ {
+	cells := make([]int, size)
+	for col := 0; col < size; col++ {
 		cells[col] = row*9 + col
 	}
 
@@ -27,7 +37,8 @@ func NewRowConstraint(row int) (*RowConstraint, error) {
 			Cells: cells,
 			Name:  fmt.Sprintf("Row %d", row+1),
 		},
-		row: row,
+		row:  row,
+		size: size,
 	}, nil
 }
 
@@ -40,8 +51,18 @@ func (rc *RowConstraint) IsValid(board *lib.Board) (bool, error) {
 	return lib.HasUniqueNonZeros(rowData[:]), nil
 }
 
+// Violations reports each duplicated value and the cells holding it
+func (rc *RowConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	return lib.DuplicateViolations(board, rc.GetName(), rc.Cells)
+}
+
 func (rc *RowConstraint) GetDescription() string {
-	return fmt.Sprintf("All values in row %d must be unique (1-9)", rc.row+1)
+	return fmt.Sprintf("All values in row %d must be unique (1-%d)", rc.row+1, rc.size)
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (rc *RowConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeRow, rc.Cells, rowParams{Row: rc.row})
 }
 
 // PropagateValueChange propagates the value change to other cells in the row
@@ -72,6 +93,10 @@ func (rc *RowConstraint) RequiresUniqueness() bool {
 	return true
 }
 
+func (rc *RowConstraint) ImpactScore(board *lib.Board) int {
+	return lib.UniquenessImpact(board, rc.Cells)
+}
+
 func (rc *RowConstraint) ApplyPencilMarkConstraints(board *lib.Board) bool {
 	// Apply both naked and hidden subset techniques up to quads (size 4)
 	changed := false
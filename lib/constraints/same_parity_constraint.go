@@ -0,0 +1,121 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// SameParityConstraint requires all values in a group to share parity, all even or
+// all odd, without fixing which
+type SameParityConstraint struct {
+	lib.BaseConstraint
+}
+
+func NewSameParityConstraint(cells []int) (*SameParityConstraint, error) {
+	if len(cells) < 2 {
+		return nil, fmt.Errorf("same parity group must have at least two cells")
+	}
+
+	for _, cell := range cells {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+	}
+
+	return &SameParityConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: cells,
+			Name:  "Same Parity",
+		},
+	}, nil
+}
+
+func (sp *SameParityConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	even, odd := sp.split(board)
+	return len(even) == 0 || len(odd) == 0, nil
+}
+
+// split returns the filled cells holding even and odd values
+func (sp *SameParityConstraint) split(board *lib.Board) (even, odd []int) {
+	for _, cellIdx := range sp.Cells {
+		val := board.Get(cellIdx/9, cellIdx%9)
+		switch {
+		case val == 0:
+			continue
+		case val%2 == 0:
+			even = append(even, cellIdx)
+		default:
+			odd = append(odd, cellIdx)
+		}
+	}
+	return even, odd
+}
+
+// Violations reports every filled cell of the group once both parities are present
+func (sp *SameParityConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	even, odd := sp.split(board)
+	if len(even) == 0 || len(odd) == 0 {
+		return nil
+	}
+
+	filled := make([]int, 0, len(even)+len(odd))
+	for _, cellIdx := range sp.Cells {
+		if board.Get(cellIdx/9, cellIdx%9) != 0 {
+			filled = append(filled, cellIdx)
+		}
+	}
+
+	return []lib.ConstraintViolation{{
+		ConstraintName: sp.GetName(),
+		Cells:          filled,
+		Message:        fmt.Sprintf("group mixes %d even and %d odd value(s)", len(even), len(odd)),
+	}}
+}
+
+func (sp *SameParityConstraint) GetDescription() string {
+	return fmt.Sprintf("Group with %d cells - values must be all even or all odd", len(sp.GetCells()))
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (sp *SameParityConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeSameParity, sp.Cells, nil)
+}
+
+// PropagateValueChange restricts the other cells of the group to the solved value's parity
+// This is called automatically via the observer pattern when a cell is solved
+func (sp *SameParityConstraint) PropagateValueChange(row, col, value int) {
+	if value == 0 {
+		return // No value set, nothing to propagate
+	}
+
+	// Get the board from the base constraint
+	if sp.Board == nil {
+		return
+	}
+
+	cellIndex := row*9 + col
+	for _, otherIndex := range sp.Cells {
+		if otherIndex == cellIndex {
+			continue
+		}
+		otherCell := sp.Board.GetCell(otherIndex)
+		if otherCell == nil || otherCell.IsSolved() {
+			continue
+		}
+		for candidate := 1; candidate <= 9; candidate++ {
+			if candidate%2 != value%2 {
+				otherCell.RemoveCandidate(candidate)
+			}
+		}
+	}
+}
+
+func (sp *SameParityConstraint) RequiresUniqueness() bool {
+	// Values of the same parity may repeat
+	return false
+}
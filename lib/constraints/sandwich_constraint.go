@@ -0,0 +1,145 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// SandwichConstraint requires the digits between the 1 and the 9 of a row or column
+// to add up to a clue. It only checks the line and does no propagation.
+type SandwichConstraint struct {
+	lib.BaseConstraint
+	sum int
+}
+
+// NewSandwichConstraint creates a sandwich clue for the 9 cells of a row or column,
+// given in order. The sum must be between 0 (1 and 9 adjacent) and 35 (2 through 8).
+func NewSandwichConstraint(cells []int, sum int) (*SandwichConstraint, error) {
+	if len(cells) != 9 {
+		return nil, fmt.Errorf("sandwich must cover the 9 cells of a row or column, got %d", len(cells))
+	}
+
+	for _, cell := range cells {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+	}
+
+	if !isLine(cells) {
+		return nil, fmt.Errorf("sandwich cells must be a full row or column in order")
+	}
+
+	if sum < 0 || sum > 35 {
+		return nil, fmt.Errorf("sandwich sum must be between 0 and 35, got %d", sum)
+	}
+
+	return &SandwichConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: cells,
+			Name:  fmt.Sprintf("Sandwich (%d)", sum),
+		},
+		sum: sum,
+	}, nil
+}
+
+// isLine reports whether cells are a full row or column, in either direction
+func isLine(cells []int) bool {
+	step := cells[1] - cells[0]
+	switch step {
+	case 1, -1:
+		if cells[0]/9 != cells[8]/9 {
+			return false
+		}
+	case 9, -9:
+	default:
+		return false
+	}
+
+	for i := 1; i < len(cells); i++ {
+		if cells[i]-cells[i-1] != step {
+			return false
+		}
+	}
+	return true
+}
+
+// between returns the positions of the 1 and the 9 on the line in order, and the sum
+// and number of empty cells strictly between them. ok is false until both are placed.
+func (sc *SandwichConstraint) between(board *lib.Board) (sum, empty int, cells []int, ok bool) {
+	one, nine := -1, -1
+	for pos, idx := range sc.Cells {
+		switch board.Get(idx/9, idx%9) {
+		case 1:
+			one = pos
+		case 9:
+			nine = pos
+		}
+	}
+	if one == -1 || nine == -1 {
+		return 0, 0, nil, false
+	}
+
+	start, end := one, nine
+	if start > end {
+		start, end = end, start
+	}
+	for _, idx := range sc.Cells[start+1 : end] {
+		val := board.Get(idx/9, idx%9)
+		if val == 0 {
+			empty++
+			continue
+		}
+		sum += val
+		cells = append(cells, idx)
+	}
+	return sum, empty, cells, true
+}
+
+func (sc *SandwichConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	sum, empty, _, ok := sc.between(board)
+	if !ok {
+		return true, nil // Nothing to check until both the 1 and the 9 are placed
+	}
+
+	if empty == 0 {
+		return sum == sc.sum, nil
+	}
+
+	// Each empty cell still adds at least 2
+	return sum+2*empty <= sc.sum, nil
+}
+
+// Violations reports the filled cells between the 1 and the 9 when they cannot reach
+// the clue, with their current sum as the value
+func (sc *SandwichConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	if valid, err := sc.IsValid(board); err != nil || valid {
+		return nil
+	}
+
+	sum, _, cells, _ := sc.between(board)
+	return []lib.ConstraintViolation{{
+		ConstraintName: sc.GetName(),
+		Cells:          cells,
+		Value:          sum,
+		Message:        fmt.Sprintf("sandwich sum is %d, clue is %d", sum, sc.sum),
+	}}
+}
+
+func (sc *SandwichConstraint) GetDescription() string {
+	return fmt.Sprintf("Sandwich clue %d - digits between the 1 and the 9 must sum to %d", sc.sum, sc.sum)
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (sc *SandwichConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeSandwich, sc.Cells, sandwichParams{Sum: sc.sum})
+}
+
+func (sc *SandwichConstraint) RequiresUniqueness() bool {
+	// The row or column constraint of the line enforces uniqueness
+	return false
+}
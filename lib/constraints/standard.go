@@ -0,0 +1,73 @@
+package constraints
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// StandardConstraints builds the 27 constraints of classic sudoku: for each index 0-8
+// the row, column and box, in that order. Errors from the individual constructors are
+// collected and returned together.
+func StandardConstraints() ([]lib.Constraint, error) {
+	return StandardConstraintsOfSize(lib.DefaultSize)
+}
+
+// StandardConstraintsOfSize builds the row, column and box constraints of a size x size
+// board in the same order as StandardConstraints
+func StandardConstraintsOfSize(size int) ([]lib.Constraint, error) {
+	if _, _, err := lib.BoxShape(size); err != nil {
+		return nil, err
+	}
+
+	result := make([]lib.Constraint, 0, 3*size)
+	var errs []error
+
+	for i := 0; i < size; i++ {
+		rc, err := NewRowConstraintOfSize(i, size)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("row %d: %w", i+1, err))
+		} else {
+			result = append(result, rc)
+		}
+
+		cc, err := NewColumnConstraintOfSize(i, size)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("column %d: %w", i+1, err))
+		} else {
+			result = append(result, cc)
+		}
+
+		bc, err := NewBoxConstraintOfSize(i, size)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("box %d: %w", i+1, err))
+		} else {
+			result = append(result, bc)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return result, nil
+}
+
+// AddStandardConstraints builds the standard row, column and box constraints for the
+// board's size and adds them to the board. This lives here rather than on Board since
+// the lib package cannot import its constraint implementations.
+func AddStandardConstraints(board *lib.Board) error {
+	if board == nil {
+		return fmt.Errorf("board cannot be nil")
+	}
+
+	standard, err := StandardConstraintsOfSize(board.Size())
+	if err != nil {
+		return err
+	}
+
+	for _, c := range standard {
+		board.AddConstraint(c)
+	}
+	return nil
+}
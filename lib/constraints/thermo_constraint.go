@@ -0,0 +1,214 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// ThermoConstraint ensures values increase from the bulb along the thermometer: strictly
+// on a normal thermometer, or never decreasing on a slow one
+type ThermoConstraint struct {
+	lib.BaseConstraint
+	strict bool
+}
+
+// NewThermoConstraint creates a thermometer where cells[0] is the bulb. A strict
+// thermometer needs each value greater than the one before and has at most 9 cells; a
+// slow one (strict false) also allows equal neighbours.
+func NewThermoConstraint(cells []int, strict bool) (*ThermoConstraint, error) {
+	if len(cells) < 2 {
+		return nil, fmt.Errorf("thermometer must have at least two cells")
+	}
+
+	if strict && len(cells) > 9 {
+		return nil, fmt.Errorf("thermometer cannot have more than 9 cells, got %d", len(cells))
+	}
+
+	for _, cell := range cells {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+	}
+
+	name := "Thermometer"
+	if !strict {
+		name = "Slow Thermometer"
+	}
+
+	return &ThermoConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: cells,
+			Name:  name,
+		},
+		strict: strict,
+	}, nil
+}
+
+// Strict returns true if values must strictly increase, false for a slow thermometer
+func (tc *ThermoConstraint) Strict() bool {
+	return tc.strict
+}
+
+// step returns the least increase from one cell to the next: 1 when strict, else 0
+func (tc *ThermoConstraint) step() int {
+	if tc.strict {
+		return 1
+	}
+	return 0
+}
+
+// bounds returns the smallest and largest values the cell at pos can hold, leaving room
+// for the cells before and after it
+func (tc *ThermoConstraint) bounds(pos int) (lower, upper int) {
+	return 1 + pos*tc.step(), 9 - (len(tc.Cells)-1-pos)*tc.step()
+}
+
+func (tc *ThermoConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	cells := tc.GetCells()
+	lastPos, lastVal := -1, 0
+
+	for pos, cellIdx := range cells {
+		val := board.Get(cellIdx/9, cellIdx%9)
+		if val == 0 {
+			continue
+		}
+
+		// The value must leave room for the cells before and after it
+		if lower, upper := tc.bounds(pos); val < lower || val > upper {
+			return false, nil
+		}
+
+		// Compare with the previous filled cell, allowing for the empty cells between them.
+		// Strict increase means equal values are never allowed, even with a gap.
+		if lastPos >= 0 && val-lastVal < (pos-lastPos)*tc.step() {
+			return false, nil
+		}
+
+		lastPos, lastVal = pos, val
+	}
+
+	return true, nil
+}
+
+// Violations reports cells whose value leaves no room along the thermometer and
+// pairs of filled cells that don't increase enough
+func (tc *ThermoConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	violations := make([]lib.ConstraintViolation, 0)
+
+	cells := tc.GetCells()
+	length := len(cells)
+	lastPos, lastVal := -1, 0
+
+	for pos, cellIdx := range cells {
+		val := board.Get(cellIdx/9, cellIdx%9)
+		if val == 0 {
+			continue
+		}
+
+		if lower, upper := tc.bounds(pos); val < lower || val > upper {
+			violations = append(violations, lib.ConstraintViolation{
+				ConstraintName: tc.GetName(),
+				Cells:          []int{cellIdx},
+				Value:          val,
+				Message:        fmt.Sprintf("%d cannot be at position %d of a %d-cell thermometer", val, pos+1, length),
+			})
+		}
+
+		if lastPos >= 0 && val-lastVal < (pos-lastPos)*tc.step() {
+			violations = append(violations, lib.ConstraintViolation{
+				ConstraintName: tc.GetName(),
+				Cells:          []int{cells[lastPos], cellIdx},
+				Value:          val,
+				Message:        fmt.Sprintf("%d does not increase enough from %d", val, lastVal),
+			})
+		}
+
+		lastPos, lastVal = pos, val
+	}
+
+	return violations
+}
+
+func (tc *ThermoConstraint) GetDescription() string {
+	if !tc.strict {
+		return fmt.Sprintf("Slow thermometer with %d cells - values must not decrease from the bulb", len(tc.GetCells()))
+	}
+	return fmt.Sprintf("Thermometer with %d cells - values must strictly increase from the bulb", len(tc.GetCells()))
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON. Strict
+// thermometers keep the original form without params.
+func (tc *ThermoConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	if tc.strict {
+		return newSpec(TypeThermo, tc.Cells, nil)
+	}
+	return newSpec(TypeThermo, tc.Cells, thermoParams{Slow: true})
+}
+
+// Initialize applies the position bounds as soon as the thermometer is added, before
+// any value is placed
+func (tc *ThermoConstraint) Initialize(board *lib.Board) {
+	tc.restrict(board)
+}
+
+// PropagateValueChange removes candidates that can't keep the thermometer increasing.
+// This is called automatically via the observer pattern when a cell is solved
+func (tc *ThermoConstraint) PropagateValueChange(row, col, value int) {
+	if value == 0 {
+		return // No value set, nothing to propagate
+	}
+
+	// Get the board from the base constraint
+	if tc.Board == nil {
+		return
+	}
+
+	tc.restrict(tc.Board)
+}
+
+// restrict removes candidates outside each cell's bounds. On a strict thermometer a cell
+// at position i must be at least i+1 and at most 9-(len-1-i), and must leave room for the
+// steps to every filled cell before and after it. On a slow one it only has to stay
+// between the filled cells around it.
+func (tc *ThermoConstraint) restrict(board *lib.Board) {
+	cells := tc.GetCells()
+	step := tc.step()
+
+	for pos, cellIdx := range cells {
+		cell := board.GetCell(cellIdx)
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+
+		lower, upper := tc.bounds(pos)
+		for otherPos, otherIdx := range cells {
+			otherVal := board.Get(otherIdx/9, otherIdx%9)
+			if otherVal == 0 {
+				continue
+			}
+			if otherPos < pos && otherVal+(pos-otherPos)*step > lower {
+				lower = otherVal + (pos-otherPos)*step
+			}
+			if otherPos > pos && otherVal-(otherPos-pos)*step < upper {
+				upper = otherVal - (otherPos-pos)*step
+			}
+		}
+
+		for candidate := 1; candidate <= 9; candidate++ {
+			if candidate < lower || candidate > upper {
+				cell.RemoveCandidate(candidate)
+			}
+		}
+	}
+}
+
+func (tc *ThermoConstraint) RequiresUniqueness() bool {
+	// Strictly increasing values are distinct, but the thermometer doesn't act as a uniqueness
+	// region; on a slow thermometer values may even repeat
+	return false
+}
@@ -0,0 +1,152 @@
+package constraints
+
+import (
+	"fmt"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+)
+
+// XV clue values: a V joins two cells summing to 5, an X two cells summing to 10
+const (
+	V = 5
+	X = 10
+)
+
+// XVConstraint is an X or V between two orthogonally adjacent cells
+type XVConstraint struct {
+	lib.BaseConstraint
+	target int
+}
+
+// NewXVConstraint creates an XV clue. target must be V (5) or X (10).
+func NewXVConstraint(cellA, cellB int, target int) (*XVConstraint, error) {
+	for _, cell := range []int{cellA, cellB} {
+		if cell < 0 || cell > 80 {
+			return nil, fmt.Errorf("invalid cell index: %d (must be 0-80)", cell)
+		}
+	}
+
+	rowA, colA, rowB, colB := cellA/9, cellA%9, cellB/9, cellB%9
+	sameRow := rowA == rowB && (colA-colB == 1 || colB-colA == 1)
+	sameCol := colA == colB && (rowA-rowB == 1 || rowB-rowA == 1)
+	if !sameRow && !sameCol {
+		return nil, fmt.Errorf("xv cells %d and %d are not orthogonally adjacent", cellA, cellB)
+	}
+
+	if target != V && target != X {
+		return nil, fmt.Errorf("xv target must be %d (V) or %d (X), got %d", V, X, target)
+	}
+
+	name := "X"
+	if target == V {
+		name = "V"
+	}
+
+	return &XVConstraint{
+		BaseConstraint: lib.BaseConstraint{
+			Cells: []int{cellA, cellB},
+			Name:  name,
+		},
+		target: target,
+	}, nil
+}
+
+// Target returns the sum of the two cells, V (5) or X (10)
+func (xv *XVConstraint) Target() int {
+	return xv.target
+}
+
+func (xv *XVConstraint) IsValid(board *lib.Board) (bool, error) {
+	if board == nil {
+		return false, fmt.Errorf("board cannot be nil")
+	}
+
+	valA := board.Get(xv.Cells[0]/9, xv.Cells[0]%9)
+	valB := board.Get(xv.Cells[1]/9, xv.Cells[1]%9)
+
+	// Skip if either cell is empty
+	if valA == 0 || valB == 0 {
+		return true, nil
+	}
+
+	return valA+valB == xv.target, nil
+}
+
+// Violations reports the pair when both values are set and don't add up to the target
+func (xv *XVConstraint) Violations(board *lib.Board) []lib.ConstraintViolation {
+	if valid, err := xv.IsValid(board); err != nil || valid {
+		return nil
+	}
+
+	valA := board.Get(xv.Cells[0]/9, xv.Cells[0]%9)
+	valB := board.Get(xv.Cells[1]/9, xv.Cells[1]%9)
+
+	return []lib.ConstraintViolation{{
+		ConstraintName: xv.GetName(),
+		Cells:          []int{xv.Cells[0], xv.Cells[1]},
+		Value:          valA + valB,
+		Message:        fmt.Sprintf("values %d and %d on %s sum to %d, not %d", valA, valB, xv.GetName(), valA+valB, xv.target),
+	}}
+}
+
+func (xv *XVConstraint) GetDescription() string {
+	return fmt.Sprintf("%s between cells %d and %d - values must sum to %d",
+		xv.GetName(), xv.Cells[0], xv.Cells[1], xv.target)
+}
+
+// ConstraintSpec returns the serialized form used by Board.MarshalJSON
+func (xv *XVConstraint) ConstraintSpec() (lib.ConstraintSpec, error) {
+	return newSpec(TypeXV, xv.Cells, xvParams{Target: xv.target})
+}
+
+// Initialize removes the values that have no partner digit, 5-9 for a V
+func (xv *XVConstraint) Initialize(board *lib.Board) {
+	for _, cellIdx := range xv.Cells {
+		cell := board.GetCell(cellIdx)
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		for candidate := 1; candidate <= 9; candidate++ {
+			if partner := xv.target - candidate; partner < 1 || partner > 9 {
+				cell.RemoveCandidate(candidate)
+			}
+		}
+	}
+}
+
+// PropagateValueChange leaves the partner only the value that completes the sum
+// This is called automatically via the observer pattern when a cell is solved
+func (xv *XVConstraint) PropagateValueChange(row, col, value int) {
+	if value == 0 {
+		return // No value set, nothing to propagate
+	}
+
+	// Get the board from the base constraint
+	if xv.Board == nil {
+		return
+	}
+
+	cellIndex := row*9 + col
+	partnerIndex := xv.Cells[0]
+	if partnerIndex == cellIndex {
+		partnerIndex = xv.Cells[1]
+	} else if xv.Cells[1] != cellIndex {
+		return // Cell not in this constraint
+	}
+
+	partner := xv.Board.GetCell(partnerIndex)
+	if partner == nil || partner.IsSolved() {
+		return
+	}
+
+	for candidate := 1; candidate <= 9; candidate++ {
+		if candidate != xv.target-value {
+			partner.RemoveCandidate(candidate)
+		}
+	}
+}
+
+func (xv *XVConstraint) RequiresUniqueness() bool {
+	// The clue relates two cells; uniqueness comes from the row or column they share
+	return false
+}
@@ -0,0 +1,238 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/eftil/sudoku-solver.git/lib/utils"
+)
+
+// defaultFinnedFishSizes are the finned fish tried when none have been configured:
+// finned X-Wing, finned Swordfish and finned Jellyfish
+var defaultFinnedFishSizes = []int{2, 3, 4}
+
+// lineCell returns the cell at position pos of a row (rowBased) or column
+func (b *Board) lineCell(line, pos int, rowBased bool) *Cell {
+	if rowBased {
+		return b.GetCellAt(line, pos)
+	}
+	return b.GetCellAt(pos, line)
+}
+
+// lineCells returns the cells of a row (rowBased) or column, in position order
+func (b *Board) lineCells(line int, rowBased bool) []*Cell {
+	if rowBased {
+		return b.RowCells(line)
+	}
+	return b.ColCells(line)
+}
+
+// fishPositions returns, for each row (rowBased) or column, the positions of the
+// unsolved cells that still have the candidate. This is the base set shared by
+// every member of the fish family.
+func (b *Board) fishPositions(candidate int, rowBased bool) [9][]int {
+	var linePositions [9][]int
+	for line := 0; line < 9; line++ {
+		positions := make([]int, 0)
+		for pos, cell := range b.lineCells(line, rowBased) {
+			if cell != nil && !cell.IsSolved() && cell.HasCandidate(candidate) {
+				positions = append(positions, pos)
+			}
+		}
+		linePositions[line] = positions
+	}
+	return linePositions
+}
+
+// fishName returns the conventional name of a fish of the given size
+func fishName(size int) string {
+	switch size {
+	case 2:
+		return "X-Wing"
+	case 3:
+		return "Swordfish"
+	case 4:
+		return "Jellyfish"
+	}
+	return fmt.Sprintf("Fish (%d)", size)
+}
+
+// SetFinnedFishSizes configures which finned fish the advanced techniques try:
+// 2 for finned X-Wing, 3 for finned Swordfish and 4 for finned Jellyfish.
+// Calling it with no sizes disables finned fish.
+func (b *Board) SetFinnedFishSizes(sizes ...int) error {
+	for _, size := range sizes {
+		if size < 2 || size > 4 {
+			return &BoardError{Message: fmt.Sprintf("finned fish size must be between 2 and 4, got %d", size)}
+		}
+	}
+	b.finnedFishSizes = append([]int{}, sizes...)
+	return nil
+}
+
+// FinnedFishSizes returns the finned fish sizes tried by the advanced techniques
+func (b *Board) FinnedFishSizes() []int {
+	if b.finnedFishSizes == nil {
+		return append([]int{}, defaultFinnedFishSizes...)
+	}
+	return append([]int{}, b.finnedFishSizes...)
+}
+
+// applyFinnedFishes tries every configured finned fish size, smallest first
+func (b *Board) applyFinnedFishes() bool {
+	changed := false
+	for _, size := range b.FinnedFishSizes() {
+		if b.applyFinnedFish(size) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// applyFinnedFish implements finned fish of the given size.
+// A fish needs a candidate in size base lines to be confined to size cover lines.
+// In a finned fish some base line positions fall outside the cover lines, but all
+// of these fins sit in a single box. Either a fin holds the candidate, or the plain
+// fish does, so the candidate can only be eliminated from cover line cells outside
+// the base lines that also lie in the fins' box.
+func (b *Board) applyFinnedFish(size int) bool {
+	changed := false
+
+	b.log().Debug("Checking for finned %s in rows...", fishName(size))
+	if b.applyFinnedFishInDirection(size, true) {
+		changed = true
+	}
+
+	b.log().Debug("Checking for finned %s in columns...", fishName(size))
+	if b.applyFinnedFishInDirection(size, false) {
+		changed = true
+	}
+
+	return changed
+}
+
+func (b *Board) applyFinnedFishInDirection(size int, rowBased bool) bool {
+	changed := false
+	name := "Finned " + fishName(size)
+
+	direction := "rows"
+	if !rowBased {
+		direction = "columns"
+	}
+
+	for candidate := 1; candidate <= 9; candidate++ {
+		linePositions := b.fishPositions(candidate, rowBased)
+
+		lines := make([]int, 0)
+		for line, positions := range linePositions {
+			if len(positions) > 0 {
+				lines = append(lines, line)
+			}
+		}
+
+		for _, lineCombo := range utils.GenerateCombinations(len(lines), size) {
+			baseLines := make([]int, size)
+			isBase := make(map[int]bool, size)
+			union := make(map[int]bool)
+			for i, idx := range lineCombo {
+				baseLines[i] = lines[idx]
+				isBase[lines[idx]] = true
+				for _, pos := range linePositions[lines[idx]] {
+					union[pos] = true
+				}
+			}
+
+			// Without extra positions this is a plain fish, handled elsewhere
+			if len(union) <= size {
+				continue
+			}
+
+			unionPositions := make([]int, 0, len(union))
+			for pos := range union {
+				unionPositions = append(unionPositions, pos)
+			}
+			sort.Ints(unionPositions)
+
+			for _, coverCombo := range utils.GenerateCombinations(len(unionPositions), size) {
+				coverPositions := make([]int, size)
+				isCover := make(map[int]bool, size)
+				for i, idx := range coverCombo {
+					coverPositions[i] = unionPositions[idx]
+					isCover[unionPositions[idx]] = true
+				}
+
+				finBox, ok := b.finBox(linePositions, baseLines, isCover, rowBased)
+				if !ok {
+					continue
+				}
+
+				var targets []*Cell
+				for _, pos := range coverPositions {
+					for otherLine := 0; otherLine < 9; otherLine++ {
+						if isBase[otherLine] {
+							continue
+						}
+
+						cell := b.lineCell(otherLine, pos, rowBased)
+						if cell == nil || cell.IsSolved() || !cell.HasCandidate(candidate) {
+							continue
+						}
+						if b.BoxIndex(cell.GetRow(), cell.GetCol()) != finBox {
+							continue
+						}
+						targets = append(targets, cell)
+					}
+				}
+				if len(targets) == 0 {
+					continue
+				}
+
+				b.solvingStep(name, "Found %s for candidate %d in %s %v at positions %v with fins in box %d",
+					name, candidate, direction, oneBased(baseLines), oneBased(coverPositions), finBox+1)
+				removeCandidateFrom(targets, candidate)
+				changed = true
+				b.log().Info("%s eliminated candidate %d from %d cell(s)", name, candidate, len(targets))
+			}
+		}
+	}
+
+	return changed
+}
+
+// finBox checks that every base line has at least one position in the cover set and
+// that all positions outside it (the fins) lie in one box, which it returns
+func (b *Board) finBox(linePositions [9][]int, baseLines []int, isCover map[int]bool, rowBased bool) (int, bool) {
+	box := -1
+	for _, line := range baseLines {
+		covered := false
+		for _, pos := range linePositions[line] {
+			if isCover[pos] {
+				covered = true
+				continue
+			}
+
+			row, col := line, pos
+			if !rowBased {
+				row, col = pos, line
+			}
+			finBox := b.BoxIndex(row, col)
+			if box != -1 && box != finBox {
+				return 0, false
+			}
+			box = finBox
+		}
+		if !covered {
+			return 0, false
+		}
+	}
+	return box, box != -1
+}
+
+// oneBased converts zero-based line or position indices for display
+func oneBased(indices []int) []int {
+	result := make([]int, len(indices))
+	for i, idx := range indices {
+		result[i] = idx + 1
+	}
+	return result
+}
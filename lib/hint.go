@@ -0,0 +1,277 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/eftil/sudoku-solver.git/lib/observer"
+	"github.com/eftil/sudoku-solver.git/lib/utils"
+)
+
+// Elimination is a candidate a technique would remove, found without changing the board
+type Elimination struct {
+	CellIndex int    `json:"cell"` // Index (0-80) of the cell
+	Candidate int    `json:"candidate"`
+	Technique string `json:"technique"`
+	Reason    string `json:"reason"` // Explanation logged by the technique for this pattern
+}
+
+// Hint is the next logical deduction on a board, found without applying it
+type Hint struct {
+	Technique   string              `json:"technique"`
+	Cells       []int               `json:"cells"`      // Indices (0-80) of the cells it changes, ascending
+	Candidates  []int               `json:"candidates"` // The value placed, or the candidates removed, ascending
+	Action      observer.StepAction `json:"action"`     // observer.ActionSolve or observer.ActionEliminate
+	Explanation string              `json:"explanation"`
+
+	// Eliminations pairs each removed candidate with its cell, since a deduction need not
+	// remove every candidate from every cell. Empty for a value to place.
+	Eliminations []Elimination `json:"eliminations,omitempty"`
+}
+
+// dryRun applies a technique to a clone of the board and returns the changes it made as
+// recorded steps, in order. The board itself is never changed and its observers are not
+// notified.
+func (b *Board) dryRun(name string, apply func(*Board) bool) []observer.Step {
+	c := b.clone()
+	recorder := observer.NewSolveRecorder()
+	c.AddObserver(recorder)
+	c.notifyStep(name, "")
+	apply(c)
+	return recorder.GetSteps()
+}
+
+// findEliminations returns the candidates a technique would remove, in the order it
+// removes them, without changing the board
+func (b *Board) findEliminations(name string, apply func(*Board) bool) []Elimination {
+	eliminations := make([]Elimination, 0)
+	for _, step := range b.dryRun(name, apply) {
+		if step.Action != observer.ActionEliminate {
+			continue
+		}
+		for _, idx := range step.Cells {
+			eliminations = append(eliminations, Elimination{
+				CellIndex: idx,
+				Candidate: step.Candidate,
+				Technique: step.Technique,
+				Reason:    step.Reason,
+			})
+		}
+	}
+	return eliminations
+}
+
+// FindXWingEliminations returns what the X-Wing technique would eliminate, without
+// changing the board
+func (b *Board) FindXWingEliminations() []Elimination {
+	return b.findEliminations("X-Wing", (*Board).applyXWings)
+}
+
+// FindSwordfishEliminations returns what the Swordfish technique would eliminate, without
+// changing the board
+func (b *Board) FindSwordfishEliminations() []Elimination {
+	return b.findEliminations("Swordfish", (*Board).applySwordfish)
+}
+
+// FindFinnedFishEliminations returns what finned fish of the configured sizes would
+// eliminate, without changing the board
+func (b *Board) FindFinnedFishEliminations() []Elimination {
+	return b.findEliminations("Finned Fish", (*Board).applyFinnedFishes)
+}
+
+// FindIntersectionEliminations returns what intersection removal (pointing pairs and
+// box-line reduction) would eliminate, without changing the board
+func (b *Board) FindIntersectionEliminations() []Elimination {
+	return b.findEliminations("Intersection Removal", (*Board).ApplyIntersectionRemoval)
+}
+
+// FindColoringEliminations returns what simple coloring would eliminate, without
+// changing the board
+func (b *Board) FindColoringEliminations() []Elimination {
+	return b.findEliminations("Simple Coloring", (*Board).applyColoring)
+}
+
+// FindCageLineEliminations returns what cage line reduction would eliminate, without
+// changing the board
+func (b *Board) FindCageLineEliminations() []Elimination {
+	return b.findEliminations("Cage Line Reduction", (*Board).applyCageLineReductions)
+}
+
+// FindXYWingEliminations returns what the XY-Wing technique would eliminate, without
+// changing the board
+func (b *Board) FindXYWingEliminations() []Elimination {
+	return b.findEliminations("XY-Wing", (*Board).applyXYWings)
+}
+
+// FindWWingEliminations returns what the W-Wing technique would eliminate, without
+// changing the board
+func (b *Board) FindWWingEliminations() []Elimination {
+	return b.findEliminations("W-Wing", (*Board).applyWWings)
+}
+
+// FindXYZWingEliminations returns what the XYZ-Wing technique would eliminate, without
+// changing the board
+func (b *Board) FindXYZWingEliminations() []Elimination {
+	return b.findEliminations("XYZ-Wing", (*Board).applyXYZWings)
+}
+
+// FindUniqueRectangleEliminations returns what the unique rectangle technique would
+// eliminate, without changing the board. Like the technique itself it finds nothing
+// unless SetAssumeUnique(true) was called.
+func (b *Board) FindUniqueRectangleEliminations() []Elimination {
+	if !b.assumeUnique {
+		return []Elimination{}
+	}
+	return b.findEliminations("Unique Rectangle", (*Board).applyUniqueRectangles)
+}
+
+// FindNextEliminations returns the eliminations of the first advanced technique, in the
+// order ApplyAdvancedTechniques tries them, that finds any, for showing the next logical
+// step as a hint. BUG+1 places a value rather than eliminating, so it is not tried.
+// Returns an empty slice if no technique applies.
+func (b *Board) FindNextEliminations() []Elimination {
+	finders := []func() []Elimination{
+		b.FindUniqueRectangleEliminations,
+		b.FindXWingEliminations,
+		b.FindSwordfishEliminations,
+		b.FindFinnedFishEliminations,
+		b.FindIntersectionEliminations,
+		b.FindColoringEliminations,
+		b.FindCageLineEliminations,
+		b.FindXYWingEliminations,
+		b.FindWWingEliminations,
+		b.FindXYZWingEliminations,
+	}
+	for _, find := range finders {
+		if eliminations := find(); len(eliminations) > 0 {
+			return eliminations
+		}
+	}
+	return []Elimination{}
+}
+
+// NextHint returns the next deduction a human would make, without applying it. The
+// techniques are tried in the order of the logical solver, easiest first, so a naked or
+// hidden single is always preferred to an X-Wing. Only the first pattern the technique
+// finds is returned: a value to place, or the candidates one pattern removes. found is
+// false when the board is complete or logic is stuck.
+func (b *Board) NextHint() (hint *Hint, found bool) {
+	if b.isComplete() {
+		return nil, false
+	}
+
+	for _, t := range b.techniques() {
+		if steps := b.dryRun(t.name, t.apply); len(steps) > 0 {
+			return hintFromSteps(steps), true
+		}
+	}
+	return nil, false
+}
+
+// hintFromSteps turns the first deduction of a dry run into a hint. The steps recorded
+// under the same technique and reason belong to that deduction; if it placed a value,
+// the eliminations that followed from it are left out.
+func hintFromSteps(steps []observer.Step) *Hint {
+	first := steps[0]
+	hint := &Hint{
+		Technique:   first.Technique,
+		Action:      observer.ActionEliminate,
+		Explanation: first.Reason,
+	}
+	if hint.Explanation == "" {
+		hint.Explanation = first.Technique
+	}
+
+	var cells, candidates []int
+	for _, step := range steps {
+		if step.Technique != first.Technique || step.Reason != first.Reason {
+			break
+		}
+		if step.Action == observer.ActionSolve {
+			hint.Action = observer.ActionSolve
+			hint.Cells = append([]int{}, step.Cells...)
+			hint.Candidates = []int{step.Candidate}
+			return hint
+		}
+		for _, idx := range step.Cells {
+			if !utils.ContainsInt(cells, idx) {
+				cells = append(cells, idx)
+			}
+			hint.Eliminations = append(hint.Eliminations, Elimination{
+				CellIndex: idx,
+				Candidate: step.Candidate,
+				Technique: step.Technique,
+				Reason:    step.Reason,
+			})
+		}
+		if !utils.ContainsInt(candidates, step.Candidate) {
+			candidates = append(candidates, step.Candidate)
+		}
+	}
+
+	sort.Ints(cells)
+	sort.Ints(candidates)
+	hint.Cells = cells
+	hint.Candidates = candidates
+	return hint
+}
+
+// ApplyHint makes the deduction a hint describes: places its value, or removes its
+// candidates, notifying observers under the hint's technique. The hint must still fit
+// the board exactly - the cell empty with the value still a candidate, or every
+// candidate still present - otherwise the board has changed since the hint was made and
+// an error is returned without changing anything. A hand-built elimination hint without
+// Eliminations removes every candidate in Candidates from every cell in Cells.
+func (b *Board) ApplyHint(h *Hint) error {
+	if h == nil {
+		return &BoardError{Message: "hint cannot be nil"}
+	}
+
+	switch h.Action {
+	case observer.ActionSolve:
+		if len(h.Cells) != 1 || len(h.Candidates) != 1 {
+			return &BoardError{Message: fmt.Sprintf("a %s hint must place one value in one cell", h.Action)}
+		}
+		idx, value := h.Cells[0], h.Candidates[0]
+		cell := b.GetCell(idx)
+		if cell == nil || !cell.HasCandidate(value) {
+			b.log().Warn("Hint %s no longer applies: %d is not a candidate of cell %d", h.Technique, value, idx)
+			return &BoardError{Message: fmt.Sprintf("hint no longer applies: %d is not a candidate of cell %d", value, idx)}
+		}
+
+		b.solvingStep(h.Technique, "%s", h.Explanation)
+		defer b.notifyStep("", "")
+		return b.Set(cell.row, cell.col, value)
+
+	case observer.ActionEliminate:
+		eliminations := h.Eliminations
+		if len(eliminations) == 0 {
+			for _, idx := range h.Cells {
+				for _, candidate := range h.Candidates {
+					if cell := b.GetCell(idx); cell != nil && cell.HasCandidate(candidate) {
+						eliminations = append(eliminations, Elimination{CellIndex: idx, Candidate: candidate})
+					}
+				}
+			}
+		}
+		if len(eliminations) == 0 {
+			return &BoardError{Message: "hint no longer applies: nothing left to eliminate"}
+		}
+		for _, e := range eliminations {
+			if cell := b.GetCell(e.CellIndex); cell == nil || !cell.HasCandidate(e.Candidate) {
+				b.log().Warn("Hint %s no longer applies: %d is not a candidate of cell %d", h.Technique, e.Candidate, e.CellIndex)
+				return &BoardError{Message: fmt.Sprintf("hint no longer applies: %d is not a candidate of cell %d", e.Candidate, e.CellIndex)}
+			}
+		}
+
+		b.solvingStep(h.Technique, "%s", h.Explanation)
+		defer b.notifyStep("", "")
+		for _, e := range eliminations {
+			b.GetCell(e.CellIndex).RemoveCandidate(e.Candidate)
+		}
+		return nil
+
+	default:
+		return &BoardError{Message: fmt.Sprintf("unknown hint action %q", h.Action)}
+	}
+}
@@ -0,0 +1,106 @@
+package lib
+
+import (
+	"fmt"
+)
+
+// cellEdit records one cell's state before and after a move
+type cellEdit struct {
+	index  int
+	before cellState
+	after  cellState
+}
+
+// move is one Set call with every cell it changed, including the candidates
+// removed from other cells by constraint propagation
+type move struct {
+	row, col int
+	edits    []cellEdit
+}
+
+// SetHistoryEnabled turns the move history used by Undo and Redo on or off. It is off by
+// default because every Set then snapshots the whole board to find what changed. Each
+// recorded move keeps only the cells it touched, roughly 40 bytes per cell; a value placed
+// on a standard board changes the cell and up to 20 peers, so about 1 KB per move.
+// Disabling the history also clears it.
+func (b *Board) SetHistoryEnabled(enabled bool) {
+	b.historyEnabled = enabled
+	if !enabled {
+		b.ClearHistory()
+	}
+}
+
+// HistoryEnabled reports whether Set records moves for Undo and Redo
+func (b *Board) HistoryEnabled() bool {
+	return b.historyEnabled
+}
+
+// ClearHistory drops all recorded moves, both undoable and redoable
+func (b *Board) ClearHistory() {
+	b.undoStack = nil
+	b.redoStack = nil
+}
+
+// recordMove pushes the cells that changed since before as a move, and
+// discards the moves that could have been redone
+func (b *Board) recordMove(row, col int, before [81]cellState) {
+	after := b.saveState()
+
+	m := move{row: row, col: col}
+	for i := range after {
+		if after[i] != before[i] {
+			m.edits = append(m.edits, cellEdit{index: i, before: before[i], after: after[i]})
+		}
+	}
+	if len(m.edits) == 0 {
+		return
+	}
+
+	b.undoStack = append(b.undoStack, m)
+	b.redoStack = nil
+}
+
+// Undo reverts the most recent recorded Set, restoring the value and candidates of every
+// cell it changed. Observers are not notified. Returns an error if there is nothing to
+// undo, or if the move placed a given, since givens are never reverted.
+func (b *Board) Undo() error {
+	if len(b.undoStack) == 0 {
+		return &BoardError{Message: "nothing to undo"}
+	}
+
+	m := b.undoStack[len(b.undoStack)-1]
+	for _, edit := range m.edits {
+		if cell := b.board[edit.index]; cell.IsGiven() && edit.before.value != cell.value {
+			return &BoardError{Message: fmt.Sprintf("cannot undo past the given at R%dC%d", cell.row+1, cell.col+1)}
+		}
+	}
+
+	b.undoStack = b.undoStack[:len(b.undoStack)-1]
+	for _, edit := range m.edits {
+		b.board[edit.index].value = edit.before.value
+		b.board[edit.index].candidates = edit.before.candidates
+	}
+	b.redoStack = append(b.redoStack, m)
+
+	b.log().Info("Undid move at R%dC%d (%d cell(s) restored)", m.row+1, m.col+1, len(m.edits))
+	return nil
+}
+
+// Redo reapplies the most recently undone move. Any new Set clears the moves that could
+// be redone. Returns an error if there is nothing to redo.
+func (b *Board) Redo() error {
+	if len(b.redoStack) == 0 {
+		return &BoardError{Message: "nothing to redo"}
+	}
+
+	m := b.redoStack[len(b.redoStack)-1]
+	b.redoStack = b.redoStack[:len(b.redoStack)-1]
+	for _, edit := range m.edits {
+		b.board[edit.index].value = edit.after.value
+		b.board[edit.index].candidates = edit.after.candidates
+	}
+	b.undoStack = append(b.undoStack, m)
+
+	b.log().Info("Redid move at R%dC%d (%d cell(s) changed)", m.row+1, m.col+1, len(m.edits))
+	return nil
+}
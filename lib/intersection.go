@@ -0,0 +1,84 @@
+package lib
+
+// ApplyIntersectionRemoval implements pointing pairs/triples and box-line reduction.
+// When every remaining position of a candidate in one unit lies in the overlap with a
+// second unit, the candidate must go in the overlap and is eliminated from the rest of
+// the second unit. Units are the uniqueness constraints covering one cell per digit,
+// so a box confined to a row and a row confined to a box are both found without assuming
+// which rows, columns or boxes the board actually has.
+// Returns true if any candidates were eliminated
+func (b *Board) ApplyIntersectionRemoval() bool {
+	units := make([]Constraint, 0, len(b.constraints))
+	for _, constraint := range b.GetConstraints() {
+		if b.isUnit(constraint) {
+			units = append(units, constraint)
+		}
+	}
+
+	changed := false
+	for _, source := range units {
+		inSource := make(map[int]bool)
+		for _, idx := range source.GetCells() {
+			inSource[idx] = true
+		}
+
+		for _, target := range units {
+			if target == source {
+				continue
+			}
+
+			// Only overlaps of two or more cells matter; a single shared cell is a hidden single
+			overlap := make(map[int]bool)
+			for _, idx := range target.GetCells() {
+				if inSource[idx] {
+					overlap[idx] = true
+				}
+			}
+			if len(overlap) < 2 || len(overlap) == b.size {
+				continue
+			}
+
+			for candidate := 1; candidate <= 9; candidate++ {
+				cells := b.cellsOutsideOverlap(candidate, source, target, overlap)
+				if len(cells) == 0 {
+					continue
+				}
+				b.solvingStep("Intersection Removal", "%d in %s is confined to %s, removed from the rest of %s",
+					candidate, source.GetName(), target.GetName(), target.GetName())
+				removeCandidateFrom(cells, candidate)
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+// cellsOutsideOverlap returns target's cells outside the overlap that still have the
+// candidate, if all of its positions in source lie inside the overlap
+func (b *Board) cellsOutsideOverlap(candidate int, source, target Constraint, overlap map[int]bool) []*Cell {
+	found := false
+	for _, idx := range source.GetCells() {
+		cell := b.GetCell(idx)
+		if cell == nil || !cell.HasCandidate(candidate) {
+			continue
+		}
+		if !overlap[idx] {
+			return nil
+		}
+		found = true
+	}
+	if !found {
+		return nil
+	}
+
+	var cells []*Cell
+	for _, idx := range target.GetCells() {
+		cell := b.GetCell(idx)
+		if overlap[idx] || cell == nil || !cell.HasCandidate(candidate) {
+			continue
+		}
+		cells = append(cells, cell)
+	}
+	return cells
+}
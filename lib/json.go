@@ -0,0 +1,232 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/eftil/sudoku-solver.git/lib/logger"
+)
+
+// BoardJSONVersion is the version of the JSON format written by MarshalJSON.
+// LoadBoardJSON accepts every version up to and including this one.
+const BoardJSONVersion = 1
+
+// ConstraintSpec is the serialized form of a constraint: a registered type name, the
+// cells it covers, and type-specific parameters such as a killer cage's sum
+type ConstraintSpec struct {
+	Type   string          `json:"type"`
+	Cells  []int           `json:"cells"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// SpecMarshaler is implemented by constraints that can be saved with the board
+type SpecMarshaler interface {
+	ConstraintSpec() (ConstraintSpec, error)
+}
+
+// ConstraintDecoder rebuilds a constraint from its serialized form
+type ConstraintDecoder func(spec ConstraintSpec) (Constraint, error)
+
+var (
+	registryMu         sync.RWMutex
+	constraintRegistry = make(map[string]ConstraintDecoder)
+)
+
+// RegisterConstraintType makes a constraint type loadable by LoadBoardJSON. The
+// constraints package registers its types when imported; custom constraints register
+// their own, using a type name that is unique and never changes once puzzles are saved.
+func RegisterConstraintType(typeName string, decode ConstraintDecoder) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := constraintRegistry[typeName]; exists {
+		logger.Warn("Constraint type %q registered twice, replacing the earlier decoder", typeName)
+	}
+	constraintRegistry[typeName] = decode
+}
+
+// RegisteredConstraintTypes returns the registered type names in sorted order
+func RegisteredConstraintTypes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(constraintRegistry))
+	for name := range constraintRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// boardJSON is the stable on-disk layout. Fields may be added in later versions,
+// but existing ones keep their names and meaning.
+type boardJSON struct {
+	Version     int              `json:"version"`
+	Size        int              `json:"size,omitempty"`   // Board size, omitted for 9x9
+	Grid        string           `json:"grid"`             // size*size characters, see String
+	Givens      []int            `json:"givens,omitempty"` // Indices of cells marked as givens
+	Constraints []ConstraintSpec `json:"constraints"`
+}
+
+// MarshalJSON writes the cell values, givens and every constraint with its parameters.
+// Candidates, solver settings and observers are not saved. Every constraint must
+// implement SpecMarshaler.
+func (b *Board) MarshalJSON() ([]byte, error) {
+	doc := boardJSON{
+		Version:     BoardJSONVersion,
+		Grid:        b.String(),
+		Constraints: make([]ConstraintSpec, 0, len(b.constraints)),
+	}
+	if b.size != DefaultSize {
+		doc.Size = b.size
+	}
+
+	for idx, cell := range b.board {
+		if cell != nil && cell.IsGiven() {
+			doc.Givens = append(doc.Givens, idx)
+		}
+	}
+
+	for _, constraint := range b.constraints {
+		marshaler, ok := constraint.(SpecMarshaler)
+		if !ok {
+			return nil, &BoardError{Message: fmt.Sprintf("constraint %q cannot be serialized", constraint.GetName())}
+		}
+		spec, err := marshaler.ConstraintSpec()
+		if err != nil {
+			return nil, fmt.Errorf("serializing constraint %q: %w", constraint.GetName(), err)
+		}
+		doc.Constraints = append(doc.Constraints, spec)
+	}
+
+	return json.Marshal(doc)
+}
+
+// LoadBoardJSON builds a board from the output of MarshalJSON. Constraints are rebuilt
+// through the registry and added before the values are set, so candidates are derived
+// by normal propagation. Constraint types must be registered first; for the built-in
+// constraints that means importing the constraints package.
+func LoadBoardJSON(data []byte) (*Board, error) {
+	var doc boardJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing board JSON: %w", err)
+	}
+
+	if doc.Version < 1 || doc.Version > BoardJSONVersion {
+		return nil, &BoardError{Message: fmt.Sprintf("unsupported board JSON version %d (supported: 1-%d)",
+			doc.Version, BoardJSONVersion)}
+	}
+
+	b := NewBoard()
+	if doc.Size != 0 && doc.Size != DefaultSize {
+		sized, err := NewBoardOfSize(doc.Size)
+		if err != nil {
+			return nil, err
+		}
+		b = sized
+	}
+	for i, spec := range doc.Constraints {
+		registryMu.RLock()
+		decode, ok := constraintRegistry[spec.Type]
+		registryMu.RUnlock()
+		if !ok {
+			return nil, &BoardError{Message: fmt.Sprintf("constraint %d has unknown type %q", i, spec.Type)}
+		}
+
+		constraint, err := decode(spec)
+		if err != nil {
+			return nil, fmt.Errorf("constraint %d (%s): %w", i, spec.Type, err)
+		}
+
+		// The parameters must describe the same cells that were saved
+		if !reflect.DeepEqual(constraint.GetCells(), spec.Cells) {
+			return nil, &BoardError{Message: fmt.Sprintf("constraint %d (%s) covers cells %v, saved as %v",
+				i, spec.Type, constraint.GetCells(), spec.Cells)}
+		}
+		b.AddConstraint(constraint)
+	}
+
+	if err := b.LoadString(doc.Grid); err != nil {
+		return nil, err
+	}
+
+	// LoadString marks every value as a given; only the saved givens are
+	for _, cell := range b.board {
+		if cell != nil {
+			cell.isGiven = false
+		}
+	}
+	for _, idx := range doc.Givens {
+		cell := b.GetCell(idx)
+		if cell == nil || !cell.IsSolved() {
+			return nil, &BoardError{Message: fmt.Sprintf("given %d is not a filled cell", idx)}
+		}
+		cell.MarkGiven()
+	}
+
+	return b, nil
+}
+
+// LoadBoard reads a board from r in either format: a JSON document as written by Save
+// or MarshalJSON, recognised by its leading '{', or a plain grid as accepted by
+// LoadString. A plain grid of 16 or 36 cells gives a 4x4 or 6x6 board, anything else a
+// 9x9 one; plain grids carry no constraints, so add them (for example with
+// constraints.AddStandardConstraints) before solving.
+func LoadBoard(r io.Reader) (*Board, error) {
+	if r == nil {
+		return nil, &BoardError{Message: "reader cannot be nil"}
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading board: %w", err)
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, &BoardError{Message: "no board data to load"}
+	}
+	if data[0] == '{' {
+		return LoadBoardJSON(data)
+	}
+
+	cells := 0
+	for _, ch := range string(data) {
+		if ch != ' ' && ch != '\t' && ch != '\n' && ch != '\r' {
+			cells++
+		}
+	}
+	b := NewBoard()
+	for size := range boxShapes {
+		if size != DefaultSize && size*size == cells {
+			if b, err = NewBoardOfSize(size); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if err := b.LoadString(string(data)); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Save writes the board to w as a JSON document followed by a newline, which LoadBoard
+// reads back with its constraints and givens. Use String for the compact grid alone.
+func (b *Board) Save(w io.Writer) error {
+	if w == nil {
+		return &BoardError{Message: "writer cannot be nil"}
+	}
+	data, err := b.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing board: %w", err)
+	}
+	return nil
+}
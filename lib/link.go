@@ -0,0 +1,77 @@
+package lib
+
+import (
+	"github.com/eftil/sudoku-solver.git/lib/logger"
+)
+
+// cellLink is an observer bridge that copies a solved value from a cell on one board
+// to a cell on another board
+type cellLink struct {
+	target      *Board
+	targetIndex int
+
+	// forwarding is set while the link is pushing a value, so a chain of links that
+	// loops back to this one stops instead of recursing
+	forwarding bool
+}
+
+// OnSingleCandidate is a no-op, only solved values are forwarded
+func (cl *cellLink) OnSingleCandidate(row, col, candidate int) {}
+
+// OnCandidateEliminated is a no-op, only solved values are forwarded
+func (cl *cellLink) OnCandidateEliminated(row, col, candidate int, remainingCount int) {}
+
+// OnCellSolved sets the linked cell on the target board to the same value
+func (cl *cellLink) OnCellSolved(row, col, value int) {
+	if cl.forwarding {
+		return
+	}
+
+	targetCell := cl.target.GetCell(cl.targetIndex)
+	if targetCell == nil || targetCell.GetValue() == value {
+		return // Nothing to do - this also ends the round trip of a bidirectional link
+	}
+
+	targetRow, targetCol := cl.targetIndex/9, cl.targetIndex%9
+	if targetCell.IsSolved() {
+		logger.Warn("Linked cell R%dC%d already holds %d, not overwriting with %d from R%dC%d",
+			targetRow+1, targetCol+1, targetCell.GetValue(), value, row+1, col+1)
+		return
+	}
+
+	cl.forwarding = true
+	defer func() { cl.forwarding = false }()
+
+	logger.Info("Forwarding R%dC%d=%d to linked cell R%dC%d", row+1, col+1, value, targetRow+1, targetCol+1)
+	if err := targetCell.SetValue(value); err != nil {
+		logger.Error("Failed to set linked cell R%dC%d: %v", targetRow+1, targetCol+1, err)
+	}
+}
+
+// LinkCell links the cell at myIndex to the cell at otherIndex on another board: whenever
+// this cell is solved, the other cell is set to the same value (and propagates through
+// the other board's constraints as usual). If this cell is already solved the value is
+// forwarded immediately.
+//
+// Links are single-direction. For a bidirectional link call LinkCell on both boards;
+// a link never overwrites a cell that already holds a value, so the echo from the
+// reverse link stops immediately instead of cycling between the boards.
+func (b *Board) LinkCell(myIndex int, other *Board, otherIndex int) {
+	source := b.GetCell(myIndex)
+	if source == nil || other == nil || other.GetCell(otherIndex) == nil {
+		b.log().Error("Invalid cell link: index %d to index %d", myIndex, otherIndex)
+		return
+	}
+	if other == b && myIndex == otherIndex {
+		b.log().Warn("Ignoring link of cell %d to itself", myIndex)
+		return
+	}
+
+	link := &cellLink{target: other, targetIndex: otherIndex}
+	source.AddObserver(link)
+	b.log().Debug("Linked cell %d to cell %d", myIndex, otherIndex)
+
+	if source.IsSolved() {
+		link.OnCellSolved(source.GetRow(), source.GetCol(), source.GetValue())
+	}
+}
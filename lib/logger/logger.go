@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -35,14 +36,31 @@ func (l LogLevel) String() string {
 	}
 }
 
+// Format selects how log lines are written
+type Format int
+
+const (
+	// Text writes human-readable lines: [time] [LEVEL] [Cell R1C1] message
+	Text Format = iota
+	// JSON writes one object per line with time, level, technique, cell and message
+	// fields, plus candidate, value, reason and constraint when the call carries them
+	JSON
+)
+
 // Logger handles all logging operations with structured output
 type Logger struct {
 	mu         sync.Mutex
 	level      LogLevel
+	format     Format
 	output     io.Writer
 	prefix     string
 	showTime   bool
 	showCaller bool
+
+	// Category filters for SolvingStep (by technique) and the constraint helpers (by
+	// constraint name). When enabled is non-empty only those categories are logged.
+	enabled  map[string]bool
+	disabled map[string]bool
 }
 
 // Global logger instance
@@ -72,6 +90,13 @@ func SetLevel(level LogLevel) {
 	globalLogger.level = level
 }
 
+// GetLevel returns the current minimum log level of the logger
+func GetLevel() LogLevel {
+	globalLogger.mu.Lock()
+	defer globalLogger.mu.Unlock()
+	return globalLogger.level
+}
+
 // SetOutput sets the output destination for the logger
 func SetOutput(w io.Writer) {
 	globalLogger.mu.Lock()
@@ -79,6 +104,70 @@ func SetOutput(w io.Writer) {
 	globalLogger.output = w
 }
 
+// SetFormat sets whether log lines are written as text (the default) or JSON
+func SetFormat(format Format) {
+	globalLogger.mu.Lock()
+	defer globalLogger.mu.Unlock()
+	globalLogger.format = format
+}
+
+// GetFormat returns the current log format
+func GetFormat() Format {
+	globalLogger.mu.Lock()
+	defer globalLogger.mu.Unlock()
+	return globalLogger.format
+}
+
+// EnableCategory logs only the enabled categories: once any category is enabled,
+// solving steps and constraint messages of other categories are dropped. A category is a
+// technique name passed to SolvingStep, such as "X-Wing", or a constraint name passed to
+// DebugConstraint and InfoConstraint. Messages without a category are not affected.
+func EnableCategory(category string) {
+	globalLogger.mu.Lock()
+	defer globalLogger.mu.Unlock()
+	if globalLogger.enabled == nil {
+		globalLogger.enabled = make(map[string]bool)
+	}
+	globalLogger.enabled[category] = true
+	delete(globalLogger.disabled, category)
+}
+
+// DisableCategory drops the solving steps or constraint messages of one category, such as
+// "Propagation", whatever their level
+func DisableCategory(category string) {
+	globalLogger.mu.Lock()
+	defer globalLogger.mu.Unlock()
+	if globalLogger.disabled == nil {
+		globalLogger.disabled = make(map[string]bool)
+	}
+	globalLogger.disabled[category] = true
+	delete(globalLogger.enabled, category)
+}
+
+// ResetCategories removes all category filters, logging every category again
+func ResetCategories() {
+	globalLogger.mu.Lock()
+	defer globalLogger.mu.Unlock()
+	globalLogger.enabled = nil
+	globalLogger.disabled = nil
+}
+
+// categoryAllowed reports whether an entry passes the category filters. The caller must
+// hold l.mu.
+func (l *Logger) categoryAllowed(e entry) bool {
+	category := e.Technique
+	if category == "" {
+		category = e.Constraint
+	}
+	if category == "" {
+		return true
+	}
+	if l.disabled[category] {
+		return false
+	}
+	return len(l.enabled) == 0 || l.enabled[category]
+}
+
 // SetPrefix sets a prefix for all log messages
 func SetPrefix(prefix string) {
 	globalLogger.mu.Lock()
@@ -86,110 +175,193 @@ func SetPrefix(prefix string) {
 	globalLogger.prefix = prefix
 }
 
-// formatMessage formats a log message with timestamp and level
-func (l *Logger) formatMessage(level LogLevel, format string, args ...interface{}) string {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+// entry is one log line. The text format flattens it into a single string, the JSON
+// format writes the fields as they are.
+type entry struct {
+	Time       string `json:"time"`
+	Level      string `json:"level"`
+	Prefix     string `json:"prefix,omitempty"`
+	Technique  string `json:"technique"`
+	Cell       string `json:"cell"` // R1C1 notation, 1-based
+	Constraint string `json:"constraint,omitempty"`
+	Candidate  int    `json:"candidate,omitempty"`
+	Value      int    `json:"value,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Message    string `json:"message"`
+}
+
+// cellName returns the R1C1 name of a 0-based cell position
+func cellName(row, col int) string {
+	return fmt.Sprintf("R%dC%d", row+1, col+1)
+}
+
+// render formats an entry in the logger's format. The caller must hold l.mu.
+func (l *Logger) render(level LogLevel, e entry) string {
+	now := time.Now()
+	e.Level = level.String()
+	e.Prefix = l.prefix
+
+	if l.format == JSON {
+		e.Time = now.Format(time.RFC3339Nano)
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Sprintf(`{"level":"ERROR","message":%q}`, "cannot encode log entry: "+err.Error())
+		}
+		return string(data)
+	}
 
 	var msg string
 	if l.showTime {
-		msg = fmt.Sprintf("[%s] ", time.Now().Format("2006-01-02 15:04:05"))
+		msg = fmt.Sprintf("[%s] ", now.Format("2006-01-02 15:04:05"))
 	}
 
-	msg += fmt.Sprintf("[%s] ", level.String())
+	msg += fmt.Sprintf("[%s] ", e.Level)
 
-	if l.prefix != "" {
-		msg += fmt.Sprintf("[%s] ", l.prefix)
+	if e.Prefix != "" {
+		msg += fmt.Sprintf("[%s] ", e.Prefix)
+	}
+	if e.Technique != "" {
+		msg += fmt.Sprintf("[SOLVING: %s] ", e.Technique)
+	}
+	if e.Cell != "" {
+		msg += fmt.Sprintf("[Cell %s] ", e.Cell)
+	}
+	if e.Constraint != "" {
+		msg += fmt.Sprintf("[%s] ", e.Constraint)
 	}
 
-	msg += fmt.Sprintf(format, args...)
+	msg += e.Message
+	if e.Reason != "" {
+		msg += " - Reason: " + e.Reason
+	}
 
 	return msg
 }
 
-// log is the internal logging method
-func (l *Logger) log(level LogLevel, format string, args ...interface{}) {
-	if level < l.level {
-		return
-	}
-
-	msg := l.formatMessage(level, format, args...)
+// allows reports whether messages of the given level are logged. The helpers check it
+// before formatting, so filtered messages cost no formatting or allocation.
+func (l *Logger) allows(level LogLevel) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return level >= l.level
+}
 
+// log is the internal logging method
+func (l *Logger) log(level LogLevel, e entry) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	if l.output != nil {
-		fmt.Fprintln(l.output, msg)
+	if level < l.level {
+		return
+	}
+	if l.output != nil && l.categoryAllowed(e) {
+		fmt.Fprintln(l.output, l.render(level, e))
 	}
 }
 
 // Debug logs a debug message
 func Debug(format string, args ...interface{}) {
-	globalLogger.log(DEBUG, format, args...)
+	if !globalLogger.allows(DEBUG) {
+		return
+	}
+	globalLogger.log(DEBUG, entry{Message: fmt.Sprintf(format, args...)})
 }
 
 // Info logs an info message
 func Info(format string, args ...interface{}) {
-	globalLogger.log(INFO, format, args...)
+	if !globalLogger.allows(INFO) {
+		return
+	}
+	globalLogger.log(INFO, entry{Message: fmt.Sprintf(format, args...)})
 }
 
 // Warn logs a warning message
 func Warn(format string, args ...interface{}) {
-	globalLogger.log(WARN, format, args...)
+	if !globalLogger.allows(WARN) {
+		return
+	}
+	globalLogger.log(WARN, entry{Message: fmt.Sprintf(format, args...)})
 }
 
 // Error logs an error message
 func Error(format string, args ...interface{}) {
-	globalLogger.log(ERROR, format, args...)
+	if !globalLogger.allows(ERROR) {
+		return
+	}
+	globalLogger.log(ERROR, entry{Message: fmt.Sprintf(format, args...)})
 }
 
 // DebugCell logs cell-specific debug information
 func DebugCell(row, col int, format string, args ...interface{}) {
-	msg := fmt.Sprintf("[Cell R%dC%d] %s", row+1, col+1, fmt.Sprintf(format, args...))
-	globalLogger.log(DEBUG, "%s", msg)
+	if !globalLogger.allows(DEBUG) {
+		return
+	}
+	globalLogger.log(DEBUG, entry{Cell: cellName(row, col), Message: fmt.Sprintf(format, args...)})
 }
 
 // InfoCell logs cell-specific info
 func InfoCell(row, col int, format string, args ...interface{}) {
-	msg := fmt.Sprintf("[Cell R%dC%d] %s", row+1, col+1, fmt.Sprintf(format, args...))
-	globalLogger.log(INFO, "%s", msg)
+	if !globalLogger.allows(INFO) {
+		return
+	}
+	globalLogger.log(INFO, entry{Cell: cellName(row, col), Message: fmt.Sprintf(format, args...)})
 }
 
 // DebugConstraint logs constraint-specific debug information
 func DebugConstraint(constraintName string, format string, args ...interface{}) {
-	msg := fmt.Sprintf("[%s] %s", constraintName, fmt.Sprintf(format, args...))
-	globalLogger.log(DEBUG, "%s", msg)
+	if !globalLogger.allows(DEBUG) {
+		return
+	}
+	globalLogger.log(DEBUG, entry{Constraint: constraintName, Message: fmt.Sprintf(format, args...)})
 }
 
 // InfoConstraint logs constraint-specific info
 func InfoConstraint(constraintName string, format string, args ...interface{}) {
-	msg := fmt.Sprintf("[%s] %s", constraintName, fmt.Sprintf(format, args...))
-	globalLogger.log(INFO, "%s", msg)
+	if !globalLogger.allows(INFO) {
+		return
+	}
+	globalLogger.log(INFO, entry{Constraint: constraintName, Message: fmt.Sprintf(format, args...)})
 }
 
 // SolvingStep logs a solving technique step
 func SolvingStep(technique string, format string, args ...interface{}) {
-	msg := fmt.Sprintf("[SOLVING: %s] %s", technique, fmt.Sprintf(format, args...))
-	globalLogger.log(INFO, "%s", msg)
+	if !globalLogger.allows(INFO) {
+		return
+	}
+	globalLogger.log(INFO, entry{Technique: technique, Message: fmt.Sprintf(format, args...)})
 }
 
 // CandidateElimination logs when candidates are eliminated
 func CandidateElimination(row, col, candidate int, reason string) {
-	msg := fmt.Sprintf("[Cell R%dC%d] Eliminated candidate %d - Reason: %s",
-		row+1, col+1, candidate, reason)
-	globalLogger.log(DEBUG, "%s", msg)
+	if !globalLogger.allows(DEBUG) {
+		return
+	}
+	globalLogger.log(DEBUG, entry{
+		Cell:      cellName(row, col),
+		Candidate: candidate,
+		Reason:    reason,
+		Message:   fmt.Sprintf("Eliminated candidate %d", candidate),
+	})
 }
 
 // CellSolved logs when a cell is solved
 func CellSolved(row, col, value int, reason string) {
-	msg := fmt.Sprintf("[Cell R%dC%d] Solved with value %d - Reason: %s",
-		row+1, col+1, value, reason)
-	globalLogger.log(INFO, "%s", msg)
+	if !globalLogger.allows(INFO) {
+		return
+	}
+	globalLogger.log(INFO, entry{
+		Cell:    cellName(row, col),
+		Value:   value,
+		Reason:  reason,
+		Message: fmt.Sprintf("Solved with value %d", value),
+	})
 }
 
 // Fatal logs a fatal error and exits the program
 func Fatal(format string, args ...interface{}) {
-	msg := globalLogger.formatMessage(ERROR, format, args...)
+	globalLogger.mu.Lock()
+	msg := globalLogger.render(ERROR, entry{Message: fmt.Sprintf(format, args...)})
+	globalLogger.mu.Unlock()
 	log.Fatal(msg)
 }
 
@@ -9,6 +9,11 @@ type AutoSolverObserver struct {
 	enabled       bool
 	cellsToSolve  map[string]int // Map of "row,col" -> value
 	solutionCount int
+
+	// Progress statistics: how solved cells were reached and how many candidates went
+	nakedSingleCount int // Single-candidate detections
+	externalSetCount int // Cells solved without a prior single-candidate detection
+	eliminationCount int // Candidate eliminations observed
 }
 
 // NewAutoSolverObserver creates a new auto-solver observer
@@ -27,6 +32,9 @@ func (aso *AutoSolverObserver) OnSingleCandidate(row, col, candidate int) {
 	}
 
 	key := fmt.Sprintf("%d,%d", row, col)
+	if _, seen := aso.cellsToSolve[key]; !seen {
+		aso.nakedSingleCount++
+	}
 	aso.cellsToSolve[key] = candidate
 	fmt.Printf("📝 Observer detected: Cell R%dC%d can be auto-solved with value %d\n",
 		row+1, col+1, candidate)
@@ -42,15 +50,22 @@ func (aso *AutoSolverObserver) OnCellSolved(row, col, value int) {
 	fmt.Printf("✓ Cell R%dC%d solved with value %d (Total solved: %d)\n",
 		row+1, col+1, value, aso.solutionCount)
 
-	// Remove from cellsToSolve if it was there
+	// Remove from cellsToSolve if it was there; otherwise the value came from outside
 	key := fmt.Sprintf("%d,%d", row, col)
+	if _, detected := aso.cellsToSolve[key]; !detected {
+		aso.externalSetCount++
+	}
 	delete(aso.cellsToSolve, key)
 }
 
 // OnCandidateEliminated is called when a candidate is removed from a cell
 func (aso *AutoSolverObserver) OnCandidateEliminated(row, col, candidate, remainingCount int) {
-	// This observer doesn't need to do anything for general candidate elimination
-	// (OnSingleCandidate will be called when count reaches 1)
+	if !aso.enabled {
+		return
+	}
+
+	// Only counted; OnSingleCandidate is called when the count reaches 1
+	aso.eliminationCount++
 }
 
 // GetCellsToSolve returns the cells that have been identified as solvable
@@ -68,6 +83,24 @@ func (aso *AutoSolverObserver) GetSolutionCount() int {
 	return aso.solutionCount
 }
 
+// GetNakedSingleCount returns how many cells were detected with a single candidate left.
+// Each cell is counted once, even if the detection repeats before it is solved.
+func (aso *AutoSolverObserver) GetNakedSingleCount() int {
+	return aso.nakedSingleCount
+}
+
+// GetExternalSetCount returns how many cells were solved without having been detected
+// as a naked single first, e.g. givens or values set by hand. Together with the naked
+// singles that were then solved it makes up GetSolutionCount.
+func (aso *AutoSolverObserver) GetExternalSetCount() int {
+	return aso.externalSetCount
+}
+
+// GetEliminationCount returns the total number of candidate eliminations observed
+func (aso *AutoSolverObserver) GetEliminationCount() int {
+	return aso.eliminationCount
+}
+
 // Enable enables the observer
 func (aso *AutoSolverObserver) Enable() {
 	aso.enabled = true
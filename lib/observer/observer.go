@@ -1,5 +1,9 @@
 package observer
 
+import (
+	"github.com/eftil/sudoku-solver.git/lib/logger"
+)
+
 // CellObserver is an interface for objects that want to be notified of cell events
 type CellObserver interface {
 	// OnSingleCandidate is called when a cell has only one candidate remaining
@@ -12,9 +16,44 @@ type CellObserver interface {
 	OnCandidateEliminated(row, col, candidate int, remainingCount int)
 }
 
+// StepObserver is an optional interface for CellObservers registered on a board that
+// want to know which solving step caused the cell events that follow
+type StepObserver interface {
+	// OnSolvingStep is called when a technique starts a deduction. An empty technique
+	// means the events that follow no longer belong to a solving step.
+	OnSolvingStep(technique, reason string)
+}
+
+// CandidatesObserver is an optional interface for CellObservers that handle several
+// candidates removed from a cell at once, see NotifyCandidatesEliminated. Observers
+// without it get one OnCandidateEliminated per candidate instead.
+type CandidatesObserver interface {
+	// OnCandidatesEliminated is called once with every candidate removed from a cell,
+	// in ascending order, and the number of candidates left
+	OnCandidatesEliminated(row, col int, candidates []int, remainingCount int)
+}
+
+// MaxNotifyDepth bounds how deeply notifications of one notifier may nest, for example
+// when an observer sets a value that leads back to the same cell. Deeper notifications
+// are dropped with an error instead of overflowing the stack.
+const MaxNotifyDepth = 16
+
 // CellNotifier manages observers for cell events
 type CellNotifier struct {
 	observers []CellObserver
+
+	// depth is the number of notifications of this notifier currently running
+	depth int
+
+	// batchDepth counts open BeginBatch calls; while it is non-zero single candidate
+	// notifications wait in pending
+	batchDepth int
+	pending    []pendingSingle
+}
+
+// pendingSingle is a single candidate notification deferred by a batch
+type pendingSingle struct {
+	row, col, candidate int
 }
 
 // NewCellNotifier creates a new cell notifier
@@ -46,25 +85,109 @@ func (cn *CellNotifier) RemoveObserver(observer CellObserver) {
 	}
 }
 
-// NotifySingleCandidate notifies all observers that a cell has a single candidate
+// BeginBatch starts deferring single candidate notifications until the matching
+// EndBatch, so observers that act on them (such as an auto-solver setting the value) run
+// once propagation has settled instead of in the middle of it. Batches nest.
+func (cn *CellNotifier) BeginBatch() {
+	cn.batchDepth++
+}
+
+// EndBatch closes a batch. When the outermost batch ends, the deferred notifications are
+// sent in order, each once. By then the cell may have been solved or lost the candidate,
+// so observers should check the cell before acting.
+func (cn *CellNotifier) EndBatch() {
+	if cn.batchDepth == 0 {
+		logger.Warn("EndBatch called without a matching BeginBatch")
+		return
+	}
+	cn.batchDepth--
+	if cn.batchDepth > 0 {
+		return
+	}
+
+	pending := cn.pending
+	cn.pending = nil
+	for _, p := range pending {
+		cn.NotifySingleCandidate(p.row, p.col, p.candidate)
+	}
+}
+
+// InBatch returns true while a batch is open
+func (cn *CellNotifier) InBatch() bool {
+	return cn.batchDepth > 0
+}
+
+// NotifySingleCandidate notifies all observers that a cell has a single candidate.
+// Inside a batch the notification is deferred until the batch ends.
 func (cn *CellNotifier) NotifySingleCandidate(row, col, candidate int) {
-	for _, observer := range cn.observers {
-		observer.OnSingleCandidate(row, col, candidate)
+	if cn.batchDepth > 0 {
+		single := pendingSingle{row: row, col: col, candidate: candidate}
+		for _, p := range cn.pending {
+			if p == single {
+				return
+			}
+		}
+		cn.pending = append(cn.pending, single)
+		return
 	}
+
+	cn.notify("OnSingleCandidate", row, col, func(observer CellObserver) {
+		observer.OnSingleCandidate(row, col, candidate)
+	})
 }
 
 // NotifyCellSolved notifies all observers that a cell has been solved
 func (cn *CellNotifier) NotifyCellSolved(row, col, value int) {
-	for _, observer := range cn.observers {
+	cn.notify("OnCellSolved", row, col, func(observer CellObserver) {
 		observer.OnCellSolved(row, col, value)
-	}
+	})
 }
 
 // NotifyCandidateEliminated notifies all observers that a candidate was eliminated
 func (cn *CellNotifier) NotifyCandidateEliminated(row, col, candidate, remainingCount int) {
-	for _, observer := range cn.observers {
+	cn.notify("OnCandidateEliminated", row, col, func(observer CellObserver) {
 		observer.OnCandidateEliminated(row, col, candidate, remainingCount)
+	})
+}
+
+// NotifyCandidatesEliminated notifies all observers that several candidates were removed
+// from a cell together. A CandidatesObserver gets them in one call, any other observer one
+// OnCandidateEliminated per candidate, each with the final remaining count.
+func (cn *CellNotifier) NotifyCandidatesEliminated(row, col int, candidates []int, remainingCount int) {
+	cn.notify("OnCandidatesEliminated", row, col, func(observer CellObserver) {
+		if bulk, ok := observer.(CandidatesObserver); ok {
+			bulk.OnCandidatesEliminated(row, col, candidates, remainingCount)
+			return
+		}
+		for _, candidate := range candidates {
+			observer.OnCandidateEliminated(row, col, candidate, remainingCount)
+		}
+	})
+}
+
+// notify sends an event to every observer, refusing to nest deeper than MaxNotifyDepth
+func (cn *CellNotifier) notify(event string, row, col int, call func(observer CellObserver)) {
+	if cn.depth >= MaxNotifyDepth {
+		logger.Error("Dropping %s for R%dC%d: notifications nested %d deep", event, row+1, col+1, cn.depth)
+		return
 	}
+
+	cn.depth++
+	defer func() { cn.depth-- }()
+	for _, observer := range cn.observers {
+		safeNotify(event, row, col, func() { call(observer) })
+	}
+}
+
+// safeNotify calls a single observer, recovering from any panic so that a
+// misbehaving observer cannot crash the solve or starve the observers after it
+func safeNotify(event string, row, col int, notify func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Observer panicked in %s for R%dC%d: %v", event, row+1, col+1, r)
+		}
+	}()
+	notify()
 }
 
 // HasObservers returns true if there are any observers registered
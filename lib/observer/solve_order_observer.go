@@ -0,0 +1,59 @@
+package observer
+
+// SolvedCell is a value placed on the board
+type SolvedCell struct {
+	Row   int `json:"row"`
+	Col   int `json:"col"`
+	Value int `json:"value"`
+}
+
+// SolveOrderObserver records the cells solved during a solve, in the order they were
+// placed, for animating a solve. Register it with Board.AddObserver. Like SolveRecorder it
+// only records inside solving steps, so the givens of a loaded puzzle and the values the
+// search tries and takes back are left out.
+type SolveOrderObserver struct {
+	inStep bool
+	order  []SolvedCell
+}
+
+// NewSolveOrderObserver creates an empty solve order observer
+func NewSolveOrderObserver() *SolveOrderObserver {
+	return &SolveOrderObserver{
+		order: make([]SolvedCell, 0),
+	}
+}
+
+// OnSolvingStep is called when a technique starts a deduction
+func (so *SolveOrderObserver) OnSolvingStep(technique, reason string) {
+	so.inStep = technique != ""
+}
+
+// OnSingleCandidate is called when a cell has only one candidate remaining
+func (so *SolveOrderObserver) OnSingleCandidate(row, col, candidate int) {
+	// Not solved yet
+}
+
+// OnCellSolved is called when a cell's value is set
+func (so *SolveOrderObserver) OnCellSolved(row, col, value int) {
+	if !so.inStep {
+		return
+	}
+	so.order = append(so.order, SolvedCell{Row: row, Col: col, Value: value})
+}
+
+// OnCandidateEliminated is called when a candidate is removed from a cell
+func (so *SolveOrderObserver) OnCandidateEliminated(row, col, candidate int, remainingCount int) {
+	// Only placed values are recorded
+}
+
+// GetOrder returns a copy of the solved cells, first solved first
+func (so *SolveOrderObserver) GetOrder() []SolvedCell {
+	order := make([]SolvedCell, len(so.order))
+	copy(order, so.order)
+	return order
+}
+
+// Clear removes all recorded cells
+func (so *SolveOrderObserver) Clear() {
+	so.order = make([]SolvedCell, 0)
+}
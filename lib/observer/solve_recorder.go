@@ -0,0 +1,84 @@
+package observer
+
+// StepAction is the kind of board change recorded in a Step
+type StepAction string
+
+const (
+	ActionSolve     StepAction = "solve"     // A value was placed
+	ActionEliminate StepAction = "eliminate" // A candidate was removed
+)
+
+// Step is one recorded board change with the deduction that caused it
+type Step struct {
+	Technique string     `json:"technique"`
+	Cells     []int      `json:"cells"`     // Indices (0-80) of the changed cells
+	Candidate int        `json:"candidate"` // Value placed or candidate removed
+	Action    StepAction `json:"action"`
+	Reason    string     `json:"reason"` // Explanation logged by the technique, may be empty
+}
+
+// SolveRecorder records every value placed and candidate removed during a solve as
+// structured steps, in the order they happen, so a UI can replay the solve. Register it
+// with Board.AddObserver. Events outside a solving step, such as loading a puzzle, are
+// not recorded. Eliminations that follow from placing a value are recorded under the
+// step that placed it, and may come before the solve event itself.
+type SolveRecorder struct {
+	technique string
+	reason    string
+	steps     []Step
+}
+
+// NewSolveRecorder creates an empty solve recorder
+func NewSolveRecorder() *SolveRecorder {
+	return &SolveRecorder{
+		steps: make([]Step, 0),
+	}
+}
+
+// OnSolvingStep is called when a technique starts a deduction
+func (sr *SolveRecorder) OnSolvingStep(technique, reason string) {
+	sr.technique = technique
+	sr.reason = reason
+}
+
+// OnSingleCandidate is called when a cell has only one candidate remaining
+func (sr *SolveRecorder) OnSingleCandidate(row, col, candidate int) {
+	// Not a board change; the elimination that caused it is recorded
+}
+
+// OnCellSolved is called when a cell's value is set
+func (sr *SolveRecorder) OnCellSolved(row, col, value int) {
+	sr.record(row, col, value, ActionSolve)
+}
+
+// OnCandidateEliminated is called when a candidate is removed from a cell
+func (sr *SolveRecorder) OnCandidateEliminated(row, col, candidate int, remainingCount int) {
+	sr.record(row, col, candidate, ActionEliminate)
+}
+
+// record appends a step for the current deduction, if there is one
+func (sr *SolveRecorder) record(row, col, candidate int, action StepAction) {
+	if sr.technique == "" {
+		return
+	}
+
+	sr.steps = append(sr.steps, Step{
+		Technique: sr.technique,
+		Cells:     []int{row*9 + col},
+		Candidate: candidate,
+		Action:    action,
+		Reason:    sr.reason,
+	})
+}
+
+// GetSteps returns a copy of the recorded steps, oldest first
+func (sr *SolveRecorder) GetSteps() []Step {
+	steps := make([]Step, len(sr.steps))
+	copy(steps, sr.steps)
+	return steps
+}
+
+// Clear removes all recorded steps
+func (sr *SolveRecorder) Clear() {
+	sr.steps = make([]Step, 0)
+}
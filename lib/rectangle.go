@@ -0,0 +1,126 @@
+package lib
+
+import (
+	"math/bits"
+)
+
+// applyUniqueRectangles implements the Type 1 unique rectangle. Four unsolved cells at
+// the corners of a rectangle over two rows, two columns and two boxes would form a
+// deadly pattern if all held only {X,Y}: X and Y could be swapped without breaking any
+// unit, giving two solutions. So when three corners are bivalue {X,Y}, the fourth cannot
+// be X or Y and both are eliminated from it.
+// Like BUG+1 this assumes the puzzle has a unique solution, and it only runs with
+// SetAssumeUnique(true).
+// Returns true if any candidates were eliminated
+func (b *Board) applyUniqueRectangles() bool {
+	changed := false
+
+	for r1 := 0; r1 < b.size; r1++ {
+		for r2 := r1 + 1; r2 < b.size; r2++ {
+			for c1 := 0; c1 < b.size; c1++ {
+				for c2 := c1 + 1; c2 < b.size; c2++ {
+					if b.applyUniqueRectangle([4]int{r1*9 + c1, r1*9 + c2, r2*9 + c1, r2*9 + c2}) {
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	return changed
+}
+
+// applyUniqueRectangle checks one rectangle, with corners listed row by row, for a
+// Type 1 pattern and eliminates the pair from its fourth corner
+func (b *Board) applyUniqueRectangle(corners [4]int) bool {
+	var cells [4]*Cell
+	for i, idx := range corners {
+		cells[i] = b.GetCell(idx)
+		if cells[i] == nil || cells[i].IsSolved() {
+			return false
+		}
+	}
+
+	// Three corners share one pair, the fourth holds it with extra candidates
+	var pair uint16
+	target := -1
+	for i, cell := range cells {
+		if cell.CandidateCount() != 2 {
+			if target != -1 {
+				return false
+			}
+			target = i
+			continue
+		}
+		if pair != 0 && cell.candidateMask() != pair {
+			return false
+		}
+		pair = cell.candidateMask()
+	}
+	if target == -1 || cells[target].candidateMask()&pair != pair {
+		return false
+	}
+
+	if !b.isDeadlyRectangle(cells) {
+		return false
+	}
+
+	digits := maskToSlice(pair)
+	b.solvingStep("Unique Rectangle", "Found Unique Rectangle on %v in R%dC%d, R%dC%d, R%dC%d, R%dC%d: removing %v from R%dC%d",
+		digits, cells[0].row+1, cells[0].col+1, cells[1].row+1, cells[1].col+1,
+		cells[2].row+1, cells[2].col+1, cells[3].row+1, cells[3].col+1,
+		digits, cells[target].row+1, cells[target].col+1)
+
+	for _, digit := range digits {
+		cells[target].RemoveCandidate(digit)
+	}
+	b.log().Info("Unique Rectangle eliminated %d candidate(s) from R%dC%d",
+		bits.OnesCount16(pair), cells[target].row+1, cells[target].col+1)
+	return true
+}
+
+// isDeadlyRectangle reports whether swapping X and Y around the corners (listed row by row)
+// would keep every constraint satisfied. The corners must span exactly two boxes, and
+// every constraint containing a corner must be a unit holding exactly a row pair, a
+// column pair or all four corners, so a jigsaw region or a variant constraint such as a
+// killer cage or thermometer cannot tell the two solutions apart.
+func (b *Board) isDeadlyRectangle(cells [4]*Cell) bool {
+	boxes := make(map[int]bool)
+	for _, cell := range cells {
+		boxes[b.BoxIndex(cell.row, cell.col)] = true
+	}
+	if len(boxes) != 2 {
+		return false
+	}
+
+	seen := make(map[Constraint]bool)
+	for _, cell := range cells {
+		for _, constraint := range b.cellConstraints[cell.index] {
+			if seen[constraint] {
+				continue
+			}
+			seen[constraint] = true
+			if !b.isUnit(constraint) {
+				return false
+			}
+
+			// Bit i is set when the constraint contains corner i
+			held := 0
+			for i, corner := range cells {
+				for _, idx := range constraint.GetCells() {
+					if idx == corner.index {
+						held |= 1 << i
+						break
+					}
+				}
+			}
+			switch held {
+			case 0b0011, 0b1100, 0b0101, 0b1010, 0b1111:
+				// Row pair, column pair or the whole rectangle
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,416 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// cellState captures a cell's value and candidates so it can be restored later
+type cellState struct {
+	value      int
+	candidates uint16
+}
+
+// saveState captures the value and candidates of every cell
+func (b *Board) saveState() [81]cellState {
+	var state [81]cellState
+	for i, cell := range b.board {
+		if cell == nil {
+			continue
+		}
+		state[i] = cellState{value: cell.value, candidates: cell.candidates}
+	}
+	return state
+}
+
+// restoreState resets every cell to a previously saved state without notifying observers
+func (b *Board) restoreState(state [81]cellState) {
+	for i, cell := range b.board {
+		if cell == nil {
+			continue
+		}
+		cell.value = state[i].value
+		cell.candidates = state[i].candidates
+	}
+}
+
+// BoardState is a full-board checkpoint of every cell's value and candidate mask,
+// taken by Snapshot. It holds no pointers, so copying it copies the checkpoint.
+type BoardState struct {
+	cells [81]cellState
+}
+
+// Value returns the value the cell at index (0-80) had when the snapshot was taken
+func (s *BoardState) Value(index int) int {
+	if index < 0 || index > 80 {
+		return 0
+	}
+	return s.cells[index].value
+}
+
+// Snapshot captures the value and candidates of every cell. It is the exported form of
+// the checkpoint the search takes at every branch, for solvers and what-if analysis.
+func (b *Board) Snapshot() *BoardState {
+	return &BoardState{cells: b.saveState()}
+}
+
+// Restore resets every cell to a snapshot. It is a pure state reset: no observers are
+// notified and no constraint propagation runs, so candidates come back exactly as saved.
+// Constraints and settings of the board are not part of the snapshot.
+func (b *Board) Restore(s *BoardState) {
+	if s == nil {
+		b.log().Warn("Cannot restore a nil board state")
+		return
+	}
+	b.restoreState(s.cells)
+}
+
+// resetToValues clears the board and sets the given values again, so candidates
+// are rebuilt from scratch by constraint propagation
+func (b *Board) resetToValues(values [81]int) {
+	for _, cell := range b.board {
+		if cell == nil {
+			continue
+		}
+		cell.value = 0
+		cell.candidates = b.fullMask()
+	}
+	b.initializeConstraints()
+
+	for i, value := range values {
+		if value != 0 && b.board[i] != nil {
+			b.board[i].SetValue(value)
+		}
+	}
+}
+
+// values returns the current value of every cell
+func (b *Board) values() [81]int {
+	var values [81]int
+	for i, cell := range b.board {
+		if cell != nil {
+			values[i] = cell.value
+		}
+	}
+	return values
+}
+
+// copyConstraint makes a shallow copy of a constraint so it can be attached to another board.
+// Constraints only hold their configuration (cells, sums, ...) plus the board reference,
+// which AddConstraint replaces, so a copy of the struct is independent of the original.
+func copyConstraint(c Constraint) Constraint {
+	v := reflect.ValueOf(c)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return c
+	}
+
+	copied := reflect.New(v.Elem().Type())
+	copied.Elem().Set(v.Elem())
+	return copied.Interface().(Constraint)
+}
+
+// clone returns an independent copy of the board with the same values, candidates and
+// constraints. Observers are not carried over.
+func (b *Board) clone() *Board {
+	c := newBoard(b.size, b.boxRows, b.boxCols, true) // internal copies are what-if boards; keep their routine logs out
+	c.assumeUnique = b.assumeUnique
+	c.rng = b.rng
+	c.finnedFishSizes = b.finnedFishSizes
+	c.candidateOrder = b.candidateOrder
+
+	for _, constraint := range b.constraints {
+		c.AddConstraint(copyConstraint(constraint))
+	}
+	c.restoreState(b.saveState())
+	for i, cell := range b.board {
+		if cell != nil && c.board[i] != nil {
+			c.board[i].isGiven = cell.isGiven
+		}
+	}
+
+	return c
+}
+
+// Clone returns an independent copy of the board for what-if work such as rating or
+// generation: values, candidates, givens and solver settings are copied, and each
+// constraint is copied and registered as an observer of the clone's cells. Changing the
+// clone never affects this board. Observers, undo history and the random seed are not
+// carried over; call SetRandomSeed on the clone for reproducible results.
+func (b *Board) Clone() *Board {
+	c := b.clone()
+	c.rng = nil // no seeded generator: the clone seeds its own from the time unless SetRandomSeed is called
+	c.quiet = b.quiet
+	return c
+}
+
+// GivensOnly returns a new board holding only this board's given cells, with copies of
+// its constraints attached. Candidates are computed from the givens alone, so the result
+// is the original puzzle, ready to be solved again from scratch or shown next to the
+// solution. Observers are not carried over.
+func (b *Board) GivensOnly() *Board {
+	g := newBoard(b.size, b.boxRows, b.boxCols, b.quiet)
+	g.assumeUnique = b.assumeUnique
+	g.rng = b.rng
+	g.finnedFishSizes = b.finnedFishSizes
+	g.candidateOrder = b.candidateOrder
+
+	for _, constraint := range b.constraints {
+		g.AddConstraint(copyConstraint(constraint))
+	}
+
+	givens := 0
+	for i, cell := range b.board {
+		if cell == nil || !cell.IsGiven() || g.board[i] == nil {
+			continue
+		}
+		g.board[i].SetValue(cell.value)
+		g.board[i].isGiven = true
+		givens++
+	}
+
+	b.log().Info("Copied %d given(s) to a new board", givens)
+	return g
+}
+
+// SetRandomSeed seeds the random number generator used by puzzle generation,
+// making the results reproducible
+func (b *Board) SetRandomSeed(seed int64) {
+	b.rng = rand.New(rand.NewSource(seed))
+}
+
+// random returns the board's random number generator, creating a time-seeded one if needed
+func (b *Board) random() *rand.Rand {
+	if b.rng == nil {
+		b.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return b.rng
+}
+
+// search runs a depth-first search over the empty cells, always branching on the cell
+// with the fewest candidates. Every assignment goes through SetValue so constraints
+// propagate, and is checked against the constraints containing the cell. onSolution is
+// called for each completed grid and returns false to stop the search. The board is
+// restored to its original state after each branch.
+func (b *Board) search(ctx context.Context, onSolution func() bool) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return true, err
+	}
+
+	var target *Cell
+	for _, cell := range b.board {
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		if target == nil || cell.CandidateCount() < target.CandidateCount() {
+			target = cell
+		}
+	}
+
+	if target == nil {
+		// Complete grid - make sure every constraint agrees before accepting it
+		valid, err := b.ValidateAll()
+		if err != nil {
+			return true, err
+		}
+		if valid {
+			return !onSolution(), nil
+		}
+		return false, nil
+	}
+
+	candidates := maskToSlice(target.candidateMask())
+	if b.shuffleSearch {
+		b.random().Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+	}
+	for _, candidate := range candidates {
+		state := b.saveState()
+
+		target.SetValue(candidate)
+		valid, err := b.ValidateAffected(target.GetRow(), target.GetCol())
+		if err != nil {
+			b.restoreState(state)
+			return true, err
+		}
+
+		if valid {
+			stop, err := b.search(ctx, onSolution)
+			if stop || err != nil {
+				b.restoreState(state)
+				return true, err
+			}
+		}
+
+		b.restoreState(state)
+	}
+
+	return false, nil
+}
+
+// CountSolutions counts the distinct completed grids that satisfy every constraint,
+// stopping once limit is reached. Pass 2 to check uniqueness cheaply: 0 means
+// unsolvable, 1 unique and 2 at least two solutions. The board is left unchanged.
+func (b *Board) CountSolutions(limit int) (int, error) {
+	if limit < 1 {
+		return 0, &BoardError{Message: fmt.Sprintf("limit must be at least 1, got %d", limit)}
+	}
+
+	count := 0
+	var err error
+	work := b.clone()
+	_, err = work.search(context.Background(), func() bool {
+		count++
+		return count < limit
+	})
+
+	return count, err
+}
+
+// LikelyUnique runs the solution counter under a time budget. If the search finishes,
+// the answer is conclusive. If it times out, the result is a best guess: true when
+// exactly one solution had been found so far. A non-conclusive true is not a guarantee
+// of uniqueness, since a second solution may lie in the part of the search that never ran.
+func (b *Board) LikelyUnique(timeout time.Duration) (isLikelyUnique bool, conclusive bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	count := 0
+	var err error
+	work := b.clone()
+	_, err = work.search(ctx, func() bool {
+		count++
+		return count < 2
+	})
+
+	if err != nil {
+		b.log().Info("Uniqueness check timed out after %v with %d solution(s) found", timeout, count)
+		return count == 1, false
+	}
+	return count == 1, true
+}
+
+// MinimalClues returns a minimal puzzle derived from this board's solution: clues are
+// removed one at a time in random order, keeping each removal only if the puzzle still
+// has a unique solution. Removing any remaining clue would make the puzzle ambiguous.
+// The remaining clues are marked as givens. If the board is not complete it is solved
+// first; nil is returned if it has no solution. Use SetRandomSeed for a reproducible result.
+func (b *Board) MinimalClues() *Board {
+	b.log().Info("Computing minimal clue set...")
+
+	work := b.clone()
+
+	// Start from a full solution
+	solution, solved := work.firstSolution()
+	if !solved {
+		b.log().Warn("Board has no solution, cannot compute minimal clues")
+		return nil
+	}
+
+	work.resetToValues(work.removeClues(solution, false))
+	work.markGivens()
+	work.quiet = b.quiet
+	b.log().Info("Minimal puzzle has %d clues", work.clueCount())
+	return work
+}
+
+// markGivens marks every solved cell as a given and every empty cell as not given
+func (b *Board) markGivens() {
+	for _, cell := range b.board {
+		if cell != nil {
+			cell.isGiven = cell.IsSolved()
+		}
+	}
+}
+
+// GeneratePuzzle creates a new puzzle for this board's constraints: a random solution is
+// found by the backtracking search, then clues are removed in random order as long as
+// the solution stays unique, until no clue can be removed. With symmetrical set, clues
+// are removed in pairs mirrored through the center, keeping 180° rotational symmetry.
+// The remaining clues are marked as givens. Values already on the board are kept in the
+// solution. Use SetRandomSeed for a reproducible puzzle; nil is returned if the
+// constraints admit no solution.
+func (b *Board) GeneratePuzzle(symmetrical bool) *Board {
+	b.log().Info("Generating puzzle (symmetrical: %v)...", symmetrical)
+
+	work := b.clone()
+
+	work.shuffleSearch = true
+	solution, solved := work.firstSolution()
+	work.shuffleSearch = false
+	if !solved {
+		b.log().Warn("Constraints admit no solution, cannot generate a puzzle")
+		return nil
+	}
+
+	work.resetToValues(work.removeClues(solution, symmetrical))
+	work.markGivens()
+	work.quiet = b.quiet
+	b.log().Info("Generated puzzle has %d clues", work.clueCount())
+	return work
+}
+
+// firstSolution searches for one completed grid and returns its values
+func (b *Board) firstSolution() ([81]int, bool) {
+	solved := false
+	var solution [81]int
+	b.search(context.Background(), func() bool {
+		solution = b.values()
+		solved = true
+		return false
+	})
+	return solution, solved
+}
+
+// removeClues takes clues out of a solution in random order, keeping each removal only
+// if the puzzle still has exactly one solution. With symmetrical set, a cell and its
+// mirror through the center are removed together. Returns the remaining puzzle.
+func (b *Board) removeClues(solution [81]int, symmetrical bool) [81]int {
+	puzzle := solution
+	for _, idx := range b.random().Perm(81) {
+		group := []int{idx}
+		if symmetrical {
+			mirror := (b.size-1-idx/9)*9 + (b.size - 1 - idx%9)
+			if idx > mirror {
+				continue // Handled together with its mirror
+			}
+			if idx != mirror {
+				group = append(group, mirror)
+			}
+		}
+
+		removed := puzzle
+		for _, i := range group {
+			removed[i] = 0
+		}
+		if removed == puzzle {
+			continue
+		}
+
+		b.resetToValues(removed)
+		count := 0
+		b.search(context.Background(), func() bool {
+			count++
+			return count < 2
+		})
+
+		if count == 1 {
+			puzzle = removed // Still unique, the clues can go
+		}
+	}
+	return puzzle
+}
+
+// clueCount returns the number of filled cells
+func (b *Board) clueCount() int {
+	clues := 0
+	for _, value := range b.values() {
+		if value != 0 {
+			clues++
+		}
+	}
+	return clues
+}
@@ -0,0 +1,338 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+)
+
+// CellChange describes how a single cell changed during a solve step
+type CellChange struct {
+	Index      int   // Cell index (0-80)
+	Value      int   // Value placed in the cell, 0 if it was not solved by this step
+	Eliminated []int // Candidates removed from the cell, in ascending order
+}
+
+// SolveStep records one technique application that changed the board
+type SolveStep struct {
+	Technique string
+	Changes   []CellChange // Affected cells in index order
+}
+
+// SolveResult is the trace of a logical solve
+type SolveResult struct {
+	Steps []SolveStep
+
+	// Solved reports whether the solve reached its goal: the target cell for
+	// SolveUntilCell, otherwise a complete grid
+	Solved bool
+
+	// ComputerAssisted reports that logic stalled and a search finished the grid.
+	// The search is then the last step, with the technique name SearchTechnique.
+	ComputerAssisted bool
+}
+
+// SearchTechnique names the step in which a search filled the cells logic couldn't
+const SearchTechnique = "Search"
+
+// technique is one entry in the logical solving pipeline. apply makes a single
+// deduction (or one pass of the technique) and reports whether the board changed.
+type technique struct {
+	name  string
+	apply func(b *Board) bool
+}
+
+// techniques returns the logical pipeline in increasing order of difficulty.
+// Singles place exactly one value per application so a solve can stop right after
+// a given cell is set.
+func (b *Board) techniques() []technique {
+	pipeline := []technique{
+		{"Full House", (*Board).placeFullHouse},
+		{"Naked Single", (*Board).placeNakedSingle},
+		{"Hidden Single", (*Board).placeHiddenSingle},
+		{"Pencil Mark", (*Board).ApplyPencilMarkConstraints},
+		{"Intersection Removal", (*Board).ApplyIntersectionRemoval},
+		{"Cage Line Reduction", (*Board).applyCageLineReductions},
+		{"X-Wing", (*Board).applyXWings},
+		{"Swordfish", (*Board).applySwordfish},
+		{"Finned Fish", (*Board).applyFinnedFishes},
+		{"Simple Coloring", (*Board).applyColoring},
+		{"XY-Wing", (*Board).applyXYWings},
+		{"W-Wing", (*Board).applyWWings},
+		{"XYZ-Wing", (*Board).applyXYZWings},
+	}
+	if b.assumeUnique {
+		pipeline = append(pipeline,
+			technique{"Unique Rectangle", (*Board).applyUniqueRectangles},
+			technique{"BUG+1", (*Board).applyBUG})
+	}
+	return pipeline
+}
+
+// placeFullHouse fills the last empty cell of the first unit that has only one left
+func (b *Board) placeFullHouse() bool {
+	for _, constraint := range b.constraints {
+		idx, value, ok := b.LastDigitInUnit(constraint)
+		if !ok {
+			continue
+		}
+
+		cell := b.board[idx]
+		b.solvingStep("Full House", "R%dC%d is the last empty cell in %s and must be %d",
+			cell.GetRow()+1, cell.GetCol()+1, constraint.GetName(), value)
+		return cell.SetValue(value) == nil
+	}
+	return false
+}
+
+// placeNakedSingle sets the first unsolved cell that has a single candidate left
+func (b *Board) placeNakedSingle() bool {
+	for _, cell := range b.board {
+		if cell == nil || cell.IsSolved() || cell.CandidateCount() != 1 {
+			continue
+		}
+
+		value := maskToSlice(cell.candidateMask())[0]
+		b.solvingStep("Naked Single", "R%dC%d has only candidate %d",
+			cell.GetRow()+1, cell.GetCol()+1, value)
+		return cell.SetValue(value) == nil
+	}
+	return false
+}
+
+// placeHiddenSingle sets the first cell that is the only place for a digit in a unit
+func (b *Board) placeHiddenSingle() bool {
+	singles := b.HiddenSingleCandidates()
+	if len(singles) == 0 {
+		return false
+	}
+
+	single := singles[0]
+	cell := b.board[single.Index]
+	b.solvingStep("Hidden Single", "R%dC%d is the only place for %d in %s",
+		cell.GetRow()+1, cell.GetCol()+1, single.Value, single.Unit)
+	return cell.SetValue(single.Value) == nil
+}
+
+// diffState lists the cells that changed since the given state was saved
+func (b *Board) diffState(before [81]cellState) []CellChange {
+	changes := make([]CellChange, 0)
+	for idx, cell := range b.board {
+		if cell == nil {
+			continue
+		}
+
+		change := CellChange{Index: idx}
+		if before[idx].value == 0 && cell.value != 0 {
+			change.Value = cell.value
+		}
+		if cell.value == 0 {
+			if removed := before[idx].candidates &^ cell.candidates; removed != 0 {
+				change.Eliminated = maskToSlice(removed)
+			}
+		}
+
+		if change.Value != 0 || len(change.Eliminated) > 0 {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// solveLogically applies the technique pipeline, always restarting from the easiest
+// technique after a change, until done reports true or no technique makes progress.
+// Progress is measured by TotalCandidates rather than the techniques' own reports.
+func (b *Board) solveLogically(done func() bool) SolveResult {
+	result := SolveResult{Steps: make([]SolveStep, 0)}
+
+	for !done() {
+		progress := false
+		for _, t := range b.techniques() {
+			before := b.saveState()
+			count := b.TotalCandidates()
+			b.notifyStep(t.name, "")
+			if !t.apply(b) || b.TotalCandidates() == count {
+				continue
+			}
+
+			changes := b.diffState(before)
+
+			result.Steps = append(result.Steps, SolveStep{Technique: t.name, Changes: changes})
+			progress = true
+			break
+		}
+
+		if !progress {
+			b.log().Info("Logical solving stalled after %d step(s)", len(result.Steps))
+			b.notifyStep("", "")
+			b.lastSolve = result
+			return result
+		}
+	}
+
+	b.notifyStep("", "")
+	result.Solved = true
+	b.lastSolve = result
+	return result
+}
+
+// StepCount returns how many technique applications the most recent logical solve
+// (Solve, SolveHybrid or SolveUntilCell) needed. Only steps that changed the board are
+// counted, and the search that may finish SolveHybrid is not a logical step. Together
+// with the techniques used it gives a measure of difficulty. Returns 0 before any solve.
+func (b *Board) StepCount() int {
+	count := 0
+	for _, step := range b.lastSolve.Steps {
+		if len(step.Changes) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// SolveUntilCell runs logical techniques only until the cell at (row, col) has a value,
+// and returns the trace of steps that led there. Values are placed one cell at a time,
+// so solving stops right after the target is set. If logic stalls first, the result is
+// returned with Solved set to false.
+func (b *Board) SolveUntilCell(row, col int) (SolveResult, error) {
+	if row < 0 || row >= b.size || col < 0 || col >= b.size {
+		b.log().Error("Invalid board position: row=%d, col=%d", row, col)
+		return SolveResult{}, &BoardError{Message: fmt.Sprintf("invalid position: row=%d, col=%d", row, col)}
+	}
+
+	target := b.GetCellAt(row, col)
+	if target == nil {
+		return SolveResult{}, &BoardError{Message: fmt.Sprintf("no cell at R%dC%d", row+1, col+1)}
+	}
+
+	b.log().Info("Solving until R%dC%d is set...", row+1, col+1)
+	result := b.solveLogically(target.IsSolved)
+
+	if result.Solved {
+		b.log().Info("R%dC%d = %d after %d step(s)", row+1, col+1, target.GetValue(), len(result.Steps))
+	}
+	return result, nil
+}
+
+// isComplete reports whether every cell has a value
+func (b *Board) isComplete() bool {
+	for _, cell := range b.board {
+		if cell != nil && !cell.IsSolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// SolveLogically solves as far as logic allows, without guessing. Techniques are tried
+// in a fixed order - full houses, naked and hidden singles, pencil mark subsets,
+// intersection removal, then the advanced techniques - restarting from the top after
+// every change, until the grid is complete or nothing makes progress. Singles are
+// placed on the board. iterations is the number of technique applications that
+// changed the board, and the trace is kept for StepCount.
+func (b *Board) SolveLogically() (solved bool, iterations int) {
+	b.log().Info("Solving board logically...")
+	result := b.solveLogically(b.isComplete)
+	if result.Solved {
+		b.log().Info("Board solved logically in %d step(s)", len(result.Steps))
+	}
+	return result.Solved, len(result.Steps)
+}
+
+// Solve completes the board. The logical pipeline runs first; if it stalls, a
+// depth-first search takes over, branching on the cell with the fewest candidates and
+// checking every constraint containing each assigned cell, so variant constraints such
+// as killer cages and Renban lines are respected. On success the board is left solved.
+// If there is no solution, the board is restored to its original state and false is returned.
+func (b *Board) Solve() (bool, error) {
+	result, err := b.SolveHybrid()
+	return result.Solved, err
+}
+
+// SolveHybrid solves the board with human-style techniques first, for a readable trace,
+// and finishes with a search only if logic stalls. The fallback is the same backtracking
+// search as Solve, not an exact-cover solver; it runs on a copy of the board, so this
+// board and its observers only see the final values. When it runs, ComputerAssisted is set
+// and the final step, named SearchTechnique, lists the cells the search filled in, so
+// the trace shows exactly where logic ended. If there is no solution, the board is
+// restored and the result holds the logical steps that were tried with Solved false.
+func (b *Board) SolveHybrid() (SolveResult, error) {
+	return b.solveHybrid(context.Background())
+}
+
+// SolveContext is Solve with cancellation: the search checks ctx at every node it expands,
+// so a deadline or cancel stops even a deep search promptly. If ctx ends first, the board
+// is restored to its original state and ctx.Err() is returned.
+func (b *Board) SolveContext(ctx context.Context) (bool, error) {
+	result, err := b.solveHybrid(ctx)
+	return result.Solved, err
+}
+
+// solveHybrid implements SolveHybrid, with a context for the search
+func (b *Board) solveHybrid(ctx context.Context) (SolveResult, error) {
+	b.log().Info("Solving board...")
+	original := b.saveState()
+	if err := ctx.Err(); err != nil {
+		return SolveResult{Steps: make([]SolveStep, 0)}, err
+	}
+
+	result := b.solveLogically(b.isComplete)
+	if result.Solved {
+		valid, err := b.ValidateAll()
+		if err != nil || !valid {
+			b.log().Warn("Logical solve produced an invalid grid, restoring the original board")
+			b.restoreState(original)
+			result.Solved = false
+			return result, err
+		}
+		b.log().Info("Board solved logically in %d step(s)", len(result.Steps))
+		return result, nil
+	}
+
+	b.log().Info("Falling back to search after %d logical step(s)", len(result.Steps))
+	before := b.saveState()
+
+	// Search a copy: trial values would otherwise reach this board's observers and any
+	// linked boards, which never see the rollbacks the same way
+	found := false
+	var solution [81]int
+	var err error
+	work := b.clone()
+	_, err = work.search(ctx, func() bool {
+		solution = work.values()
+		found = true
+		return false
+	})
+
+	if err != nil && ctx.Err() != nil {
+		b.log().Warn("Search cancelled (%v), restoring the original board", err)
+		b.restoreState(original)
+		return result, err
+	}
+	if err != nil || !found {
+		b.log().Warn("Board has no solution, restoring the original board")
+		b.restoreState(original)
+		return result, err
+	}
+
+	// Only the final solution is applied to this board
+	b.notifyStep(SearchTechnique, "Logic stalled, values filled in by search")
+	for idx, cell := range b.board {
+		if cell == nil || cell.IsSolved() {
+			continue
+		}
+		if err := cell.SetValue(solution[idx]); err != nil {
+			b.notifyStep("", "")
+			b.log().Warn("Applying the search solution failed (%v), restoring the original board", err)
+			b.restoreState(original)
+			return result, err
+		}
+	}
+	b.notifyStep("", "")
+
+	result.Steps = append(result.Steps, SolveStep{Technique: SearchTechnique, Changes: b.diffState(before)})
+	result.Solved = true
+	result.ComputerAssisted = true
+
+	b.log().Info("Board solved with search after %d logical step(s)", len(result.Steps)-1)
+	return result, nil
+}
@@ -24,13 +24,16 @@ func GenerateCombinations(n, k int) [][]int {
 		return [][]int{}
 	}
 
-	result := make([][]int, 0)
+	// All combinations share one backing array to keep allocations down
+	count := Binomial(n, k)
+	result := make([][]int, 0, count)
+	backing := make([]int, count*k)
 	combination := make([]int, k)
 
 	var generate func(start, depth int)
 	generate = func(start, depth int) {
 		if depth == k {
-			temp := make([]int, k)
+			temp := backing[len(result)*k : (len(result)+1)*k : (len(result)+1)*k]
 			copy(temp, combination)
 			result = append(result, temp)
 			return
@@ -46,6 +49,18 @@ func GenerateCombinations(n, k int) [][]int {
 	return result
 }
 
+// Binomial returns the number of ways to choose k items from n
+func Binomial(n, k int) int {
+	if k < 0 || k > n {
+		return 0
+	}
+	result := 1
+	for i := 1; i <= k; i++ {
+		result = result * (n - k + i) / i
+	}
+	return result
+}
+
 // ContainsInt checks if an int is in a slice of ints
 func ContainsInt(slice []int, target int) bool {
 	for _, val := range slice {
@@ -88,7 +103,8 @@ func GetBoxCoordinates(box int) (startRow, startCol int) {
 	return (box / 3) * 3, (box % 3) * 3
 }
 
-// GetCandidatesAsSlice converts a candidate map to a sorted slice
+// GetCandidatesAsSlice converts a candidate map to a sorted slice. For a cell's own
+// candidates, Cell.SortedCandidates does the same without building the map.
 func GetCandidatesAsSlice(candidates map[int]bool) []int {
 	result := make([]int, 0, len(candidates))
 	for i := 1; i <= 9; i++ {
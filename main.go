@@ -30,24 +30,8 @@ func main() {
 
 	// Add standard sudoku constraints (rows, columns, boxes)
 	logger.Info("Adding standard Sudoku constraints...")
-	for i := 0; i < 9; i++ {
-		rowConstraint, err := constraints.NewRowConstraint(i)
-		if err != nil {
-			log.Fatalf("Failed to create row constraint: %v", err)
-		}
-		board.AddConstraint(rowConstraint)
-
-		colConstraint, err := constraints.NewColumnConstraint(i)
-		if err != nil {
-			log.Fatalf("Failed to create column constraint: %v", err)
-		}
-		board.AddConstraint(colConstraint)
-
-		boxConstraint, err := constraints.NewBoxConstraint(i)
-		if err != nil {
-			log.Fatalf("Failed to create box constraint: %v", err)
-		}
-		board.AddConstraint(boxConstraint)
+	if err := constraints.AddStandardConstraints(board); err != nil {
+		log.Fatalf("Failed to add standard constraints: %v", err)
 	}
 
 	fmt.Println("\n=== Example 1: Standard Sudoku ===")
@@ -95,13 +79,8 @@ func main() {
 	variantBoard.AddObserver(autoSolver2)
 
 	// Add standard constraints
-	for i := 0; i < 9; i++ {
-		rc, _ := constraints.NewRowConstraint(i)
-		variantBoard.AddConstraint(rc)
-		cc, _ := constraints.NewColumnConstraint(i)
-		variantBoard.AddConstraint(cc)
-		bc, _ := constraints.NewBoxConstraint(i)
-		variantBoard.AddConstraint(bc)
+	if err := constraints.AddStandardConstraints(variantBoard); err != nil {
+		log.Fatalf("Failed to add standard constraints: %v", err)
 	}
 
 	// Add a killer cage constraint (cells in top-left that sum to 15)
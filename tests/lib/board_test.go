@@ -1,10 +1,16 @@
 package lib_test
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/eftil/sudoku-solver.git/lib"
 	"github.com/eftil/sudoku-solver.git/lib/constraints"
+	"github.com/eftil/sudoku-solver.git/lib/logger"
+	"github.com/eftil/sudoku-solver.git/lib/utils"
 )
 
 func TestBoardSetGet(t *testing.T) {
@@ -120,6 +126,54 @@ func TestBoardGetBoxMiddle(t *testing.T) {
 	}
 }
 
+func TestBoardUnitCells(t *testing.T) {
+	board := lib.NewBoard()
+	small, err := lib.NewBoardOfSize(6)
+	if err != nil {
+		t.Fatalf("NewBoardOfSize(6) returned error: %v", err)
+	}
+
+	indices := func(cells []*lib.Cell) []int {
+		if cells == nil {
+			return nil
+		}
+		result := make([]int, len(cells))
+		for i, cell := range cells {
+			result[i] = cell.GetIndex()
+		}
+		return result
+	}
+
+	tests := []struct {
+		name  string
+		cells []*lib.Cell
+		want  []int
+	}{
+		{"row 2", board.RowCells(1), []int{9, 10, 11, 12, 13, 14, 15, 16, 17}},
+		{"column 9", board.ColCells(8), []int{8, 17, 26, 35, 44, 53, 62, 71, 80}},
+		{"middle box", board.BoxCells(4), []int{30, 31, 32, 39, 40, 41, 48, 49, 50}},
+		{"6x6 row", small.RowCells(5), []int{45, 46, 47, 48, 49, 50}},
+		{"6x6 box", small.BoxCells(3), []int{21, 22, 23, 30, 31, 32}},
+		{"row out of range", board.RowCells(9), nil},
+		{"column out of range", board.ColCells(-1), nil},
+		{"6x6 box out of range", small.BoxCells(6), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := indices(tt.cells); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got cells %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The cells are the board's own, not copies
+	board.RowCells(0)[4].RemoveCandidate(3)
+	if board.GetCellAt(0, 4).HasCandidate(3) {
+		t.Error("RowCells should return the board's cells")
+	}
+}
+
 func TestBoardAddConstraint(t *testing.T) {
 	board := lib.NewBoard()
 
@@ -136,6 +190,43 @@ func TestBoardAddConstraint(t *testing.T) {
 	}
 }
 
+// initConstraint records what it saw when Initialize was called
+type initConstraint struct {
+	lib.BaseConstraint
+	calls     int
+	sawBoard  bool
+	observing bool
+}
+
+func (ic *initConstraint) IsValid(board *lib.Board) (bool, error) { return true, nil }
+func (ic *initConstraint) GetDescription() string                 { return "records Initialize calls" }
+
+func (ic *initConstraint) Initialize(board *lib.Board) {
+	ic.calls++
+	ic.sawBoard = ic.Board == board
+	ic.observing = board.GetCell(0).GetNotifier().HasObservers()
+	board.GetCell(0).RemoveCandidate(9)
+}
+
+func TestBoardAddConstraintInitialize(t *testing.T) {
+	board := lib.NewBoard()
+	ic := &initConstraint{BaseConstraint: lib.BaseConstraint{Cells: []int{0}, Name: "Init"}}
+	board.AddConstraint(ic)
+
+	if ic.calls != 1 || !ic.sawBoard || !ic.observing {
+		t.Errorf("Initialize should run once after the board is set and observers are registered, got %+v", ic)
+	}
+	if board.GetCell(0).HasCandidate(9) {
+		t.Error("candidates removed by Initialize should stay removed")
+	}
+
+	// Rebuilding candidates runs it again
+	board.RecomputeAllCandidates()
+	if ic.calls != 2 || board.GetCell(0).HasCandidate(9) {
+		t.Errorf("RecomputeAllCandidates() should run Initialize again, got %d call(s)", ic.calls)
+	}
+}
+
 func TestBoardValidateAll(t *testing.T) {
 	board := lib.NewBoard()
 
@@ -246,6 +337,44 @@ func TestBoardValidateAllMultipleConstraints(t *testing.T) {
 	}
 }
 
+func TestBoardValidateAffected(t *testing.T) {
+	board := lib.NewBoard()
+
+	// Add all standard sudoku constraints
+	for i := 0; i < 9; i++ {
+		rc, _ := constraints.NewRowConstraint(i)
+		board.AddConstraint(rc)
+		cc, _ := constraints.NewColumnConstraint(i)
+		board.AddConstraint(cc)
+		bc, _ := constraints.NewBoxConstraint(i)
+		board.AddConstraint(bc)
+	}
+
+	board.Set(0, 0, 5)
+	board.Set(0, 4, 5) // duplicate 5 in row 0
+
+	valid, err := board.ValidateAffected(0, 4)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if valid {
+		t.Error("cell in a violated row should fail affected validation")
+	}
+
+	// R5C5 is not in row 1, so its constraints are still satisfied
+	valid, err = board.ValidateAffected(4, 4)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !valid {
+		t.Error("R5C5 constraints are unaffected by the duplicate and should pass")
+	}
+
+	if _, err := board.ValidateAffected(9, 0); err == nil {
+		t.Error("ValidateAffected with invalid position should return error")
+	}
+}
+
 func TestBoardApplyPencilMarkConstraints(t *testing.T) {
 	board := lib.NewBoard()
 
@@ -289,6 +418,41 @@ func TestBoardApplyPencilMarkConstraintsUntilStable(t *testing.T) {
 	}
 }
 
+func TestBoardApplyPencilMarkConstraintsUntilStableN(t *testing.T) {
+	reference := newStandardBoard(t)
+	setGrid(t, reference, easyPuzzle)
+	stable := reference.ApplyPencilMarkConstraintsUntilStable()
+	if stable < 2 {
+		t.Fatalf("expected the puzzle to need at least 2 iterations, got %d", stable)
+	}
+
+	tests := []struct {
+		name          string
+		maxIterations int
+		want          int
+	}{
+		{"capped after one iteration", 1, 1},
+		{"cap equal to the iterations needed", stable, stable},
+		{"cap above the iterations needed", stable + 10, stable},
+		{"zero cap", 0, 0},
+		{"negative cap", -1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			setGrid(t, board, easyPuzzle)
+
+			if got := board.ApplyPencilMarkConstraintsUntilStableN(tt.maxIterations); got != tt.want {
+				t.Errorf("ApplyPencilMarkConstraintsUntilStableN(%d) = %d, want %d", tt.maxIterations, got, tt.want)
+			}
+			if tt.want == stable && board.CandidatesString() != reference.CandidatesString() {
+				t.Error("a cap that isn't hit should reach the same state as the uncapped loop")
+			}
+		})
+	}
+}
+
 func TestBoardApplyAdvancedTechniques(t *testing.T) {
 	board := lib.NewBoard()
 
@@ -365,6 +529,182 @@ func TestBoardGetCell(t *testing.T) {
 	}
 }
 
+func TestBoardForEachCell(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, easyPuzzle)
+
+	visited := 0
+	board.ForEachCell(func(row, col int, cell *lib.Cell) {
+		if cell.GetIndex() != visited || cell.GetRow() != row || cell.GetCol() != col {
+			t.Errorf("cell %d visited as R%dC%d, want row-major order", cell.GetIndex(), row+1, col+1)
+		}
+		visited++
+	})
+	if visited != 81 {
+		t.Errorf("ForEachCell() visited %d cells, want 81", visited)
+	}
+
+	unsolved := 0
+	board.ForEachUnsolved(func(cell *lib.Cell) {
+		if cell.IsSolved() {
+			t.Errorf("ForEachUnsolved() visited solved cell %d", cell.GetIndex())
+		}
+		unsolved++
+	})
+	if want := strings.Count(easyPuzzle, "0"); unsolved != want {
+		t.Errorf("ForEachUnsolved() visited %d cells, want %d", unsolved, want)
+	}
+}
+
+func TestBoardEmptyAndSolvedCells(t *testing.T) {
+	tests := []struct {
+		name string
+		grid string
+	}{
+		{"empty grid", strings.Repeat("0", 81)},
+		{"partial grid", easyPuzzle},
+		{"complete grid", bugPlusOneSolution},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			setGrid(t, board, tt.grid)
+
+			var wantEmpty, wantSolved []int
+			for i, ch := range tt.grid {
+				if ch == '0' {
+					wantEmpty = append(wantEmpty, i)
+				} else {
+					wantSolved = append(wantSolved, i)
+				}
+			}
+
+			indices := func(cells []*lib.Cell) []int {
+				var result []int
+				for _, cell := range cells {
+					result = append(result, cell.GetIndex())
+				}
+				return result
+			}
+			if got := indices(board.GetEmptyCells()); !reflect.DeepEqual(got, wantEmpty) {
+				t.Errorf("GetEmptyCells() = %v, want %v", got, wantEmpty)
+			}
+			if got := indices(board.GetSolvedCells()); !reflect.DeepEqual(got, wantSolved) {
+				t.Errorf("GetSolvedCells() = %v, want %v", got, wantSolved)
+			}
+			if got := board.EmptyCount(); got != len(wantEmpty) {
+				t.Errorf("EmptyCount() = %d, want %d", got, len(wantEmpty))
+			}
+		})
+	}
+}
+
+func TestNewBoardOfSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		solution  string
+		pretty    string // First two lines of PrettyString
+		shouldErr bool
+	}{
+		{"4x4", 4, "1234341221434321", "+--+--+\n|12|34|\n", false},
+		{"6x6", 6, "123456456123231564564231312645645312", "+---+---+\n|123|456|\n", false},
+		{"9x9", 9, bugPlusOneSolution, "+---+---+---+\n|926|817|435|\n", false},
+		{"unsupported size", 5, "", "", true},
+		{"zero", 0, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := lib.NewBoardOfSize(tt.size)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := constraints.AddStandardConstraints(board); err != nil {
+				t.Fatalf("AddStandardConstraints() returned error: %v", err)
+			}
+			if got := len(board.GetConstraints()); got != 3*tt.size {
+				t.Errorf("got %d standard constraints, want %d", got, 3*tt.size)
+			}
+			var digits []int
+			for digit := 1; digit <= tt.size; digit++ {
+				digits = append(digits, digit)
+			}
+			if got := board.GetCell(0).CandidateSlice(); !reflect.DeepEqual(got, digits) {
+				t.Errorf("candidates = %v, want %v", got, digits)
+			}
+
+			if err := board.Set(0, tt.size, 1); err == nil {
+				t.Error("expected error for a column outside the board, got none")
+			}
+			if tt.size < 9 {
+				if err := board.Set(0, 0, tt.size+1); err == nil {
+					t.Errorf("expected error for value %d, got none", tt.size+1)
+				}
+			}
+
+			// Blank the first row and last column, then solve back to the solution
+			puzzle := []byte(tt.solution)
+			for i := range puzzle {
+				if i < tt.size || i%tt.size == tt.size-1 {
+					puzzle[i] = '.'
+				}
+			}
+			if err := board.LoadString(string(puzzle)); err != nil {
+				t.Fatalf("LoadString() returned error: %v", err)
+			}
+			solved, err := board.Solve()
+			if err != nil || !solved {
+				t.Fatalf("Solve() = (%v, %v), want solved", solved, err)
+			}
+			if board.String() != tt.solution {
+				t.Errorf("String() = %s, want %s", board.String(), tt.solution)
+			}
+			if valid, err := board.ValidateAll(); err != nil || !valid {
+				t.Errorf("ValidateAll() = (%v, %v), want valid", valid, err)
+			}
+			if got := board.PrettyString(); !strings.HasPrefix(got, tt.pretty) {
+				t.Errorf("PrettyString() starts with %q, want %q", got[:len(tt.pretty)], tt.pretty)
+			}
+		})
+	}
+}
+
+func TestBoardBoxIndex(t *testing.T) {
+	tests := []struct {
+		size     int
+		row, col int
+		want     int
+	}{
+		{4, 1, 2, 1},
+		{4, 3, 3, 3},
+		{6, 1, 4, 1},
+		{6, 2, 0, 2},
+		{6, 5, 3, 5},
+		{9, 4, 4, 4},
+		{9, 8, 0, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%dx%d R%dC%d", tt.size, tt.size, tt.row+1, tt.col+1), func(t *testing.T) {
+			board, err := lib.NewBoardOfSize(tt.size)
+			if err != nil {
+				t.Fatalf("NewBoardOfSize() returned error: %v", err)
+			}
+			if got := board.BoxIndex(tt.row, tt.col); got != tt.want {
+				t.Errorf("BoxIndex(%d, %d) = %d, want %d", tt.row, tt.col, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestBoardInvalidPosition(t *testing.T) {
 	board := lib.NewBoard()
 
@@ -426,3 +766,1794 @@ func TestBoardPrint(t *testing.T) {
 	// This function prints to stdout, we just verify it doesn't crash
 	board.Print()
 }
+
+func TestBoardPrettyString(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, easyPuzzle)
+
+	want := `+---+---+---+
+|53.|.7.|...|
+|6..|195|...|
+|.98|...|.6.|
++---+---+---+
+|8..|.6.|..3|
+|4..|8.3|..1|
+|7..|.2.|..6|
++---+---+---+
+|.6.|...|28.|
+|...|419|..5|
+|...|.8.|.79|
++---+---+---+
+`
+	if got := board.PrettyString(); got != want {
+		t.Errorf("PrettyString() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBoardCandidatesString(t *testing.T) {
+	board := lib.NewBoard()
+	rc, _ := constraints.NewRowConstraint(0)
+	board.AddConstraint(rc)
+
+	for col := 0; col < 7; col++ {
+		board.Set(0, col, col+1)
+	}
+
+	dump := board.CandidatesString()
+	lines := strings.Split(strings.TrimSuffix(dump, "\n"), "\n")
+
+	// 2 unsolved cells in row 1 plus the 72 cells of the other rows
+	if len(lines) != 74 {
+		t.Fatalf("expected 74 lines, got %d", len(lines))
+	}
+	if lines[0] != "7 R1C8: [8 9]" {
+		t.Errorf("first line = %q, want %q", lines[0], "7 R1C8: [8 9]")
+	}
+	if lines[2] != "9 R2C1: [1 2 3 4 5 6 7 8 9]" {
+		t.Errorf("third line = %q, want %q", lines[2], "9 R2C1: [1 2 3 4 5 6 7 8 9]")
+	}
+
+	if board.CandidatesString() != dump {
+		t.Error("CandidatesString should be deterministic")
+	}
+}
+
+func TestBoardPencilMarkString(t *testing.T) {
+	board := lib.NewBoard()
+	rc, _ := constraints.NewRowConstraint(0)
+	board.AddConstraint(rc)
+	for col := 0; col < 7; col++ {
+		board.Set(0, col, col+1)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(board.PencilMarkString(), "\n"), "\n")
+	if len(lines) != 37 {
+		t.Fatalf("expected 37 lines (9 rows of 3 plus 10 borders), got %d", len(lines))
+	}
+	for i, line := range lines {
+		if len(line) != 39 {
+			t.Errorf("line %d has %d characters, want 39: %q", i, len(line), line)
+		}
+	}
+
+	// R1C1 is solved, R1C8 has candidates 8 and 9 left, R2C1 has all nine
+	want := []string{
+		"+===+===+===++",
+		"|   |   |   ||   |   |   ||   |   |   |",
+		"|(1)|(2)|(3)||(4)|(5)|(6)||(7)|   |   |",
+		"|   |   |   ||   |   |   ||   | 89| 89|",
+		"+---+---+---++",
+		"|123|123|123||",
+		"|456|456|456||",
+		"|789|789|789||",
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("line %d = %q, want prefix %q", i, lines[i], prefix)
+		}
+	}
+
+	// Slots follow the display order
+	board.SetCandidateOrder([]int{9, 8, 7, 6, 5, 4, 3, 2, 1})
+	lines = strings.Split(board.PencilMarkString(), "\n")
+	if !strings.HasSuffix(lines[1], "||   |98 |98 |") {
+		t.Errorf("R1C7-9 top line = %q, want candidates 9 and 8 first", lines[1])
+	}
+}
+
+func TestBoardSetCandidateOrder(t *testing.T) {
+	board := lib.NewBoard()
+	cell := board.GetCellAt(0, 0)
+	cell.RemoveCandidate(5)
+
+	layout := []int{7, 8, 9, 4, 5, 6, 1, 2, 3}
+	if err := board.SetCandidateOrder(layout); err != nil {
+		t.Fatalf("SetCandidateOrder() returned error: %v", err)
+	}
+	want := "[7 8 9 4 6 1 2 3]"
+	if got := fmt.Sprint(cell.CandidateSlice()); got != want {
+		t.Errorf("CandidateSlice() = %s, want %s", got, want)
+	}
+	if line := strings.SplitN(board.CandidatesString(), "\n", 2)[0]; line != "0 R1C1: "+want {
+		t.Errorf("CandidatesString() first line = %q, want %q", line, "0 R1C1: "+want)
+	}
+
+	if err := board.SetCandidateOrder(nil); err != nil {
+		t.Fatalf("SetCandidateOrder(nil) returned error: %v", err)
+	}
+	if got := fmt.Sprint(cell.CandidateSlice()); got != "[1 2 3 4 6 7 8 9]" {
+		t.Errorf("CandidateSlice() after reset = %s, want ascending", got)
+	}
+
+	invalid := [][]int{
+		{1, 2, 3},
+		{1, 1, 2, 3, 4, 5, 6, 7, 8},
+		{0, 1, 2, 3, 4, 5, 6, 7, 8},
+	}
+	for _, order := range invalid {
+		if err := board.SetCandidateOrder(order); err == nil {
+			t.Errorf("SetCandidateOrder(%v) should return an error", order)
+		}
+	}
+}
+
+func TestBoardCageLineReduction(t *testing.T) {
+	// A 17 cage on R1C1-R1C2 must hold 8 and 9, both confined to row 1
+	board := newStandardBoard(t)
+	cage, err := constraints.NewKillerCageConstraint([]int{0, 1}, 17)
+	if err != nil {
+		t.Fatalf("NewKillerCageConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(cage)
+
+	if !board.ApplyAdvancedTechniques() {
+		t.Fatal("ApplyAdvancedTechniques() should find the cage line reduction")
+	}
+
+	for col := 2; col < 9; col++ {
+		cell := board.GetCellAt(0, col)
+		if cell.HasCandidate(8) || cell.HasCandidate(9) {
+			t.Errorf("R1C%d should lose 8 and 9, has %v", col+1, cell.CandidateSlice())
+		}
+	}
+	for _, idx := range []int{0, 1, 9, 10} {
+		cell := board.GetCell(idx)
+		if !cell.HasCandidate(8) || !cell.HasCandidate(9) {
+			t.Errorf("R%dC%d should keep 8 and 9, has %v", idx/9+1, idx%9+1, cell.CandidateSlice())
+		}
+	}
+}
+
+func TestBoardWWing(t *testing.T) {
+	board := newStandardBoard(t)
+
+	// R1C1 and R5C3 are both {1,2} and don't see each other
+	for candidate := 3; candidate <= 9; candidate++ {
+		board.GetCellAt(0, 0).RemoveCandidate(candidate)
+		board.GetCellAt(4, 2).RemoveCandidate(candidate)
+	}
+	// Row 9 has 1 only in R9C1 (sees R1C1) and R9C3 (sees R5C3)
+	for col := 0; col < 9; col++ {
+		if col != 0 && col != 2 {
+			board.GetCellAt(8, col).RemoveCandidate(1)
+		}
+	}
+
+	if !board.ApplyAdvancedTechniques() {
+		t.Fatal("ApplyAdvancedTechniques() should find the W-Wing")
+	}
+
+	for _, pos := range [][2]int{{0, 2}, {1, 2}, {2, 2}, {3, 0}, {4, 0}, {5, 0}} {
+		if board.GetCellAt(pos[0], pos[1]).HasCandidate(2) {
+			t.Errorf("R%dC%d sees both wings and should lose 2", pos[0]+1, pos[1]+1)
+		}
+	}
+	for _, pos := range [][2]int{{0, 0}, {4, 2}, {0, 1}, {8, 0}} {
+		if !board.GetCellAt(pos[0], pos[1]).HasCandidate(2) {
+			t.Errorf("R%dC%d should keep 2", pos[0]+1, pos[1]+1)
+		}
+	}
+}
+
+func TestBoardSimpleColoring(t *testing.T) {
+	tests := []struct {
+		name  string
+		keep  map[string][][2]int // unit -> the only cells keeping 1 in it
+		lose  [][2]int
+		stays [][2]int
+	}{
+		{
+			// R1C1 -row- R1C5 -box- R3C6 -column- R8C6: R8C1 sees both colors
+			name: "color trap",
+			keep: map[string][][2]int{
+				"row 1":    {{0, 0}, {0, 4}},
+				"box 2":    {{0, 4}, {2, 5}},
+				"column 6": {{2, 5}, {7, 5}},
+			},
+			lose:  [][2]int{{7, 0}},
+			stays: [][2]int{{0, 0}, {0, 4}, {2, 5}, {7, 5}, {7, 1}, {1, 0}},
+		},
+		{
+			// R1C1 -row- R1C5 -column- R5C5 -row- R5C2 -column- R2C2: R1C1 and R2C2 share
+			// a color and a box, so that color is false
+			name: "color wrap",
+			keep: map[string][][2]int{
+				"row 1":    {{0, 0}, {0, 4}},
+				"column 5": {{0, 4}, {4, 4}},
+				"row 5":    {{4, 4}, {4, 1}},
+				"column 2": {{4, 1}, {1, 1}},
+			},
+			lose:  [][2]int{{0, 0}, {4, 4}, {1, 1}},
+			stays: [][2]int{{0, 4}, {4, 1}},
+		},
+	}
+
+	unitCells := func(unit string) [][2]int {
+		var kind string
+		var n int
+		fmt.Sscanf(unit, "%s %d", &kind, &n)
+		cells := make([][2]int, 0, 9)
+		for i := 0; i < 9; i++ {
+			switch kind {
+			case "row":
+				cells = append(cells, [2]int{n - 1, i})
+			case "column":
+				cells = append(cells, [2]int{i, n - 1})
+			case "box":
+				cells = append(cells, [2]int{(n-1)/3*3 + i/3, (n-1)%3*3 + i%3})
+			}
+		}
+		return cells
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			// The chains also form finned X-Wings, which would break them first
+			if err := board.SetFinnedFishSizes(); err != nil {
+				t.Fatalf("SetFinnedFishSizes() returned error: %v", err)
+			}
+			for unit, keep := range tt.keep {
+				for _, pos := range unitCells(unit) {
+					if pos != keep[0] && pos != keep[1] {
+						board.GetCellAt(pos[0], pos[1]).RemoveCandidate(1)
+					}
+				}
+			}
+
+			if !board.ApplyAdvancedTechniques() {
+				t.Fatal("ApplyAdvancedTechniques() should find the coloring elimination")
+			}
+
+			for _, pos := range tt.lose {
+				if board.GetCellAt(pos[0], pos[1]).HasCandidate(1) {
+					t.Errorf("R%dC%d should lose 1", pos[0]+1, pos[1]+1)
+				}
+			}
+			for _, pos := range tt.stays {
+				if !board.GetCellAt(pos[0], pos[1]).HasCandidate(1) {
+					t.Errorf("R%dC%d should keep 1", pos[0]+1, pos[1]+1)
+				}
+			}
+		})
+	}
+}
+
+func TestBoardXYZWing(t *testing.T) {
+	board := newStandardBoard(t)
+
+	restrict := func(row, col int, keep ...int) {
+		cell := board.GetCellAt(row, col)
+		for candidate := 1; candidate <= 9; candidate++ {
+			if !utils.ContainsInt(keep, candidate) {
+				cell.RemoveCandidate(candidate)
+			}
+		}
+	}
+
+	// Pivot R1C1 {1,2,3}, wings R1C5 {1,3} in the same row and R2C2 {2,3} in the same box
+	restrict(0, 0, 1, 2, 3)
+	restrict(0, 4, 1, 3)
+	restrict(1, 1, 2, 3)
+
+	if !board.ApplyAdvancedTechniques() {
+		t.Fatal("ApplyAdvancedTechniques() should find the XYZ-Wing")
+	}
+
+	// R1C2 and R1C3 see the pivot and both wings
+	for _, col := range []int{1, 2} {
+		if board.GetCellAt(0, col).HasCandidate(3) {
+			t.Errorf("R1C%d should lose 3", col+1)
+		}
+	}
+	// R1C4 sees the pivot and R1C5 but not R2C2, and the pattern cells keep 3
+	for _, pos := range [][2]int{{0, 3}, {0, 0}, {0, 4}, {1, 1}, {1, 0}} {
+		if !board.GetCellAt(pos[0], pos[1]).HasCandidate(3) {
+			t.Errorf("R%dC%d should keep 3", pos[0]+1, pos[1]+1)
+		}
+	}
+}
+
+func TestBoardApplyIntersectionRemoval(t *testing.T) {
+	rowsAndBoxes := func(t *testing.T) *lib.Board {
+		board := lib.NewBoard()
+		for i := 0; i < 9; i++ {
+			row, err := constraints.NewRowConstraint(i)
+			if err != nil {
+				t.Fatalf("NewRowConstraint() returned error: %v", err)
+			}
+			box, err := constraints.NewBoxConstraint(i)
+			if err != nil {
+				t.Fatalf("NewBoxConstraint() returned error: %v", err)
+			}
+			board.AddConstraint(row)
+			board.AddConstraint(box)
+		}
+		return board
+	}
+
+	tests := []struct {
+		name     string
+		newBoard func(t *testing.T) *lib.Board
+	}{
+		{"standard board", newStandardBoard},
+		{"rows and boxes only", rowsAndBoxes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := tt.newBoard(t)
+
+			// Pointing: 1 in box 1 is confined to row 1
+			for _, idx := range []int{9, 10, 11, 18, 19, 20} {
+				board.GetCell(idx).RemoveCandidate(1)
+			}
+			// Box-line reduction: 2 in row 9 is confined to box 7
+			for col := 3; col < 9; col++ {
+				board.GetCellAt(8, col).RemoveCandidate(2)
+			}
+
+			if !board.ApplyIntersectionRemoval() {
+				t.Fatal("ApplyIntersectionRemoval() should find eliminations")
+			}
+
+			for col := 3; col < 9; col++ {
+				if board.GetCellAt(0, col).HasCandidate(1) {
+					t.Errorf("R1C%d should lose 1", col+1)
+				}
+			}
+			for _, idx := range []int{54, 55, 56, 63, 64, 65} {
+				if board.GetCell(idx).HasCandidate(2) {
+					t.Errorf("R%dC%d should lose 2", idx/9+1, idx%9+1)
+				}
+			}
+			for _, idx := range []int{0, 1, 2, 27} {
+				if !board.GetCell(idx).HasCandidate(1) {
+					t.Errorf("R%dC%d should keep 1", idx/9+1, idx%9+1)
+				}
+			}
+
+			if board.ApplyIntersectionRemoval() {
+				t.Error("a second pass should find nothing new")
+			}
+		})
+	}
+}
+
+func TestBoardTotalCandidates(t *testing.T) {
+	board := newStandardBoard(t)
+	if got := board.TotalCandidates(); got != 729 {
+		t.Errorf("empty board TotalCandidates() = %d, want 729", got)
+	}
+
+	// The cell's own 9 candidates go, and 5 disappears from its 20 peers
+	board.Set(4, 4, 5)
+	if got := board.TotalCandidates(); got != 700 {
+		t.Errorf("TotalCandidates() after one value = %d, want 700", got)
+	}
+
+	// Stagnation leaves the count unchanged
+	before := board.TotalCandidates()
+	board.ApplyAdvancedTechniques()
+	if got := board.TotalCandidates(); got != before {
+		t.Errorf("TotalCandidates() = %d after a pass with nothing to find, want %d", got, before)
+	}
+}
+
+func TestBoardProgress(t *testing.T) {
+	small, err := lib.NewBoardOfSize(4)
+	if err != nil {
+		t.Fatalf("NewBoardOfSize(4) returned error: %v", err)
+	}
+	small.Set(0, 0, 1)
+	partial := newStandardBoard(t)
+	setGrid(t, partial, easyPuzzle)
+	full := newStandardBoard(t)
+	setGrid(t, full, easySolution)
+
+	tests := []struct {
+		name       string
+		board      *lib.Board
+		wantFilled int
+		want       float64
+	}{
+		{"empty", newStandardBoard(t), 0, 0},
+		{"puzzle", partial, 30, 30.0 / 81},
+		{"solved", full, 81, 1},
+		{"4x4 with one value", small, 1, 1.0 / 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.board.FilledCount(); got != tt.wantFilled {
+				t.Errorf("FilledCount() = %d, want %d", got, tt.wantFilled)
+			}
+			if got := tt.board.Progress(); got != tt.want {
+				t.Errorf("Progress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoardLoadString(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		shouldErr bool
+	}{
+		{"zeros", easyPuzzle, false},
+		{"dots", strings.ReplaceAll(easyPuzzle, "0", "."), false},
+		{"split over lines", easyPuzzle[:27] + "\n" + easyPuzzle[27:54] + "\r\n  " + easyPuzzle[54:], false},
+		{"too short", easyPuzzle[:80], true},
+		{"too long", easyPuzzle + "1", true},
+		{"invalid character", "x" + easyPuzzle[1:], true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			err := board.LoadString(tt.input)
+			if tt.shouldErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				if board.TotalCandidates() != 729 {
+					t.Error("a rejected string should leave the board untouched")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadString() returned error: %v", err)
+			}
+
+			want := strings.ReplaceAll(easyPuzzle, "0", ".")
+			if got := board.String(); got != want {
+				t.Errorf("String() = %q, want %q", got, want)
+			}
+
+			// Givens propagate like Set
+			if board.GetCellAt(0, 2).HasCandidate(5) {
+				t.Error("R1C3 should lose candidate 5 from the given in R1C1")
+			}
+			if !board.GetCellAt(0, 0).IsGiven() || board.GetCellAt(0, 2).IsGiven() {
+				t.Error("loaded values should be marked as givens, empty cells should not")
+			}
+		})
+	}
+}
+
+func TestBoardGivensProtected(t *testing.T) {
+	tests := []struct {
+		name      string
+		set       func(board *lib.Board) error
+		shouldErr bool
+		want      int  // R1C1 afterwards
+		given     bool // R1C1 still a given
+	}{
+		{"overwrite", func(b *lib.Board) error { return b.Set(0, 0, 4) }, true, 5, true},
+		{"clear", func(b *lib.Board) error { return b.Set(0, 0, 0) }, true, 5, true},
+		{"same value", func(b *lib.Board) error { return b.Set(0, 0, 5) }, false, 5, true},
+		{"given over a given", func(b *lib.Board) error { return b.SetGiven(0, 0, 4) }, true, 5, true},
+		{"forced overwrite", func(b *lib.Board) error { return b.ForceSet(0, 0, 4) }, false, 4, false},
+		{"forced clear", func(b *lib.Board) error { return b.ForceSet(0, 0, 0) }, false, 0, false},
+		{"failed forced overwrite", func(b *lib.Board) error { return b.ForceSet(0, 0, 10) }, true, 5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			if err := board.SetGiven(0, 0, 5); err != nil {
+				t.Fatalf("SetGiven() returned error: %v", err)
+			}
+
+			err := tt.set(board)
+			if (err != nil) != tt.shouldErr {
+				t.Errorf("error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if got := board.Get(0, 0); got != tt.want {
+				t.Errorf("R1C1 = %d, want %d", got, tt.want)
+			}
+			if got := board.GetCellAt(0, 0).IsGiven(); got != tt.given {
+				t.Errorf("IsGiven() = %v, want %v", got, tt.given)
+			}
+		})
+	}
+}
+
+func TestBoardSetGivenSized(t *testing.T) {
+	board, err := lib.NewBoardOfSize(4)
+	if err != nil {
+		t.Fatalf("NewBoardOfSize() returned error: %v", err)
+	}
+
+	if err := board.SetGiven(0, 0, 5); err == nil {
+		t.Error("SetGiven() should reject 5 on a 4x4 board")
+	}
+	if err := board.SetGiven(0, 0, 4); err != nil {
+		t.Errorf("SetGiven() returned error: %v", err)
+	}
+	if !board.GetCellAt(0, 0).IsGiven() {
+		t.Error("R1C1 should be a given")
+	}
+}
+
+func TestBoardSolveKeepsGivens(t *testing.T) {
+	board := newStandardBoard(t)
+	if err := board.LoadString(bugPlusOneGrid); err != nil {
+		t.Fatalf("LoadString() returned error: %v", err)
+	}
+
+	if solved, err := board.Solve(); err != nil || !solved {
+		t.Fatalf("Solve() = %v, %v", solved, err)
+	}
+	for i := 0; i < 81; i++ {
+		if bugPlusOneGrid[i] == '0' {
+			continue
+		}
+		if cell := board.GetCell(i); !cell.IsGiven() || cell.GetValue() != int(bugPlusOneGrid[i]-'0') {
+			t.Errorf("given R%dC%d = %d (given %v), want %c", i/9+1, i%9+1, cell.GetValue(), cell.IsGiven(), bugPlusOneGrid[i])
+		}
+	}
+}
+
+func TestBoardStringRoundTrip(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, easySolution)
+
+	again := newStandardBoard(t)
+	if err := again.LoadString(board.String()); err != nil {
+		t.Fatalf("LoadString() returned error: %v", err)
+	}
+	if again.String() != easySolution {
+		t.Errorf("round trip = %q, want %q", again.String(), easySolution)
+	}
+}
+
+func TestBoardValidateCandidates(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, easyPuzzle)
+	if got := board.ValidateCandidates(); len(got) != 0 {
+		t.Fatalf("ValidateCandidates() on a freshly loaded board = %v, want none", got)
+	}
+
+	// Manual edits: re-add candidates that a solved peer forbids
+	board.GetCellAt(0, 2).AddCandidate(3)
+	board.GetCellAt(0, 3).AddCandidate(5)
+
+	want := "[2 3]"
+	if got := fmt.Sprint(board.ValidateCandidates()); got != want {
+		t.Errorf("ValidateCandidates() = %s, want %s", got, want)
+	}
+
+	board.RecomputeAllCandidates()
+	if got := board.ValidateCandidates(); len(got) != 0 {
+		t.Errorf("ValidateCandidates() after RecomputeAllCandidates = %v, want none", got)
+	}
+	if board.GetCellAt(0, 3).HasCandidate(5) {
+		t.Error("R1C4 should lose candidate 5 again after the repair")
+	}
+}
+
+func TestBoardClearRecomputesCandidates(t *testing.T) {
+	tests := []struct {
+		name  string
+		value int // New value for R1C1, 0 to clear it
+	}{
+		{"clear", 0},
+		{"replace", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			board.Set(0, 0, 5)
+			board.Set(0, 0, tt.value)
+
+			// The candidates match a board that never had the 5
+			fresh := newStandardBoard(t)
+			if tt.value != 0 {
+				fresh.Set(0, 0, tt.value)
+			}
+			if board.CandidatesString() != fresh.CandidatesString() {
+				t.Error("candidates after the edit should match a board that never had the 5")
+			}
+			if got := board.ValidateCandidates(); len(got) != 0 {
+				t.Errorf("ValidateCandidates() = %v, want none", got)
+			}
+		})
+	}
+}
+
+// newStandardBoard creates a board with all row, column and box constraints
+func newStandardBoard(t *testing.T) *lib.Board {
+	t.Helper()
+
+	board := lib.NewBoard()
+	if err := constraints.AddStandardConstraints(board); err != nil {
+		t.Fatalf("failed to add standard constraints: %v", err)
+	}
+	return board
+}
+
+// setGrid sets every non-zero digit of an 81-character grid string on the board
+func setGrid(t *testing.T, board *lib.Board, grid string) {
+	t.Helper()
+
+	if len(grid) != 81 {
+		t.Fatalf("grid must have 81 characters, got %d", len(grid))
+	}
+	for i, ch := range grid {
+		if ch == '0' {
+			continue
+		}
+		if err := board.Set(i/9, i%9, int(ch-'0')); err != nil {
+			t.Fatalf("Set(%d, %d, %c) failed: %v", i/9, i%9, ch, err)
+		}
+	}
+}
+
+// bugPlusOneGrid leaves 18 bivalue cells and R7C4 = {4,7,9}
+const bugPlusOneGrid = "026817035038569102501324860354178296602053081810206503065001328180032654243685010"
+
+func TestBoardApplyBUG(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, bugPlusOneGrid)
+	board.SetAssumeUnique(true)
+
+	triValue := board.GetCellAt(6, 3)
+	if triValue.CandidateCount() != 3 {
+		t.Fatalf("expected R7C4 to have 3 candidates, got %v", triValue.GetCandidates())
+	}
+
+	if !board.ApplyAdvancedTechniques() {
+		t.Fatal("expected advanced techniques to make progress on a BUG+1 position")
+	}
+
+	// 9 appears three times in row 7, column 4 and box 8; 4 and 7 appear twice
+	if got := board.Get(6, 3); got != 9 {
+		t.Errorf("BUG+1 should set R7C4 to 9, got %d", got)
+	}
+
+	valid, err := board.ValidateAll()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !valid {
+		t.Error("board should remain valid after BUG+1")
+	}
+}
+
+func TestBoardApplyBUGPreconditions(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, board *lib.Board)
+	}{
+		{"grave broken outside the tri-value units", func(t *testing.T, board *lib.Board) {
+			// R1C1 {4,9} becomes {7,9}, leaving 4 and 7 once each in row 1
+			cell := board.GetCellAt(0, 0)
+			cell.RemoveCandidate(4)
+			cell.AddCandidate(7)
+		}},
+		{"constraint that is not a unit", func(t *testing.T, board *lib.Board) {
+			cage, err := constraints.NewKillerCageConstraint([]int{0, 1}, 11)
+			if err != nil {
+				t.Fatalf("failed to create killer cage: %v", err)
+			}
+			board.AddConstraint(cage)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			setGrid(t, board, bugPlusOneGrid)
+			board.SetAssumeUnique(true)
+			tt.setup(t, board)
+
+			board.ApplyAdvancedTechniques()
+			if got := board.Get(6, 3); got != 0 {
+				t.Errorf("BUG+1 should not fire, but R7C4 = %d", got)
+			}
+		})
+	}
+}
+
+func TestBoardApplyBUGRequiresAssumeUnique(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, bugPlusOneGrid)
+
+	if board.AssumeUnique() {
+		t.Fatal("AssumeUnique should be disabled by default")
+	}
+
+	board.ApplyAdvancedTechniques()
+
+	if got := board.Get(6, 3); got != 0 {
+		t.Errorf("BUG+1 should not fire without AssumeUnique, but R7C4 = %d", got)
+	}
+}
+
+// setCandidates leaves exactly the given candidates in the cell at index
+func setCandidates(board *lib.Board, index int, candidates ...int) {
+	keep := make(map[int]bool)
+	for _, candidate := range candidates {
+		keep[candidate] = true
+	}
+	for candidate := 1; candidate <= 9; candidate++ {
+		if !keep[candidate] {
+			board.GetCell(index).RemoveCandidate(candidate)
+		}
+	}
+}
+
+func TestBoardApplyUniqueRectangles(t *testing.T) {
+	tests := []struct {
+		name         string
+		corners      [4]int // Row by row; the last one gets {1,2,3}
+		assumeUnique bool
+		wantTarget   string
+	}{
+		{"two boxes side by side", [4]int{0, 3, 9, 12}, true, "[3]"},
+		{"two boxes stacked", [4]int{0, 1, 36, 37}, true, "[3]"},
+		{"four boxes", [4]int{0, 3, 36, 39}, true, "[1 2 3]"},
+		{"one box", [4]int{0, 1, 9, 10}, true, "[1 2 3]"},
+		{"uniqueness not assumed", [4]int{0, 3, 9, 12}, false, "[1 2 3]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			board.SetAssumeUnique(tt.assumeUnique)
+			for _, idx := range tt.corners[:3] {
+				setCandidates(board, idx, 1, 2)
+			}
+			setCandidates(board, tt.corners[3], 1, 2, 3)
+
+			board.ApplyAdvancedTechniques()
+			if got := fmt.Sprint(board.GetCell(tt.corners[3]).CandidateSlice()); got != tt.wantTarget {
+				t.Errorf("fourth corner candidates = %s, want %s", got, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestBoardNextHintUniqueRectangle(t *testing.T) {
+	board := newStandardBoard(t)
+	board.SetAssumeUnique(true)
+	for _, idx := range []int{0, 3, 9} {
+		setCandidates(board, idx, 1, 2)
+	}
+	setCandidates(board, 12, 1, 2, 3)
+
+	// Clear 1 and 2 from the rest of row 1, column 1 and box 1 so the pairs there
+	// eliminate nothing and the rectangle is the first step
+	for _, idx := range []int{1, 2, 4, 5, 6, 7, 8, 10, 11, 18, 19, 20, 27, 36, 45, 54, 63, 72} {
+		board.GetCell(idx).RemoveCandidate(1)
+		board.GetCell(idx).RemoveCandidate(2)
+	}
+
+	hint, found := board.NextHint()
+	if !found || hint.Technique != "Unique Rectangle" {
+		t.Fatalf("NextHint() = %+v, %v, want a unique rectangle", hint, found)
+	}
+	if !reflect.DeepEqual(hint.Cells, []int{12}) || !reflect.DeepEqual(hint.Candidates, []int{1, 2}) {
+		t.Errorf("hint removes %v from %v, want [1 2] from [12]", hint.Candidates, hint.Cells)
+	}
+
+	// The logical solver tries the rectangle too, leaving 3 as R2C4's only candidate
+	board.SolveLogically()
+	if got := board.Get(1, 3); got != 3 {
+		t.Errorf("R2C4 = %d after SolveLogically, want 3", got)
+	}
+}
+
+func TestBoardApplyUniqueRectanglesSkipsVariantConstraints(t *testing.T) {
+	board := newStandardBoard(t)
+	board.SetAssumeUnique(true)
+
+	// A cage holding a single corner could rule out one of the two solutions
+	cage, err := constraints.NewKillerCageConstraint([]int{0, 18}, 10)
+	if err != nil {
+		t.Fatalf("NewKillerCageConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(cage)
+	for _, idx := range []int{0, 3, 9} {
+		setCandidates(board, idx, 1, 2)
+	}
+	setCandidates(board, 12, 1, 2, 3)
+
+	board.ApplyAdvancedTechniques()
+	if got := fmt.Sprint(board.GetCell(12).CandidateSlice()); got != "[1 2 3]" {
+		t.Errorf("fourth corner candidates = %s, want [1 2 3]", got)
+	}
+}
+
+func TestBoardHiddenSingleCandidates(t *testing.T) {
+	board := newStandardBoard(t)
+
+	// 5s in rows 2-3 and columns 2-3 leave R1C1 as the only place for 5 in Box 1 and Row 1
+	board.Set(1, 3, 5)
+	board.Set(2, 6, 5)
+	board.Set(4, 1, 5)
+	board.Set(5, 2, 5)
+
+	before := board.CandidatesString()
+	singles := board.HiddenSingleCandidates()
+
+	if board.CandidatesString() != before {
+		t.Error("HiddenSingleCandidates() should not modify the board")
+	}
+
+	matches := 0
+	for _, single := range singles {
+		if single.Index == 0 && single.Value == 5 {
+			matches++
+			if single.Unit != "Row 1" {
+				t.Errorf("expected R1C1=5 to be attributed to Row 1, got %q", single.Unit)
+			}
+		}
+		if !board.GetCell(single.Index).HasCandidate(single.Value) {
+			t.Errorf("reported hidden single %d at index %d is not a candidate", single.Value, single.Index)
+		}
+	}
+	if matches != 1 {
+		t.Errorf("expected R1C1=5 to be reported once, got %d", matches)
+	}
+
+	for i := 1; i < len(singles); i++ {
+		prev, cur := singles[i-1], singles[i]
+		if prev.Index > cur.Index || (prev.Index == cur.Index && prev.Value >= cur.Value) {
+			t.Errorf("results not ordered by index and value: %+v before %+v", prev, cur)
+		}
+	}
+}
+
+func TestBoardHiddenSingleCandidatesEmptyBoard(t *testing.T) {
+	board := newStandardBoard(t)
+	if singles := board.HiddenSingleCandidates(); len(singles) != 0 {
+		t.Errorf("expected no hidden singles on an empty board, got %v", singles)
+	}
+}
+
+// newKillerSinglesBoard sets up a 2-cell cage summing to 10 in R1C1-R1C2 and fills
+// R1C1 plus R1C3-R1C8, so the cage forces R1C2 to 7 and R1C9 is left with {7, 9}
+func newKillerSinglesBoard(t *testing.T) *lib.Board {
+	t.Helper()
+
+	board := newStandardBoard(t)
+	cage, err := constraints.NewKillerCageConstraint([]int{0, 1}, 10)
+	if err != nil {
+		t.Fatalf("failed to create killer cage: %v", err)
+	}
+	board.AddConstraint(cage)
+
+	setGrid(t, board, "301245680"+strings.Repeat("0", 72))
+	return board
+}
+
+func TestBoardFillNakedSingles(t *testing.T) {
+	board := newKillerSinglesBoard(t)
+
+	if got := board.GetCellAt(0, 1).CandidateCount(); got != 1 {
+		t.Fatalf("killer cage should leave R1C2 with one candidate, got %d", got)
+	}
+	if board.Get(0, 1) != 0 {
+		t.Fatal("R1C2 should not be placed before FillNakedSingles")
+	}
+
+	placed := board.FillNakedSingles()
+
+	if got := board.Get(0, 1); got != 7 {
+		t.Errorf("R1C2 = %d, want 7 from the killer cage", got)
+	}
+	// Placing 7 leaves R1C9 with only 9, which is placed in the same pass
+	if got := board.Get(0, 8); got != 9 {
+		t.Errorf("R1C9 = %d, want 9 after the cascade", got)
+	}
+	if placed != 2 {
+		t.Errorf("FillNakedSingles() = %d, want 2", placed)
+	}
+	if board.GetCellAt(5, 1).HasCandidate(7) || board.GetCellAt(5, 8).HasCandidate(9) {
+		t.Error("placed values should propagate through the column constraints")
+	}
+}
+
+func TestBoardPencilMarksUntilStablePlacesSingles(t *testing.T) {
+	board := newKillerSinglesBoard(t)
+
+	board.ApplyPencilMarkConstraintsUntilStable()
+
+	if board.Get(0, 1) != 7 || board.Get(0, 8) != 9 {
+		t.Errorf("expected R1C2=7 and R1C9=9 to be placed, got %d and %d", board.Get(0, 1), board.Get(0, 8))
+	}
+}
+
+func TestBoardValidateAllDetailed(t *testing.T) {
+	board := newStandardBoard(t)
+	cage, err := constraints.NewKillerCageConstraint([]int{72, 73}, 5)
+	if err != nil {
+		t.Fatalf("failed to create killer cage: %v", err)
+	}
+	board.AddConstraint(cage)
+
+	violations, err := board.ValidateAllDetailed()
+	if err != nil {
+		t.Fatalf("ValidateAllDetailed() returned error: %v", err)
+	}
+	if len(violations) != 0 {
+		t.Errorf("expected no violations on an empty board, got %+v", violations)
+	}
+
+	// Two 5s in Row 1 (also Box 1), and a cage summing to 9 instead of 5
+	board.Set(0, 0, 5)
+	board.Set(0, 2, 5)
+	board.Set(8, 0, 3)
+	board.Set(8, 1, 6)
+
+	violations, err = board.ValidateAllDetailed()
+	if err != nil {
+		t.Fatalf("ValidateAllDetailed() returned error: %v", err)
+	}
+
+	byName := make(map[string]lib.ConstraintViolation)
+	for _, v := range violations {
+		byName[v.ConstraintName] = v
+	}
+	if len(violations) != 3 {
+		t.Errorf("expected 3 violations, got %d: %+v", len(violations), violations)
+	}
+	for _, name := range []string{"Row 1", "Box 1"} {
+		v, ok := byName[name]
+		if !ok {
+			t.Errorf("expected a violation for %s", name)
+			continue
+		}
+		if v.Value != 5 || len(v.Cells) != 2 {
+			t.Errorf("%s: expected duplicate 5 in 2 cells, got %d in %v", name, v.Value, v.Cells)
+		}
+	}
+	if v, ok := byName["Killer Cage (5)"]; !ok || v.Value != 9 {
+		t.Errorf("expected killer cage violation with sum 9, got %+v", v)
+	}
+}
+
+// probeConstraint fails or errors on demand and tries to change the board while validating
+type probeConstraint struct {
+	lib.BaseConstraint
+	valid  bool
+	err    error
+	setErr error
+}
+
+func (pc *probeConstraint) IsValid(board *lib.Board) (bool, error) {
+	pc.setErr = board.Set(8, 8, 1)
+	return pc.valid, pc.err
+}
+func (pc *probeConstraint) GetDescription() string { return "probe" }
+
+func TestBoardValidateAllConcurrent(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    [][3]int // row, col, value
+		probe     *probeConstraint
+		wantValid bool
+		wantErr   bool
+	}{
+		{"empty board", nil, nil, true, false},
+		{"valid partial grid", [][3]int{{0, 0, 5}, {0, 1, 3}, {1, 0, 6}}, nil, true, false},
+		{"duplicate in row", [][3]int{{0, 0, 5}, {0, 4, 5}}, nil, false, false},
+		{"failing constraint", nil, &probeConstraint{valid: false}, false, false},
+		{"erroring constraint", nil, &probeConstraint{valid: true, err: errors.New("boom")}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			for _, v := range tt.values {
+				if err := board.Set(v[0], v[1], v[2]); err != nil {
+					t.Fatalf("Set(%d, %d, %d) returned error: %v", v[0], v[1], v[2], err)
+				}
+			}
+			if tt.probe != nil {
+				tt.probe.BaseConstraint = lib.BaseConstraint{Cells: []int{80}, Name: "Probe"}
+				board.AddConstraint(tt.probe)
+			}
+
+			valid, err := board.ValidateAllConcurrent()
+			if valid != tt.wantValid || (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAllConcurrent() = (%v, %v), want valid %v, error %v", valid, err, tt.wantValid, tt.wantErr)
+			}
+
+			seqValid, seqErr := board.ValidateAll()
+			if valid != seqValid || (err != nil) != (seqErr != nil) {
+				t.Errorf("ValidateAllConcurrent() = (%v, %v), ValidateAll() = (%v, %v)", valid, err, seqValid, seqErr)
+			}
+		})
+	}
+}
+
+func TestBoardValidateAllConcurrentBlocksSet(t *testing.T) {
+	board := newStandardBoard(t)
+	probe := &probeConstraint{BaseConstraint: lib.BaseConstraint{Cells: []int{80}, Name: "Probe"}, valid: true}
+	board.AddConstraint(probe)
+
+	if _, err := board.ValidateAllConcurrent(); err != nil {
+		t.Fatalf("ValidateAllConcurrent() returned error: %v", err)
+	}
+	if probe.setErr == nil || board.Get(8, 8) != 0 {
+		t.Error("Set() should be refused while the board is being validated")
+	}
+
+	// The guard is lifted once validation returns
+	if err := board.Set(8, 8, 1); err != nil {
+		t.Errorf("Set() after validation returned error: %v", err)
+	}
+}
+
+func TestBoardEquals(t *testing.T) {
+	solved := func() *lib.Board {
+		board := newStandardBoard(t)
+		setGrid(t, board, easySolution)
+		return board
+	}
+	puzzle := func() *lib.Board {
+		board := newStandardBoard(t)
+		setGrid(t, board, easyPuzzle)
+		return board
+	}
+	fewerCandidates := puzzle()
+	fewerCandidates.GetCellAt(0, 2).RemoveCandidate(4)
+	small, err := lib.NewBoardOfSize(6)
+	if err != nil {
+		t.Fatalf("NewBoardOfSize(6) returned error: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		a, b           *lib.Board
+		want           bool
+		wantCandidates bool
+	}{
+		{"same values", solved(), solved(), true, true},
+		{"same puzzle", puzzle(), puzzle(), true, true},
+		{"different values", solved(), puzzle(), false, false},
+		{"different candidates", puzzle(), fewerCandidates, true, false},
+		{"different sizes", lib.NewBoard(), small, false, false},
+		{"both nil", nil, nil, true, true},
+		{"nil and a board", nil, lib.NewBoard(), false, false},
+		{"a board and nil", lib.NewBoard(), nil, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equals(tt.b); got != tt.want {
+				t.Errorf("Equals() = %v, want %v", got, tt.want)
+			}
+			if got := tt.a.EqualsWithCandidates(tt.b); got != tt.wantCandidates {
+				t.Errorf("EqualsWithCandidates() = %v, want %v", got, tt.wantCandidates)
+			}
+		})
+	}
+
+	// The solver's output matches the known solution
+	board := puzzle()
+	if solved, err := board.Solve(); err != nil || !solved {
+		t.Fatalf("Solve() = %v, %v, want true, nil", solved, err)
+	}
+	if !board.Equals(solved()) {
+		t.Errorf("solved board %s does not equal the solution", board.String())
+	}
+}
+
+func TestBoardLoadStringConflictingGivens(t *testing.T) {
+	board := newStandardBoard(t)
+
+	// Two 5s in Row 1, loaded anyway so a UI can highlight them
+	grid := "5.5" + strings.Repeat(".", 78)
+	if err := board.LoadString(grid); err != nil {
+		t.Fatalf("LoadString() returned error: %v", err)
+	}
+
+	valid, err := board.ValidateAll()
+	if err != nil || valid {
+		t.Errorf("ValidateAll() = %v, %v, want false", valid, err)
+	}
+
+	violations, err := board.ValidateAllDetailed()
+	if err != nil {
+		t.Fatalf("ValidateAllDetailed() returned error: %v", err)
+	}
+	want := []lib.Violation{
+		{ConstraintName: "Row 1", Cells: []int{0, 2}, Value: 5},
+		{ConstraintName: "Box 1", Cells: []int{0, 2}, Value: 5},
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("ValidateAllDetailed() = %+v, want %d violations", violations, len(want))
+	}
+	for i, v := range violations {
+		if v.ConstraintName != want[i].ConstraintName || !reflect.DeepEqual(v.Cells, want[i].Cells) || v.Value != want[i].Value {
+			t.Errorf("violation %d = %+v, want %+v", i, v, want[i])
+		}
+	}
+}
+
+func TestBoardRemoveConstraintRestoresCandidates(t *testing.T) {
+	board := newStandardBoard(t)
+	cage, err := constraints.NewKillerCageConstraint([]int{0, 1}, 3)
+	if err != nil {
+		t.Fatalf("failed to create killer cage: %v", err)
+	}
+	board.AddConstraint(cage)
+
+	board.Set(0, 0, 1)
+	board.Set(4, 1, 8)
+
+	// The cage forces R1C2 to 2
+	if got := board.GetCellAt(0, 1).CandidateCount(); got != 1 {
+		t.Fatalf("expected the cage to leave R1C2 one candidate, got %d", got)
+	}
+
+	if !board.RemoveConstraint(cage) {
+		t.Fatal("RemoveConstraint() should find the cage")
+	}
+	if len(board.GetConstraints()) != 27 {
+		t.Errorf("expected 27 constraints after removal, got %d", len(board.GetConstraints()))
+	}
+
+	// Everything the cage eliminated comes back, except what Row 1 (1) and Column 2 (8) forbid
+	cell := board.GetCellAt(0, 1)
+	for candidate := 1; candidate <= 9; candidate++ {
+		want := candidate != 1 && candidate != 8
+		if cell.HasCandidate(candidate) != want {
+			t.Errorf("R1C2 candidate %d: got %v, want %v", candidate, cell.HasCandidate(candidate), want)
+		}
+	}
+
+	// The cage no longer propagates once removed
+	board.Set(0, 1, 5)
+	if got := board.GetCellAt(0, 1).GetValue(); got != 5 {
+		t.Errorf("R1C2 = %d, want 5", got)
+	}
+	if valid, _ := board.ValidateAffected(0, 1); !valid {
+		t.Error("R1C2=5 should be valid once the cage is removed")
+	}
+
+	if board.RemoveConstraint(cage) {
+		t.Error("removing the cage twice should report it was not found")
+	}
+}
+
+// countingConstraint counts the value changes it is asked to propagate
+type countingConstraint struct {
+	lib.BaseConstraint
+	propagations int
+}
+
+func (cc *countingConstraint) IsValid(board *lib.Board) (bool, error) { return true, nil }
+func (cc *countingConstraint) GetDescription() string                 { return "counts propagations" }
+
+func (cc *countingConstraint) PropagateValueChange(row, col, value int) {
+	if value != 0 {
+		cc.propagations++
+	}
+}
+
+func TestBoardRemoveConstraintDeregistersObserver(t *testing.T) {
+	board := lib.NewBoard()
+	counter := &countingConstraint{BaseConstraint: lib.BaseConstraint{Cells: []int{0, 1, 2}, Name: "Counter"}}
+	board.AddConstraint(counter)
+
+	board.Set(0, 0, 1)
+	if counter.propagations != 1 {
+		t.Fatalf("expected 1 propagation while on the board, got %d", counter.propagations)
+	}
+	if !board.RemoveConstraint(counter) {
+		t.Fatal("RemoveConstraint() should find the constraint")
+	}
+	before := counter.propagations
+
+	// Neither new values nor a candidate rebuild reach the removed constraint
+	board.Set(0, 1, 2)
+	board.Set(0, 2, 3)
+	board.RecomputeAllCandidates()
+	if counter.propagations != before {
+		t.Errorf("removed constraint received %d propagation(s)", counter.propagations-before)
+	}
+	for _, idx := range counter.GetCells() {
+		if board.GetCell(idx).GetNotifier().HasObservers() {
+			t.Errorf("cell %d still has observers after removal", idx)
+		}
+	}
+}
+
+func TestBoardGetConstraintsForCell(t *testing.T) {
+	board := newStandardBoard(t)
+	cage, err := constraints.NewKillerCageConstraint([]int{0, 1}, 8)
+	if err != nil {
+		t.Fatalf("NewKillerCageConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(cage)
+
+	names := func(cs []lib.Constraint) []string {
+		result := make([]string, 0, len(cs))
+		for _, c := range cs {
+			result = append(result, c.GetName())
+		}
+		return result
+	}
+
+	if got, want := names(board.GetConstraintsForCell(0)), []string{"Row 1", "Column 1", "Box 1", cage.GetName()}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetConstraintsForCell(0) = %v, want %v", got, want)
+	}
+	if got, want := names(board.GetConstraintsForCell(80)), []string{"Row 9", "Column 9", "Box 9"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetConstraintsForCell(80) = %v, want %v", got, want)
+	}
+	if got := board.GetConstraintsForCell(81); got != nil {
+		t.Errorf("GetConstraintsForCell(81) = %v, want nil", got)
+	}
+
+	board.RemoveConstraint(cage)
+	if got := names(board.GetConstraintsForCell(1)); len(got) != 3 {
+		t.Errorf("after removing the cage, GetConstraintsForCell(1) = %v, want the row, column and box", got)
+	}
+}
+
+func TestBoardVisibleCells(t *testing.T) {
+	board := newStandardBoard(t)
+	indices := func(cells []*lib.Cell) []int {
+		result := make([]int, 0, len(cells))
+		for _, cell := range cells {
+			result = append(result, cell.GetIndex())
+		}
+		return result
+	}
+
+	// Row 1, then column 1 and the rest of box 1, in index order
+	want := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 18, 19, 20, 27, 36, 45, 54, 63, 72}
+	visible := board.VisibleCells(board.GetCell(0))
+	if got := indices(visible); !reflect.DeepEqual(got, want) {
+		t.Fatalf("VisibleCells(R1C1) = %v, want %v", got, want)
+	}
+
+	// The caller's copy can be changed without touching the cache
+	visible[0] = nil
+	if got := indices(board.VisibleCells(board.GetCell(0))); !reflect.DeepEqual(got, want) {
+		t.Errorf("VisibleCells(R1C1) after changing a returned slice = %v, want %v", got, want)
+	}
+
+	cage, err := constraints.NewCageUniqueConstraint([]int{0, 40})
+	if err != nil {
+		t.Fatalf("failed to create cage: %v", err)
+	}
+	board.AddConstraint(cage)
+	if got := indices(board.VisibleCells(board.GetCell(0))); len(got) != 21 || !utils.ContainsInt(got, 40) {
+		t.Errorf("VisibleCells(R1C1) with the cage = %v, want R5C5 added", got)
+	}
+	board.RemoveConstraint(cage)
+	if got := indices(board.VisibleCells(board.GetCell(0))); !reflect.DeepEqual(got, want) {
+		t.Errorf("VisibleCells(R1C1) after removing the cage = %v, want %v", got, want)
+	}
+
+	if got := board.VisibleCells(nil); got != nil {
+		t.Errorf("VisibleCells(nil) = %v, want nil", got)
+	}
+	if got := board.VisibleCells(newStandardBoard(t).GetCell(0)); got != nil {
+		t.Errorf("VisibleCells() of another board's cell = %v, want nil", got)
+	}
+}
+
+func TestBoardLastDigitInUnit(t *testing.T) {
+	row1, _ := constraints.NewRowConstraint(0)
+	cage, _ := constraints.NewKillerCageConstraint([]int{0, 1}, 8)
+
+	tests := []struct {
+		name       string
+		constraint lib.Constraint
+		grid       string
+		wantIndex  int
+		wantValue  int
+		wantOK     bool
+	}{
+		{"one empty cell", row1, "534678910", 8, 2, true},
+		{"empty cell in the middle", row1, "534608912", 4, 7, true},
+		{"two empty cells", row1, "534678900", 0, 0, false},
+		{"full unit", row1, "534678912", 0, 0, false},
+		{"cage smaller than 9 cells", cage, "500000000", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			setGrid(t, board, tt.grid+strings.Repeat("0", 72))
+
+			idx, value, ok := board.LastDigitInUnit(tt.constraint)
+			if ok != tt.wantOK || idx != tt.wantIndex || value != tt.wantValue {
+				t.Errorf("LastDigitInUnit() = (%d, %d, %v), want (%d, %d, %v)",
+					idx, value, ok, tt.wantIndex, tt.wantValue, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestBoardSolveUsesFullHouse(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, "534678910"+strings.Repeat("0", 72))
+
+	result, err := board.SolveUntilCell(0, 8)
+	if err != nil {
+		t.Fatalf("SolveUntilCell() returned error: %v", err)
+	}
+	if len(result.Steps) != 1 || result.Steps[0].Technique != "Full House" {
+		t.Errorf("expected a single Full House step, got %+v", result.Steps)
+	}
+}
+
+func TestBoardPropagateUniqueness(t *testing.T) {
+	// Values set before the constraints exist are not propagated by the observers
+	board := lib.NewBoard()
+	board.Set(0, 0, 5)
+	board.Set(4, 4, 7)
+	if err := constraints.AddStandardConstraints(board); err != nil {
+		t.Fatalf("failed to add standard constraints: %v", err)
+	}
+
+	if !board.GetCellAt(0, 8).HasCandidate(5) {
+		t.Fatal("expected R1C9 to still list 5 before propagation")
+	}
+
+	if !board.PropagateUniqueness() {
+		t.Fatal("PropagateUniqueness() should report eliminations")
+	}
+
+	tests := []struct {
+		row, col, value int
+		want            bool
+	}{
+		{0, 8, 5, false}, // row peer
+		{8, 0, 5, false}, // column peer
+		{2, 2, 5, false}, // box peer
+		{4, 0, 7, false}, // row peer of R5C5
+		{1, 4, 7, false}, // column peer of R5C5
+		{8, 8, 5, true},  // no shared unit
+		{8, 8, 7, true},  // no shared unit
+	}
+	for _, tt := range tests {
+		if got := board.GetCellAt(tt.row, tt.col).HasCandidate(tt.value); got != tt.want {
+			t.Errorf("R%dC%d candidate %d: got %v, want %v", tt.row+1, tt.col+1, tt.value, got, tt.want)
+		}
+	}
+
+	if board.PropagateUniqueness() {
+		t.Error("a second pass should find nothing to eliminate")
+	}
+}
+
+// newFinnedSwordfishBoard builds a board without constraints where candidate 1 forms
+// a finned Swordfish in rows 1, 5 and 8 over columns 1, 5 and 8, with fins at R1C2
+// and R1C3 in box 1. Every other row keeps candidate 1 everywhere.
+func newFinnedSwordfishBoard() *lib.Board {
+	board := lib.NewBoard()
+	keep := map[int][]int{
+		0: {0, 1, 2, 4},
+		4: {4, 7},
+		7: {0, 7},
+	}
+	for row, cols := range keep {
+		for col := 0; col < 9; col++ {
+			board.GetCellAt(row, col).RemoveCandidate(1)
+		}
+		for _, col := range cols {
+			board.GetCellAt(row, col).AddCandidate(1)
+		}
+	}
+	return board
+}
+
+func TestBoardFinnedSwordfish(t *testing.T) {
+	board := newFinnedSwordfishBoard()
+
+	if !board.ApplyAdvancedTechniques() {
+		t.Fatal("ApplyAdvancedTechniques() should find the finned Swordfish")
+	}
+
+	// If the fins are empty, the Swordfish removes 1 from the rest of column 1.
+	// If a fin holds 1, box 1 cannot have another. R2C1 and R3C1 lose 1 either way.
+	tests := []struct {
+		row, col int
+		want     bool
+	}{
+		{1, 0, false},
+		{2, 0, false},
+		{3, 0, true}, // Column 1 but outside the fin box
+		{8, 0, true},
+		{1, 4, true}, // Column 5 does not reach box 1
+		{1, 1, true}, // Box 1 but not a cover column
+		{0, 1, true}, // The fins themselves stay
+		{0, 0, true},
+	}
+	for _, tt := range tests {
+		if got := board.GetCellAt(tt.row, tt.col).HasCandidate(1); got != tt.want {
+			t.Errorf("R%dC%d has candidate 1 = %v, want %v", tt.row+1, tt.col+1, got, tt.want)
+		}
+	}
+}
+
+// newFinnedXWingBoard leaves candidate 5 in R1C3 and R1C8, and in R5C3, R5C8 and R5C9.
+// Without the fin in R5C9 this would be an X-Wing on columns 3 and 8.
+func newFinnedXWingBoard() *lib.Board {
+	board := lib.NewBoard()
+	keep := map[int][]int{
+		0: {2, 7},
+		4: {2, 7, 8},
+	}
+	for row, cols := range keep {
+		for col := 0; col < 9; col++ {
+			board.GetCellAt(row, col).RemoveCandidate(5)
+		}
+		for _, col := range cols {
+			board.GetCellAt(row, col).AddCandidate(5)
+		}
+	}
+	return board
+}
+
+func TestBoardFinnedXWing(t *testing.T) {
+	board := newFinnedXWingBoard()
+
+	if got := board.FindXWingEliminations(); len(got) != 0 {
+		t.Errorf("plain X-Wing should not apply with a fin, got %+v", got)
+	}
+
+	// Either R5C9 holds 5, or the X-Wing removes 5 from the rest of columns 3 and 8.
+	// Only R4C8 and R6C8 see both the fin and the X-Wing.
+	var got []int
+	for _, e := range board.FindFinnedFishEliminations() {
+		if e.Candidate != 5 {
+			t.Errorf("unexpected elimination %+v", e)
+		}
+		got = append(got, e.CellIndex)
+	}
+	if want := []int{34, 52}; !reflect.DeepEqual(got, want) {
+		t.Errorf("finned X-Wing eliminates 5 from cells %v, want %v", got, want)
+	}
+
+	if err := board.SetFinnedFishSizes(3, 4); err != nil {
+		t.Fatalf("SetFinnedFishSizes() returned error: %v", err)
+	}
+	if got := board.FindFinnedFishEliminations(); len(got) != 0 {
+		t.Errorf("finned X-Wing should not apply when size 2 is not configured, got %+v", got)
+	}
+}
+
+func TestBoardFinnedFishSizes(t *testing.T) {
+	board := newFinnedSwordfishBoard()
+
+	if got := board.FinnedFishSizes(); !reflect.DeepEqual(got, []int{2, 3, 4}) {
+		t.Errorf("default FinnedFishSizes() = %v, want [2 3 4]", got)
+	}
+	if err := board.SetFinnedFishSizes(5); err == nil {
+		t.Error("expected error for finned fish size 5, got none")
+	}
+
+	if err := board.SetFinnedFishSizes(); err != nil {
+		t.Fatalf("SetFinnedFishSizes() returned error: %v", err)
+	}
+	if board.ApplyAdvancedTechniques() {
+		t.Error("ApplyAdvancedTechniques() should find nothing with finned fish disabled")
+	}
+	if !board.GetCellAt(1, 0).HasCandidate(1) {
+		t.Error("R2C1 should keep candidate 1 with finned fish disabled")
+	}
+}
+
+func TestBoardApplyAdvancedTechniquesWithOptions(t *testing.T) {
+	finnedOnly := lib.TechniqueOptions{FinnedFish: true}
+	xWingsOnly := lib.TechniqueOptions{FinnedFish: true, MaxFishSize: 2}
+	noFinnedFish := lib.AllTechniques()
+	noFinnedFish.FinnedFish = false
+
+	// The finned Swordfish removes 1 from R2C1, the finned X-Wing 5 from R4C8
+	tests := []struct {
+		name  string
+		board func() *lib.Board
+		cell  [2]int
+		digit int
+		opts  lib.TechniqueOptions
+		want  bool // Whether the elimination is made
+	}{
+		{"all techniques", newFinnedSwordfishBoard, [2]int{1, 0}, 1, lib.AllTechniques(), true},
+		{"none", newFinnedSwordfishBoard, [2]int{1, 0}, 1, lib.TechniqueOptions{}, false},
+		{"finned fish only", newFinnedSwordfishBoard, [2]int{1, 0}, 1, finnedOnly, true},
+		{"finned fish off", newFinnedSwordfishBoard, [2]int{1, 0}, 1, noFinnedFish, false},
+		{"swordfish above the cap", newFinnedSwordfishBoard, [2]int{1, 0}, 1, xWingsOnly, false},
+		{"x-wing within the cap", newFinnedXWingBoard, [2]int{3, 7}, 5, xWingsOnly, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := tt.board()
+
+			if got := board.ApplyAdvancedTechniquesWithOptions(tt.opts); got != tt.want {
+				t.Errorf("ApplyAdvancedTechniquesWithOptions() = %v, want %v", got, tt.want)
+			}
+			if got := !board.GetCellAt(tt.cell[0], tt.cell[1]).HasCandidate(tt.digit); got != tt.want {
+				t.Errorf("R%dC%d lost candidate %d = %v, want %v", tt.cell[0]+1, tt.cell[1]+1, tt.digit, got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkApplyPencilMarkConstraintsUntilStable(b *testing.B) {
+	level := logger.GetLevel()
+	logger.SetLevel(logger.ERROR)
+	defer logger.SetLevel(level)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		board := lib.NewBoard()
+		if err := constraints.AddStandardConstraints(board); err != nil {
+			b.Fatalf("failed to add standard constraints: %v", err)
+		}
+		if err := board.LoadString(xWingPuzzle); err != nil {
+			b.Fatalf("LoadString() returned error: %v", err)
+		}
+		b.StartTimer()
+
+		board.ApplyPencilMarkConstraintsUntilStable()
+	}
+}
+
+// newBenchmarkBoard builds a solved board with 30 constraints: the 27 standard ones plus
+// three killer cages
+func newBenchmarkBoard(b *testing.B) *lib.Board {
+	board := lib.NewBoard()
+	if err := constraints.AddStandardConstraints(board); err != nil {
+		b.Fatalf("failed to add standard constraints: %v", err)
+	}
+	for _, cells := range [][]int{{0, 1, 2}, {40, 41, 49}, {78, 79, 80}} {
+		sum := 0
+		for _, idx := range cells {
+			sum += int(bugPlusOneSolution[idx] - '0')
+		}
+		cage, err := constraints.NewKillerCageConstraint(cells, sum)
+		if err != nil {
+			b.Fatalf("failed to create killer cage: %v", err)
+		}
+		board.AddConstraint(cage)
+	}
+	if err := board.LoadString(bugPlusOneSolution); err != nil {
+		b.Fatalf("LoadString() returned error: %v", err)
+	}
+	if n := len(board.GetConstraints()); n != 30 {
+		b.Fatalf("benchmark board has %d constraints, want 30", n)
+	}
+	return board
+}
+
+// BenchmarkApplyXYWings runs the peer-heavy XY-Wing search on a standard board, which
+// looks up the visible cells of every bivalue pivot
+func BenchmarkApplyXYWings(b *testing.B) {
+	level := logger.GetLevel()
+	logger.SetLevel(logger.ERROR)
+	defer logger.SetLevel(level)
+
+	board := lib.NewBoard()
+	if err := constraints.AddStandardConstraints(board); err != nil {
+		b.Fatalf("failed to add standard constraints: %v", err)
+	}
+	if err := board.LoadString(xWingPuzzle); err != nil {
+		b.Fatalf("LoadString() returned error: %v", err)
+	}
+	board.ApplyPencilMarkConstraintsUntilStable()
+	state := board.Snapshot()
+	opts := lib.TechniqueOptions{XYWing: true}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		board.ApplyAdvancedTechniquesWithOptions(opts)
+
+		b.StopTimer()
+		board.Restore(state)
+		b.StartTimer()
+	}
+}
+
+func BenchmarkValidateAll(b *testing.B) {
+	level := logger.GetLevel()
+	logger.SetLevel(logger.ERROR)
+	defer logger.SetLevel(level)
+
+	board := newBenchmarkBoard(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		board.ValidateAll()
+	}
+}
+
+func BenchmarkValidateAllConcurrent(b *testing.B) {
+	level := logger.GetLevel()
+	logger.SetLevel(logger.ERROR)
+	defer logger.SetLevel(level)
+
+	board := newBenchmarkBoard(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		board.ValidateAllConcurrent()
+	}
+}
+
+func TestBoardFindXWingEliminations(t *testing.T) {
+	board := newStandardBoard(t)
+
+	// 5 is left only in columns 1 and 5 of rows 1 and 5
+	for _, row := range []int{0, 4} {
+		for col := 0; col < 9; col++ {
+			if col != 0 && col != 4 {
+				board.GetCellAt(row, col).RemoveCandidate(5)
+			}
+		}
+	}
+	mock := &MockObserver{}
+	board.AddObserver(mock)
+
+	eliminations := board.FindXWingEliminations()
+	if len(eliminations) != 14 {
+		t.Fatalf("expected 14 eliminations, got %d: %+v", len(eliminations), eliminations)
+	}
+	for _, e := range eliminations {
+		if e.Candidate != 5 || e.Technique != "X-Wing" || e.Reason == "" {
+			t.Errorf("unexpected elimination %+v", e)
+		}
+		if col := e.CellIndex % 9; col != 0 && col != 4 {
+			t.Errorf("elimination outside the X-Wing columns: %+v", e)
+		}
+		if !board.GetCell(e.CellIndex).HasCandidate(5) {
+			t.Errorf("dry run removed 5 from cell %d", e.CellIndex)
+		}
+	}
+	if len(mock.candidateEliminatedCalls) != 0 {
+		t.Errorf("dry run notified the board's observers %d times", len(mock.candidateEliminatedCalls))
+	}
+
+	if next := board.FindNextEliminations(); !reflect.DeepEqual(next, eliminations) {
+		t.Errorf("FindNextEliminations() = %+v, want the X-Wing eliminations", next)
+	}
+	if none := newStandardBoard(t).FindNextEliminations(); len(none) != 0 {
+		t.Errorf("an empty board should have no eliminations, got %+v", none)
+	}
+}
+
+// newPointingBoard returns an empty standard board where 1 in box 1 is confined to row
+// 1 and 2 in box 5 is confined to column 5
+func newPointingBoard(t *testing.T) *lib.Board {
+	t.Helper()
+	board := newStandardBoard(t)
+	for _, idx := range []int{9, 10, 11, 18, 19, 20} {
+		board.GetCell(idx).RemoveCandidate(1)
+	}
+	for row := 3; row <= 5; row++ {
+		board.GetCellAt(row, 3).RemoveCandidate(2)
+		board.GetCellAt(row, 5).RemoveCandidate(2)
+	}
+	return board
+}
+
+func TestBoardEliminationReasons(t *testing.T) {
+	t.Run("intersection removal", func(t *testing.T) {
+		eliminations := newPointingBoard(t).FindIntersectionEliminations()
+		if len(eliminations) != 12 {
+			t.Fatalf("expected 12 eliminations, got %d: %+v", len(eliminations), eliminations)
+		}
+		for _, e := range eliminations {
+			want := "1 in Box 1 is confined to Row 1, removed from the rest of Row 1"
+			inPattern := e.CellIndex/9 == 0
+			if e.Candidate == 2 {
+				want = "2 in Box 5 is confined to Column 5, removed from the rest of Column 5"
+				inPattern = e.CellIndex%9 == 4
+			}
+			if e.Technique != "Intersection Removal" || e.Reason != want || !inPattern {
+				t.Errorf("elimination %+v, want reason %q", e, want)
+			}
+		}
+	})
+
+	t.Run("W-Wing", func(t *testing.T) {
+		board := newStandardBoard(t)
+		for candidate := 3; candidate <= 9; candidate++ {
+			board.GetCellAt(0, 0).RemoveCandidate(candidate)
+			board.GetCellAt(4, 2).RemoveCandidate(candidate)
+		}
+		for col := 0; col < 9; col++ {
+			if col != 0 && col != 2 {
+				board.GetCellAt(8, col).RemoveCandidate(1)
+			}
+		}
+
+		eliminations := board.FindWWingEliminations()
+		if len(eliminations) != 6 {
+			t.Fatalf("expected 6 eliminations, got %d: %+v", len(eliminations), eliminations)
+		}
+		want := "Found W-Wing: R1C1 and R5C3 {1,2}, strong link on 1 between R9C1 and R9C3, eliminating 2"
+		for _, e := range eliminations {
+			if e.Candidate != 2 || e.Technique != "W-Wing" || e.Reason != want {
+				t.Errorf("elimination %+v, want reason %q", e, want)
+			}
+		}
+	})
+
+	t.Run("hint explanation", func(t *testing.T) {
+		board := newPointingBoard(t)
+		hint, found := board.NextHint()
+		if !found || hint.Technique != "Intersection Removal" {
+			t.Fatalf("NextHint() = %+v, %v, want an intersection removal", hint, found)
+		}
+		for _, e := range hint.Eliminations {
+			if e.Reason != hint.Explanation {
+				t.Errorf("elimination %+v does not match the explanation %q", e, hint.Explanation)
+			}
+		}
+		if want := fmt.Sprintf("%d in Box ", hint.Candidates[0]); len(hint.Candidates) != 1 ||
+			!strings.HasPrefix(hint.Explanation, want) {
+			t.Errorf("explanation %q should describe the pattern removing %v", hint.Explanation, hint.Candidates)
+		}
+	})
+}
+
+func TestBoardSetMany(t *testing.T) {
+	board := newStandardBoard(t)
+	mock := &MockObserver{}
+	board.AddObserver(mock)
+
+	err := board.SetMany(map[int]int{0: 5, 1: 3, 4: 7, 90: 1, 9: 12, -1: 2})
+	if err == nil {
+		t.Fatal("expected an error for the bad indices and the bad value")
+	}
+	for _, part := range []string{"cell 90: invalid cell index", "cell -1: invalid cell index", "cell 9: value must be between 0 and 9"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("error %q should mention %q", err, part)
+		}
+	}
+	var boardErr *lib.BoardError
+	if !errors.As(err, &boardErr) {
+		t.Error("errors.As should find a BoardError in the aggregated error")
+	}
+
+	// The valid values are placed despite the failures, with propagation per cell
+	for idx, want := range map[int]int{0: 5, 1: 3, 4: 7} {
+		if got := board.Get(idx/9, idx%9); got != want {
+			t.Errorf("cell %d = %d, want %d", idx, got, want)
+		}
+	}
+	if len(mock.cellSolvedCalls) < 3 {
+		t.Errorf("expected a solved notification per placed value, got %d", len(mock.cellSolvedCalls))
+	}
+	if board.GetCell(2).HasCandidate(3) || board.GetCell(2).HasCandidate(5) {
+		t.Error("placed values should propagate to their peers")
+	}
+
+	if err := newStandardBoard(t).SetMany(map[int]int{0: 1, 80: 9}); err != nil {
+		t.Errorf("SetMany() with valid values returned error: %v", err)
+	}
+}
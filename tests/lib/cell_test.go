@@ -1,9 +1,12 @@
 package lib_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+	"github.com/eftil/sudoku-solver.git/lib/utils"
 )
 
 func TestNewCell(t *testing.T) {
@@ -271,6 +274,44 @@ func TestCellGetCandidates(t *testing.T) {
 	}
 }
 
+func TestCellCandidatesSlice(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove []int
+		value  int
+		want   []int
+	}{
+		{"all candidates", nil, 0, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{"some removed", []int{1, 4, 9}, 0, []int{2, 3, 5, 6, 7, 8}},
+		{"solved cell", nil, 5, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			// Ascending regardless of the display order
+			if err := board.SetCandidateOrder([]int{9, 8, 7, 6, 5, 4, 3, 2, 1}); err != nil {
+				t.Fatalf("SetCandidateOrder() returned error: %v", err)
+			}
+			cell := board.GetCell(0)
+			for _, candidate := range tt.remove {
+				cell.RemoveCandidate(candidate)
+			}
+			if tt.value != 0 {
+				cell.SetValue(tt.value)
+			}
+
+			got := cell.SortedCandidates()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortedCandidates() = %v, want %v", got, tt.want)
+			}
+			if want := utils.GetCandidatesAsSlice(cell.GetCandidates()); !reflect.DeepEqual(got, want) {
+				t.Errorf("SortedCandidates() = %v, GetCandidatesAsSlice(GetCandidates()) = %v", got, want)
+			}
+		})
+	}
+}
+
 func TestCellIndex(t *testing.T) {
 	board := lib.NewBoard()
 
@@ -295,3 +336,243 @@ func TestCellIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestCellCanSee(t *testing.T) {
+	board := newStandardBoard(t)
+	whispers, err := constraints.NewGermanWhispersConstraint([]int{0, 80})
+	if err != nil {
+		t.Fatalf("failed to create whispers: %v", err)
+	}
+	board.AddConstraint(whispers)
+
+	cell := board.GetCellAt(0, 0)
+	tests := []struct {
+		name  string
+		other *lib.Cell
+		want  bool
+	}{
+		{"same row", board.GetCellAt(0, 8), true},
+		{"same column", board.GetCellAt(8, 0), true},
+		{"same box", board.GetCellAt(2, 2), true},
+		{"no shared unit", board.GetCellAt(4, 4), false},
+		{"itself", cell, false},
+		{"only a non-uniqueness line", board.GetCellAt(8, 8), false},
+		{"nil", nil, false},
+		{"other board", newStandardBoard(t).GetCellAt(0, 1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cell.CanSee(tt.other); got != tt.want {
+				t.Errorf("CanSee() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCellCanSeeAfterConstraintChanges(t *testing.T) {
+	board := newStandardBoard(t)
+	a, b := board.GetCellAt(0, 0), board.GetCellAt(4, 4)
+
+	if a.CanSee(b) {
+		t.Fatal("R1C1 and R5C5 should not see each other")
+	}
+
+	cage, err := constraints.NewCageUniqueConstraint([]int{0, 40})
+	if err != nil {
+		t.Fatalf("failed to create cage: %v", err)
+	}
+	board.AddConstraint(cage)
+	if !a.CanSee(b) || !board.AreVisible(40, 0) {
+		t.Error("cells in the same cage should see each other")
+	}
+
+	board.RemoveConstraint(cage)
+	if a.CanSee(b) {
+		t.Error("cells should stop seeing each other once the cage is removed")
+	}
+}
+
+func TestCellMarkGiven(t *testing.T) {
+	board := lib.NewBoard()
+	cell := board.GetCellAt(0, 0)
+
+	cell.MarkGiven()
+	if cell.IsGiven() {
+		t.Error("an empty cell should not become a given")
+	}
+
+	cell.SetValue(5)
+	cell.MarkGiven()
+	if !cell.IsGiven() {
+		t.Error("expected the cell to be a given after MarkGiven()")
+	}
+	if board.GetCellAt(0, 1).IsGiven() {
+		t.Error("other cells should not be givens")
+	}
+}
+
+// eliminationConstraint records the eliminations reported to its override
+type eliminationConstraint struct {
+	lib.BaseConstraint
+	eliminated []int
+}
+
+func (ec *eliminationConstraint) IsValid(board *lib.Board) (bool, error) { return true, nil }
+func (ec *eliminationConstraint) GetDescription() string                 { return "records eliminations" }
+
+func (ec *eliminationConstraint) OnCandidateEliminated(row, col, candidate, remainingCount int) {
+	ec.eliminated = append(ec.eliminated, candidate)
+}
+
+func TestCellRemoveCandidates(t *testing.T) {
+	board := lib.NewBoard()
+	cell := board.GetCellAt(0, 0)
+	mock := &MockObserver{}
+	cell.AddObserver(mock)
+	ec := &eliminationConstraint{BaseConstraint: lib.BaseConstraint{Cells: []int{0}, Name: "Eliminations"}}
+	board.AddConstraint(ec)
+
+	// Repeated and out-of-range digits are ignored
+	cell.RemoveCandidates([]int{3, 1, 2, 3, 12})
+	if got := cell.SortedCandidates(); !reflect.DeepEqual(got, []int{4, 5, 6, 7, 8, 9}) {
+		t.Fatalf("candidates = %v, want [4 5 6 7 8 9]", got)
+	}
+	if len(mock.candidateEliminatedCalls) != 3 {
+		t.Fatalf("Expected 3 OnCandidateEliminated calls, got %d", len(mock.candidateEliminatedCalls))
+	}
+	for _, call := range mock.candidateEliminatedCalls {
+		if call.remainingCount != 6 {
+			t.Errorf("OnCandidateEliminated(%d) reported %d remaining, want the final 6", call.candidate, call.remainingCount)
+		}
+	}
+	if !reflect.DeepEqual(ec.eliminated, []int{1, 2, 3}) {
+		t.Errorf("constraint override saw %v, want [1 2 3]", ec.eliminated)
+	}
+
+	// Down to one candidate: a single notification, not one per step
+	cell.RemoveCandidates([]int{4, 5, 6, 7, 8})
+	if len(mock.singleCandidateCalls) != 1 || mock.singleCandidateCalls[0].candidate != 9 {
+		t.Errorf("single candidate calls = %+v, want one for 9", mock.singleCandidateCalls)
+	}
+
+	// Nothing left to remove, or a solved cell: no notifications
+	before := len(mock.candidateEliminatedCalls)
+	cell.RemoveCandidates([]int{1, 2})
+	cell.SetValue(9)
+	cell.RemoveCandidates([]int{9})
+	if len(mock.candidateEliminatedCalls) != before {
+		t.Errorf("got %d more OnCandidateEliminated calls, want none", len(mock.candidateEliminatedCalls)-before)
+	}
+}
+
+func TestCellRemoveCandidateReversible(t *testing.T) {
+	board := lib.NewBoard()
+	cell := lib.NewCell(2, 3, board)
+	for i := 1; i <= 7; i++ {
+		cell.RemoveCandidate(i)
+	}
+
+	mock := &MockObserver{}
+	cell.AddObserver(mock)
+
+	// Leaves a single candidate, which is reported once
+	restore := cell.RemoveCandidateReversible(8)
+	if cell.HasCandidate(8) {
+		t.Fatal("candidate 8 should be removed")
+	}
+	if len(mock.candidateEliminatedCalls) != 1 || len(mock.singleCandidateCalls) != 1 {
+		t.Fatalf("removal should notify once each, got %d eliminated and %d single",
+			len(mock.candidateEliminatedCalls), len(mock.singleCandidateCalls))
+	}
+
+	restore()
+	if !cell.HasCandidate(8) || cell.CandidateCount() != 2 {
+		t.Errorf("restore should bring back candidate 8, got %v", cell.CandidateSlice())
+	}
+	if len(mock.candidateEliminatedCalls) != 1 || len(mock.singleCandidateCalls) != 1 {
+		t.Error("restore should not fire any notifications")
+	}
+
+	// Restoring twice or after the cell is solved changes nothing
+	restore()
+	if cell.CandidateCount() != 2 {
+		t.Errorf("second restore changed the candidates to %v", cell.CandidateSlice())
+	}
+	restore = cell.RemoveCandidateReversible(9)
+	cell.SetValue(8)
+	restore()
+	if cell.HasCandidate(9) {
+		t.Error("restore should not add candidates to a solved cell")
+	}
+
+	// Removing a missing candidate returns a no-op
+	other := lib.NewCell(0, 0, board)
+	other.RemoveCandidate(5)
+	other.RemoveCandidateReversible(5)()
+	if other.HasCandidate(5) {
+		t.Error("restoring a candidate that was never removed should do nothing")
+	}
+}
+
+func TestCellSetCandidates(t *testing.T) {
+	board := lib.NewBoard()
+	cell := lib.NewCell(4, 4, board)
+	mock := &MockObserver{}
+	cell.AddObserver(mock)
+
+	if err := cell.SetCandidates([]int{2, 5, 5, 7}); err != nil {
+		t.Fatalf("SetCandidates() returned error: %v", err)
+	}
+	if got := cell.SortedCandidates(); !reflect.DeepEqual(got, []int{2, 5, 7}) {
+		t.Errorf("candidates = %v, want [2 5 7]", got)
+	}
+	if len(mock.candidateEliminatedCalls) != 6 || len(mock.singleCandidateCalls) != 0 {
+		t.Errorf("expected 6 eliminations and no single candidate, got %d and %d",
+			len(mock.candidateEliminatedCalls), len(mock.singleCandidateCalls))
+	}
+
+	// Adding 9 back is silent, removing 2, 5 and 7 leaves a single candidate
+	mock = &MockObserver{}
+	cell.GetNotifier().ClearObservers()
+	cell.AddObserver(mock)
+	if err := cell.SetCandidates([]int{9}); err != nil {
+		t.Fatalf("SetCandidates() returned error: %v", err)
+	}
+	if got := cell.SortedCandidates(); !reflect.DeepEqual(got, []int{9}) {
+		t.Errorf("candidates = %v, want [9]", got)
+	}
+	if len(mock.candidateEliminatedCalls) != 3 {
+		t.Errorf("expected 3 eliminations, got %d", len(mock.candidateEliminatedCalls))
+	}
+	if len(mock.singleCandidateCalls) != 1 || mock.singleCandidateCalls[0].candidate != 9 {
+		t.Errorf("expected one single candidate notification for 9, got %+v", mock.singleCandidateCalls)
+	}
+
+	// The same set again changes nothing and notifies nothing
+	if err := cell.SetCandidates([]int{9}); err != nil {
+		t.Fatalf("SetCandidates() returned error: %v", err)
+	}
+	if len(mock.candidateEliminatedCalls) != 3 || len(mock.singleCandidateCalls) != 1 {
+		t.Error("setting the same candidates should not notify")
+	}
+
+	for _, cands := range [][]int{nil, {0, 3}, {10}} {
+		if err := cell.SetCandidates(cands); err == nil {
+			t.Errorf("SetCandidates(%v) should fail", cands)
+		}
+	}
+	if got := cell.SortedCandidates(); !reflect.DeepEqual(got, []int{9}) {
+		t.Errorf("a failed SetCandidates changed the candidates to %v", got)
+	}
+
+	cell.SetValue(9)
+	if err := cell.SetCandidates([]int{1, 2}); err == nil {
+		t.Error("SetCandidates on a solved cell should fail")
+	}
+
+	small, _ := lib.NewBoardOfSize(4)
+	if err := small.GetCellAt(0, 0).SetCandidates([]int{1, 5}); err == nil {
+		t.Error("SetCandidates should reject digits beyond the board size")
+	}
+}
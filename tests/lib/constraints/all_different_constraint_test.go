@@ -0,0 +1,158 @@
+package constraints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewAllDifferentConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		cells     []int
+		shouldErr bool
+	}{
+		{"valid pair", []int{0, 40}, false},
+		{"valid nine cells", []int{0, 10, 20, 30, 40, 50, 60, 70, 80}, false},
+		{"single cell", []int{40}, true},
+		{"empty cells", []int{}, true},
+		{"too many cells", []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, true},
+		{"duplicate cell", []int{0, 1, 0}, true},
+		{"invalid cell index negative", []int{0, -1}, true},
+		{"invalid cell index too large", []int{0, 81}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad, err := constraints.NewAllDifferentConstraint(tt.cells)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !ad.RequiresUniqueness() {
+				t.Error("all-different group should require uniqueness")
+			}
+		})
+	}
+}
+
+func TestAllDifferentConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []int
+		wantValid bool
+	}{
+		{"empty group", []int{0, 0, 0}, true},
+		{"unique values", []int{3, 8, 1}, true},
+		{"partial unique values", []int{5, 0, 2}, true},
+		{"duplicate values", []int{7, 0, 7}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad, err := constraints.NewAllDifferentConstraint([]int{0, 40, 80})
+			if err != nil {
+				t.Fatalf("failed to create constraint: %v", err)
+			}
+
+			board := lib.NewBoard()
+			for i, cellIdx := range ad.GetCells() {
+				board.Set(cellIdx/9, cellIdx%9, tt.values[i])
+			}
+
+			valid, err := ad.IsValid(board)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if valid != tt.wantValid {
+				t.Errorf("IsValid() = %v, want %v for values %v", valid, tt.wantValid, tt.values)
+			}
+		})
+	}
+
+	ad, _ := constraints.NewAllDifferentConstraint([]int{0, 1})
+	if _, err := ad.IsValid(nil); err == nil {
+		t.Error("expected error for nil board, got none")
+	}
+}
+
+func TestAllDifferentConstraintPropagation(t *testing.T) {
+	ad, err := constraints.NewAllDifferentConstraint([]int{0, 40, 80})
+	if err != nil {
+		t.Fatalf("failed to create constraint: %v", err)
+	}
+
+	board := lib.NewBoard()
+	board.AddConstraint(ad)
+	board.Set(0, 0, 4)
+
+	for _, idx := range []int{40, 80} {
+		if board.GetCell(idx).HasCandidate(4) {
+			t.Errorf("cell %d shares the group with R1C1 and should not have candidate 4", idx)
+		}
+	}
+	if !board.GetCell(30).HasCandidate(4) {
+		t.Error("cell outside the group should keep candidate 4")
+	}
+}
+
+func TestAllDifferentConstraintNakedPair(t *testing.T) {
+	ad, err := constraints.NewAllDifferentConstraint([]int{0, 40, 80})
+	if err != nil {
+		t.Fatalf("failed to create constraint: %v", err)
+	}
+
+	board := lib.NewBoard()
+	board.AddConstraint(ad)
+	for _, idx := range []int{0, 40} {
+		cell := board.GetCell(idx)
+		for digit := 3; digit <= 9; digit++ {
+			cell.RemoveCandidate(digit)
+		}
+	}
+
+	if !ad.ApplyPencilMarkConstraints(board) {
+		t.Fatal("ApplyPencilMarkConstraints() = false, want the naked pair {1,2} to eliminate")
+	}
+	if got := fmt.Sprint(board.GetCell(80).SortedCandidates()); got != "[3 4 5 6 7 8 9]" {
+		t.Errorf("R9C9 candidates = %s, want [3 4 5 6 7 8 9]", got)
+	}
+}
+
+func TestAllDifferentConstraintJSONRoundTrip(t *testing.T) {
+	board := lib.NewBoard()
+	ad, err := constraints.NewAllDifferentConstraint([]int{2, 33, 64})
+	if err != nil {
+		t.Fatalf("NewAllDifferentConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(ad)
+
+	data, err := json.Marshal(board)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	loaded, err := lib.LoadBoardJSON(data)
+	if err != nil {
+		t.Fatalf("LoadBoardJSON() returned error: %v", err)
+	}
+
+	got := loaded.GetConstraints()
+	if len(got) != 1 {
+		t.Fatalf("loaded %d constraints, want 1", len(got))
+	}
+	loadedAD, ok := got[0].(*constraints.AllDifferentConstraint)
+	if !ok {
+		t.Fatalf("loaded %T, want *constraints.AllDifferentConstraint", got[0])
+	}
+	if fmt.Sprint(loadedAD.GetCells()) != "[2 33 64]" {
+		t.Errorf("loaded cells = %v, want [2 33 64]", loadedAD.GetCells())
+	}
+}
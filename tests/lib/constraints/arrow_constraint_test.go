@@ -0,0 +1,181 @@
+package constraints_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewArrowConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		bulb      []int
+		shaft     []int
+		shouldErr bool
+	}{
+		{"single bulb", []int{0}, []int{1, 2}, false},
+		{"two-digit bulb", []int{0, 1}, []int{2, 3, 4}, false},
+		{"no bulb", []int{}, []int{1}, true},
+		{"three-cell bulb", []int{0, 1, 2}, []int{3}, true},
+		{"no shaft", []int{0}, []int{}, true},
+		{"bulb cell on the shaft", []int{0}, []int{1, 0}, true},
+		{"invalid cell index", []int{0}, []int{81}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ac, err := constraints.NewArrowConstraint(tt.bulb, tt.shaft)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(ac.GetCells()) != len(tt.bulb)+len(tt.shaft) {
+				t.Errorf("GetCells() has %d cells, want %d", len(ac.GetCells()), len(tt.bulb)+len(tt.shaft))
+			}
+			if ac.RequiresUniqueness() {
+				t.Error("arrow should not require uniqueness")
+			}
+		})
+	}
+}
+
+func TestArrowConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		bulb   []int
+		shaft  []int
+		values map[int]int
+		want   bool
+	}{
+		{"empty arrow", []int{0}, []int{1, 2}, nil, true},
+		{"complete and correct", []int{0}, []int{1, 2}, map[int]int{0: 7, 1: 3, 2: 4}, true},
+		{"complete and wrong", []int{0}, []int{1, 2}, map[int]int{0: 7, 1: 3, 2: 5}, false},
+		{"partial shaft already too big", []int{0}, []int{1, 2}, map[int]int{0: 5, 1: 5}, false},
+		{"partial shaft still fits", []int{0}, []int{1, 2}, map[int]int{0: 5, 1: 4}, true},
+		{"shaft too big for any single digit", []int{0}, []int{1, 2}, map[int]int{1: 5, 2: 6}, false},
+		{"two-digit bulb correct", []int{0, 1}, []int{2, 3}, map[int]int{0: 1, 1: 5, 2: 7, 3: 8}, true},
+		{"two-digit bulb wrong", []int{0, 1}, []int{2, 3}, map[int]int{0: 1, 1: 5, 2: 7, 3: 9}, false},
+		{"two-digit bulb too big for the shaft", []int{0, 1}, []int{2, 3}, map[int]int{0: 2, 2: 9, 3: 9}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			ac, err := constraints.NewArrowConstraint(tt.bulb, tt.shaft)
+			if err != nil {
+				t.Fatalf("NewArrowConstraint() returned error: %v", err)
+			}
+			for idx, value := range tt.values {
+				board.Set(idx/9, idx%9, value)
+			}
+
+			got, err := ac.IsValid(board)
+			if err != nil {
+				t.Fatalf("IsValid() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArrowConstraintIsValidNilBoard(t *testing.T) {
+	ac, err := constraints.NewArrowConstraint([]int{0}, []int{1})
+	if err != nil {
+		t.Fatalf("NewArrowConstraint() returned error: %v", err)
+	}
+	if _, err := ac.IsValid(nil); err == nil {
+		t.Error("expected error for nil board, got none")
+	}
+}
+
+func TestArrowConstraintPropagateValueChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		bulb   []int
+		shaft  []int
+		values map[int]int
+		want   map[int]string
+	}{
+		{
+			name:   "bulb bounds the shaft",
+			bulb:   []int{0},
+			shaft:  []int{1, 2},
+			values: map[int]int{0: 5},
+			want:   map[int]string{1: "[1 2 3 4]", 2: "[1 2 3 4]"},
+		},
+		{
+			name:   "shaft cell bounds the rest",
+			bulb:   []int{0},
+			shaft:  []int{1, 2},
+			values: map[int]int{1: 6},
+			want:   map[int]string{0: "[7 8 9]", 2: "[1 2 3]"},
+		},
+		{
+			name:   "last shaft cell is forced",
+			bulb:   []int{0},
+			shaft:  []int{1, 2},
+			values: map[int]int{0: 9, 1: 4},
+			want:   map[int]string{2: "[5]"},
+		},
+		{
+			name:   "two-digit bulb tens digit",
+			bulb:   []int{0, 1},
+			shaft:  []int{2, 3},
+			values: map[int]int{2: 9},
+			want:   map[int]string{0: "[1]", 1: "[1 2 3 4 5 6 7 8]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			ac, err := constraints.NewArrowConstraint(tt.bulb, tt.shaft)
+			if err != nil {
+				t.Fatalf("NewArrowConstraint() returned error: %v", err)
+			}
+			board.AddConstraint(ac)
+			for idx, value := range tt.values {
+				board.Set(idx/9, idx%9, value)
+			}
+
+			for idx, want := range tt.want {
+				if got := fmt.Sprint(board.GetCell(idx).CandidateSlice()); got != want {
+					t.Errorf("cell %d candidates = %s, want %s", idx, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestArrowConstraintViolations(t *testing.T) {
+	board := lib.NewBoard()
+	ac, err := constraints.NewArrowConstraint([]int{0}, []int{1, 2})
+	if err != nil {
+		t.Fatalf("NewArrowConstraint() returned error: %v", err)
+	}
+	board.Set(0, 0, 7)
+	board.Set(0, 1, 3)
+	board.Set(0, 2, 5)
+
+	violations := ac.Violations(board)
+	if len(violations) != 1 {
+		t.Fatalf("got %d violations, want 1", len(violations))
+	}
+	if v := violations[0]; v.Value != 8 || len(v.Cells) != 3 {
+		t.Errorf("violation = %+v, want value 8 over 3 cells", v)
+	}
+
+	board.Set(0, 2, 4)
+	if violations := ac.Violations(board); len(violations) != 0 {
+		t.Errorf("expected no violations on a correct arrow, got %+v", violations)
+	}
+}
@@ -0,0 +1,107 @@
+package constraints_test
+
+import (
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewCageUniqueConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		cells     []int
+		shouldErr bool
+	}{
+		{"valid cage", []int{0, 1, 9}, false},
+		{"valid single cell", []int{40}, false},
+		{"empty cells", []int{}, true},
+		{"too many cells", []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, true},
+		{"duplicate cell", []int{0, 1, 0}, true},
+		{"invalid cell index negative", []int{0, -1}, true},
+		{"invalid cell index too large", []int{0, 81}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cu, err := constraints.NewCageUniqueConstraint(tt.cells)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if cu == nil {
+				t.Errorf("expected constraint but got nil")
+				return
+			}
+			if !cu.RequiresUniqueness() {
+				t.Error("cage should require uniqueness")
+			}
+		})
+	}
+}
+
+func TestCageUniqueConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []int
+		wantValid bool
+	}{
+		{"empty cage", []int{0, 0, 0}, true},
+		{"unique values with any sum", []int{9, 8, 7}, true},
+		{"partial unique values", []int{1, 0, 2}, true},
+		{"duplicate values", []int{4, 0, 4}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cu, err := constraints.NewCageUniqueConstraint([]int{0, 1, 9})
+			if err != nil {
+				t.Fatalf("failed to create constraint: %v", err)
+			}
+
+			board := lib.NewBoard()
+			for i, cellIdx := range cu.GetCells() {
+				board.Set(cellIdx/9, cellIdx%9, tt.values[i])
+			}
+
+			valid, err := cu.IsValid(board)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if valid != tt.wantValid {
+				t.Errorf("IsValid() = %v, want %v for values %v", valid, tt.wantValid, tt.values)
+			}
+		})
+	}
+
+	cu, _ := constraints.NewCageUniqueConstraint([]int{0, 1})
+	if _, err := cu.IsValid(nil); err == nil {
+		t.Error("expected error for nil board, got none")
+	}
+}
+
+func TestCageUniqueConstraintPropagation(t *testing.T) {
+	cu, err := constraints.NewCageUniqueConstraint([]int{0, 10, 20})
+	if err != nil {
+		t.Fatalf("failed to create constraint: %v", err)
+	}
+
+	board := lib.NewBoard()
+	board.AddConstraint(cu)
+	board.Set(0, 0, 6)
+
+	for _, idx := range []int{10, 20} {
+		if board.GetCell(idx).HasCandidate(6) {
+			t.Errorf("cell %d shares the cage with R1C1 and should not have candidate 6", idx)
+		}
+	}
+	if !board.GetCell(30).HasCandidate(6) {
+		t.Error("cell outside the cage should keep candidate 6")
+	}
+}
@@ -0,0 +1,126 @@
+package constraints_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewEvenOddConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		cells     []int
+		shouldErr bool
+	}{
+		{"single cell", []int{40}, false},
+		{"several cells", []int{0, 10, 20}, false},
+		{"no cells", nil, true},
+		{"invalid cell index", []int{0, 81}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, even := range []bool{true, false} {
+				newConstraint := constraints.NewOddConstraint
+				if even {
+					newConstraint = constraints.NewEvenConstraint
+				}
+				eo, err := newConstraint(tt.cells)
+				if tt.shouldErr {
+					if err == nil {
+						t.Errorf("expected error but got none")
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if eo.IsEven() != even {
+					t.Errorf("IsEven() = %v, want %v", eo.IsEven(), even)
+				}
+				if eo.RequiresUniqueness() {
+					t.Error("even/odd constraint should not require uniqueness")
+				}
+			}
+		})
+	}
+}
+
+func TestEvenOddConstraintPrunesOnAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		even bool
+		want string
+	}{
+		{"even", true, "[2 4 6 8]"},
+		{"odd", false, "[1 3 5 7 9]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			newConstraint := constraints.NewOddConstraint
+			if tt.even {
+				newConstraint = constraints.NewEvenConstraint
+			}
+			eo, err := newConstraint([]int{0, 1})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			board.AddConstraint(eo)
+
+			for _, idx := range []int{0, 1} {
+				if got := fmt.Sprint(board.GetCell(idx).CandidateSlice()); got != tt.want {
+					t.Errorf("cell %d candidates = %s, want %s", idx, got, tt.want)
+				}
+			}
+			if got := board.GetCell(2).CandidateCount(); got != 9 {
+				t.Errorf("cell outside the constraint has %d candidates, want 9", got)
+			}
+
+			// Rebuilding candidates keeps the restriction
+			board.RecomputeAllCandidates()
+			if got := fmt.Sprint(board.GetCell(0).CandidateSlice()); got != tt.want {
+				t.Errorf("after RecomputeAllCandidates() candidates = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvenOddConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[int]int
+		want   bool
+	}{
+		{"empty", nil, true},
+		{"even digits", map[int]int{0: 2, 1: 8}, true},
+		{"one odd digit", map[int]int{0: 2, 1: 5}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			eo, err := constraints.NewEvenConstraint([]int{0, 1})
+			if err != nil {
+				t.Fatalf("NewEvenConstraint() returned error: %v", err)
+			}
+			for idx, val := range tt.values {
+				board.Set(idx/9, idx%9, val)
+			}
+
+			got, err := eo.IsValid(board)
+			if err != nil {
+				t.Fatalf("IsValid() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+			if violations := eo.Violations(board); (len(violations) == 0) != tt.want {
+				t.Errorf("Violations() = %+v, want none only when valid", violations)
+			}
+		})
+	}
+}
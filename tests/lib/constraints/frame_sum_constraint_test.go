@@ -0,0 +1,159 @@
+package constraints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewFrameSumConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		cells     []int
+		sum       int
+		count     int
+		shouldErr bool
+	}{
+		{"left of a row", []int{9, 10, 11}, 6, 3, false},
+		{"right of a row, whole line", []int{17, 16, 15, 14, 13, 12, 11, 10, 9}, 17, 2, false},
+		{"top of a column", []int{4, 13, 22}, 24, 3, false},
+		{"bottom of a column", []int{76, 67}, 3, 2, false},
+		{"single edge cell", []int{8}, 9, 1, false},
+		{"count too small", []int{9, 10, 11}, 6, 0, true},
+		{"count too big", []int{9, 10, 11, 12}, 10, 4, true},
+		{"fewer cells than count", []int{9, 10}, 6, 3, true},
+		{"not from the edge", []int{10, 11, 12}, 6, 3, true},
+		{"moving toward the edge", []int{2, 1, 0}, 6, 3, true},
+		{"not straight", []int{0, 1, 10}, 6, 3, true},
+		{"single inner cell", []int{40}, 5, 1, true},
+		{"invalid cell index", []int{72, 81}, 5, 2, true},
+		{"sum too small", []int{9, 10, 11}, 5, 3, true},
+		{"sum too big", []int{9, 10, 11}, 25, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, err := constraints.NewFrameSumConstraint(tt.cells, tt.sum, tt.count)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(fs.GetCells()) != tt.count {
+				t.Errorf("GetCells() = %v, want the first %d cells", fs.GetCells(), tt.count)
+			}
+			if fs.RequiresUniqueness() {
+				t.Error("frame clue should not require uniqueness")
+			}
+		})
+	}
+}
+
+func TestFrameSumConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[int]int // cell index -> value
+		want   bool
+	}{
+		{"empty", nil, true},
+		{"complete and correct", map[int]int{0: 9, 1: 2, 2: 1}, true},
+		{"complete and wrong", map[int]int{0: 9, 1: 2, 2: 3}, false},
+		{"cells past the count are ignored", map[int]int{0: 9, 1: 2, 2: 1, 3: 8}, true},
+		{"partial sum still fits", map[int]int{0: 9}, true},
+		{"partial sum too big", map[int]int{0: 9, 1: 3}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			fs, err := constraints.NewFrameSumConstraint([]int{0, 1, 2, 3, 4, 5, 6, 7, 8}, 12, 3)
+			if err != nil {
+				t.Fatalf("NewFrameSumConstraint() returned error: %v", err)
+			}
+			for idx, value := range tt.values {
+				board.Set(idx/9, idx%9, value)
+			}
+
+			got, err := fs.IsValid(board)
+			if err != nil {
+				t.Fatalf("IsValid() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+			if violations := fs.Violations(board); (len(violations) == 0) != tt.want {
+				t.Errorf("Violations() = %+v, want none only when valid", violations)
+			}
+		})
+	}
+}
+
+func TestFrameSumConstraintDistinctDigits(t *testing.T) {
+	board := lib.NewBoard()
+	fs, err := constraints.NewFrameSumConstraint([]int{0, 1, 2}, 24, 3)
+	if err != nil {
+		t.Fatalf("NewFrameSumConstraint() returned error: %v", err)
+	}
+	board.Set(0, 0, 6) // 18 more needs 9+9, but the digits share a row
+
+	if valid, _ := fs.IsValid(board); valid {
+		t.Error("IsValid() should fail when only repeated digits could reach the clue")
+	}
+}
+
+func TestFrameSumConstraintBoundsCandidates(t *testing.T) {
+	board := lib.NewBoard()
+	fs, err := constraints.NewFrameSumConstraint([]int{0, 1, 2}, 23, 3)
+	if err != nil {
+		t.Fatalf("NewFrameSumConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(fs)
+
+	// The other two add at most 9+8, so each needs at least 6
+	if got := fmt.Sprint(board.GetCell(0).CandidateSlice()); got != "[6 7 8 9]" {
+		t.Errorf("candidates when added = %s, want [6 7 8 9]", got)
+	}
+
+	board.Set(0, 0, 9) // The other two make 14 without a 9: at least 6 each
+	if got := fmt.Sprint(board.GetCell(1).CandidateSlice()); got != "[6 7 8]" {
+		t.Errorf("candidates after 9 = %s, want [6 7 8]", got)
+	}
+
+	board.Set(0, 1, 8) // The last must be 6
+	if got := fmt.Sprint(board.GetCell(2).CandidateSlice()); got != "[6]" {
+		t.Errorf("last cell candidates = %s, want [6]", got)
+	}
+}
+
+func TestFrameSumConstraintJSONRoundTrip(t *testing.T) {
+	board := lib.NewBoard()
+	fs, err := constraints.NewFrameSumConstraint([]int{80, 71, 62, 53}, 10, 2)
+	if err != nil {
+		t.Fatalf("NewFrameSumConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(fs)
+
+	data, err := json.Marshal(board)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	loaded, err := lib.LoadBoardJSON(data)
+	if err != nil {
+		t.Fatalf("LoadBoardJSON() returned error: %v", err)
+	}
+
+	got := loaded.GetConstraints()
+	if len(got) != 1 {
+		t.Fatalf("loaded %d constraints, want 1", len(got))
+	}
+	if got[0].GetName() != fs.GetName() || fmt.Sprint(got[0].GetCells()) != "[80 71]" {
+		t.Errorf("loaded %s on %v, want %s on [80 71]", got[0].GetName(), got[0].GetCells(), fs.GetName())
+	}
+}
@@ -0,0 +1,82 @@
+package constraints_test
+
+import (
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestGenerateBoard(t *testing.T) {
+	tests := []struct {
+		name        string
+		symmetrical bool
+		seed        int64
+	}{
+		{"asymmetrical", false, 1},
+		{"symmetrical", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := constraints.GenerateBoard(tt.symmetrical, tt.seed)
+			if board == nil {
+				t.Fatal("GenerateBoard() returned nil")
+			}
+
+			count, err := board.CountSolutions(2)
+			if err != nil {
+				t.Fatalf("CountSolutions() returned error: %v", err)
+			}
+			if count != 1 {
+				t.Errorf("CountSolutions(2) = %d, want 1", count)
+			}
+
+			for i := 0; i < 81; i++ {
+				cell := board.GetCell(i)
+				if cell.IsSolved() != cell.IsGiven() {
+					t.Errorf("cell %d: solved = %v, given = %v, want clues and givens to match", i, cell.IsSolved(), cell.IsGiven())
+				}
+				if tt.symmetrical && cell.IsSolved() != board.GetCell(80-i).IsSolved() {
+					t.Errorf("cell %d and its mirror %d should both be clues or both be empty", i, 80-i)
+				}
+			}
+
+			again := constraints.GenerateBoard(tt.symmetrical, tt.seed)
+			if again.String() != board.String() {
+				t.Errorf("same seed gave different puzzles:\n%s\n%s", board.String(), again.String())
+			}
+		})
+	}
+}
+
+func TestGenerateBoardWithVariantConstraint(t *testing.T) {
+	thermo, err := constraints.NewThermoConstraint([]int{0, 1, 2, 3}, true)
+	if err != nil {
+		t.Fatalf("NewThermoConstraint() returned error: %v", err)
+	}
+
+	board := constraints.GenerateBoard(false, 3, thermo)
+	if board == nil {
+		t.Fatal("GenerateBoard() returned nil")
+	}
+	if len(board.GetConstraints()) != 28 {
+		t.Errorf("board has %d constraints, want 27 standard plus the thermo", len(board.GetConstraints()))
+	}
+
+	solved, err := board.Solve()
+	if err != nil || !solved {
+		t.Fatalf("Solve() = %v, %v, want a solvable puzzle", solved, err)
+	}
+	for i := 1; i < 4; i++ {
+		if board.Get(0, i) <= board.Get(0, i-1) {
+			t.Errorf("solution row 1 = %s, want it increasing along the thermo", rowString(board))
+			break
+		}
+	}
+}
+
+// rowString returns the values of the first row
+func rowString(board *lib.Board) string {
+	return board.String()[:9]
+}
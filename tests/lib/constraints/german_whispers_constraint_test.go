@@ -171,3 +171,38 @@ func TestGermanWhispersConstraintIsValidNilBoard(t *testing.T) {
 		t.Error("expected invalid result for nil board")
 	}
 }
+
+func TestGermanWhispersConstraintViolations(t *testing.T) {
+	gw, err := constraints.NewGermanWhispersConstraint([]int{0, 1, 2})
+	if err != nil {
+		t.Fatalf("failed to create constraint: %v", err)
+	}
+
+	board := lib.NewBoard()
+	board.Set(0, 0, 1)
+	board.Set(0, 1, 7)
+	board.Set(0, 2, 4) // 7 and 4 only differ by 3
+
+	violations := gw.Violations(board)
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %d: %+v", len(violations), violations)
+	}
+	v := violations[0]
+	if len(v.Cells) != 2 || v.Cells[0] != 1 || v.Cells[1] != 2 {
+		t.Errorf("expected offending pair [1 2], got %v", v.Cells)
+	}
+	if v.Value != 3 {
+		t.Errorf("Value = %d, want difference 3", v.Value)
+	}
+}
+
+func TestGermanWhispersConstraintImpactScore(t *testing.T) {
+	gw, err := constraints.NewGermanWhispersConstraint([]int{0, 1})
+	if err != nil {
+		t.Fatalf("failed to create constraint: %v", err)
+	}
+
+	if score := gw.ImpactScore(lib.NewBoard()); score != 0 {
+		t.Errorf("whispers use the base estimate, got %d", score)
+	}
+}
@@ -0,0 +1,162 @@
+package constraints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewInequalityConstraint(t *testing.T) {
+	tests := []struct {
+		name            string
+		greater, lesser int
+		shouldErr       bool
+	}{
+		{"adjacent in a row", 0, 1, false},
+		{"far apart", 80, 0, false},
+		{"same cell", 40, 40, true},
+		{"invalid greater cell", 81, 0, true},
+		{"invalid lesser cell", 0, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ic, err := constraints.NewInequalityConstraint(tt.greater, tt.lesser)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ic.Greater() != tt.greater || ic.Lesser() != tt.lesser {
+				t.Errorf("Greater(), Lesser() = %d, %d, want %d, %d", ic.Greater(), ic.Lesser(), tt.greater, tt.lesser)
+			}
+			if ic.RequiresUniqueness() {
+				t.Error("inequality clue should not require uniqueness")
+			}
+		})
+	}
+}
+
+func TestInequalityConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name            string
+		greater, lesser int
+		want            bool
+	}{
+		{"empty", 0, 0, true},
+		{"only greater", 1, 0, true},
+		{"only lesser", 0, 9, true},
+		{"greater", 7, 3, true},
+		{"equal", 5, 5, false},
+		{"reversed", 3, 7, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			ic, err := constraints.NewInequalityConstraint(0, 10)
+			if err != nil {
+				t.Fatalf("NewInequalityConstraint() returned error: %v", err)
+			}
+			board.Set(0, 0, tt.greater)
+			board.Set(1, 1, tt.lesser)
+
+			got, err := ic.IsValid(board)
+			if err != nil {
+				t.Fatalf("IsValid() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+			if violations := ic.Violations(board); (len(violations) == 0) != tt.want {
+				t.Errorf("Violations() = %+v, want none only when valid", violations)
+			}
+		})
+	}
+}
+
+func TestInequalityConstraintPropagation(t *testing.T) {
+	tests := []struct {
+		name          string
+		row, col, set int    // Value placed, 0 for none
+		wantGreater   string // Candidates of R1C1 afterwards
+		wantLesser    string // Candidates of R1C2 afterwards
+	}{
+		{"without values", 0, 0, 0, "[2 3 4 5 6 7 8 9]", "[1 2 3 4 5 6 7 8]"},
+		{"greater set", 0, 0, 4, "[]", "[1 2 3]"},
+		{"lesser set", 0, 1, 6, "[7 8 9]", "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			ic, err := constraints.NewInequalityConstraint(0, 1)
+			if err != nil {
+				t.Fatalf("NewInequalityConstraint() returned error: %v", err)
+			}
+			board.AddConstraint(ic)
+			if tt.set != 0 {
+				board.Set(tt.row, tt.col, tt.set)
+			}
+
+			if got := fmt.Sprint(board.GetCell(0).CandidateSlice()); got != tt.wantGreater {
+				t.Errorf("greater cell candidates = %s, want %s", got, tt.wantGreater)
+			}
+			if got := fmt.Sprint(board.GetCell(1).CandidateSlice()); got != tt.wantLesser {
+				t.Errorf("lesser cell candidates = %s, want %s", got, tt.wantLesser)
+			}
+		})
+	}
+}
+
+func TestInequalityConstraintInitializeWithValue(t *testing.T) {
+	board := lib.NewBoard()
+	board.Set(4, 4, 3) // The lesser cell is set before the clue is added
+
+	ic, err := constraints.NewInequalityConstraint(40, 41)
+	if err != nil {
+		t.Fatalf("NewInequalityConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(ic)
+
+	if got := fmt.Sprint(board.GetCell(41).CandidateSlice()); got != "[1 2]" {
+		t.Errorf("lesser cell candidates = %s, want [1 2]", got)
+	}
+}
+
+func TestInequalityConstraintJSONRoundTrip(t *testing.T) {
+	board := lib.NewBoard()
+	ic, err := constraints.NewInequalityConstraint(12, 3)
+	if err != nil {
+		t.Fatalf("NewInequalityConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(ic)
+
+	data, err := json.Marshal(board)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	loaded, err := lib.LoadBoardJSON(data)
+	if err != nil {
+		t.Fatalf("LoadBoardJSON() returned error: %v", err)
+	}
+
+	got := loaded.GetConstraints()
+	if len(got) != 1 {
+		t.Fatalf("loaded %d constraints, want 1", len(got))
+	}
+	loadedIC, ok := got[0].(*constraints.InequalityConstraint)
+	if !ok {
+		t.Fatalf("loaded %T, want *constraints.InequalityConstraint", got[0])
+	}
+	if loadedIC.Greater() != 12 || loadedIC.Lesser() != 3 {
+		t.Errorf("loaded greater %d, lesser %d, want 12, 3", loadedIC.Greater(), loadedIC.Lesser())
+	}
+}
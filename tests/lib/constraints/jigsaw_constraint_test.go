@@ -0,0 +1,220 @@
+package constraints_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+// boxRegionMap returns a region map equivalent to the standard 3x3 boxes
+func boxRegionMap() [81]int {
+	var regionMap [81]int
+	for i := 0; i < 81; i++ {
+		regionMap[i] = (i/9/3)*3 + (i%9)/3
+	}
+	return regionMap
+}
+
+func TestNewJigsawConstraints(t *testing.T) {
+	valid := boxRegionMap()
+
+	// Swap two cells between regions 0 and 1 - still 9 cells each
+	swapped := boxRegionMap()
+	swapped[2], swapped[3] = 1, 0
+
+	outOfRange := boxRegionMap()
+	outOfRange[40] = 9
+
+	unbalanced := boxRegionMap()
+	unbalanced[3] = 0 // region 0 gets 10 cells, region 1 gets 8
+
+	tests := []struct {
+		name      string
+		regionMap [81]int
+		shouldErr bool
+	}{
+		{"standard boxes", valid, false},
+		{"irregular regions", swapped, false},
+		{"region id out of range", outOfRange, true},
+		{"unbalanced regions", unbalanced, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regions, err := constraints.NewJigsawConstraints(tt.regionMap)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if len(regions) != 9 {
+				t.Fatalf("expected 9 regions, got %d", len(regions))
+			}
+			for _, region := range regions {
+				if len(region.GetCells()) != 9 {
+					t.Errorf("%s: expected 9 cells, got %d", region.GetName(), len(region.GetCells()))
+				}
+				if !region.RequiresUniqueness() {
+					t.Errorf("%s should require uniqueness", region.GetName())
+				}
+			}
+		})
+	}
+}
+
+func TestJigsawRegionConstraintIsValid(t *testing.T) {
+	regionMap := boxRegionMap()
+	regionMap[2], regionMap[3] = 1, 0
+
+	regions, err := constraints.NewJigsawConstraints(regionMap)
+	if err != nil {
+		t.Fatalf("failed to create jigsaw constraints: %v", err)
+	}
+	region0 := regions[0]
+
+	board := lib.NewBoard()
+	board.Set(0, 0, 1)
+	board.Set(0, 3, 2) // R1C4 now belongs to region 0
+
+	valid, err := region0.IsValid(board)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !valid {
+		t.Error("region with unique values should be valid")
+	}
+
+	board.Set(2, 2, 2) // duplicate 2 inside region 0
+	valid, err = region0.IsValid(board)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if valid {
+		t.Error("region with duplicate values should be invalid")
+	}
+
+	if _, err := region0.IsValid(nil); err == nil {
+		t.Error("expected error for nil board, got none")
+	}
+}
+
+func TestJigsawRegionConstraintPropagation(t *testing.T) {
+	regionMap := boxRegionMap()
+	regionMap[2], regionMap[3] = 1, 0
+
+	regions, err := constraints.NewJigsawConstraints(regionMap)
+	if err != nil {
+		t.Fatalf("failed to create jigsaw constraints: %v", err)
+	}
+
+	board := lib.NewBoard()
+	for _, region := range regions {
+		board.AddConstraint(region)
+	}
+
+	board.Set(0, 0, 7)
+
+	// R1C4 joined region 0 and should lose candidate 7
+	if board.GetCellAt(0, 3).HasCandidate(7) {
+		t.Error("R1C4 is in the same region as R1C1 and should not have candidate 7")
+	}
+	// R1C3 moved to region 1 and should keep it
+	if !board.GetCellAt(0, 2).HasCandidate(7) {
+		t.Error("R1C3 is no longer in region 0 and should keep candidate 7")
+	}
+}
+
+func TestAddJigsawRegions(t *testing.T) {
+	irregular := boxRegionMap()
+	irregular[2], irregular[3] = 1, 0
+
+	unbalanced := boxRegionMap()
+	unbalanced[3] = 0
+
+	outOfRange := boxRegionMap()
+	outOfRange[80] = -1
+
+	small, err := lib.NewBoardOfSize(6)
+	if err != nil {
+		t.Fatalf("NewBoardOfSize(6) returned error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		board     *lib.Board
+		layout    [81]int
+		shouldErr bool
+	}{
+		{"irregular layout", lib.NewBoard(), irregular, false},
+		{"unbalanced regions", lib.NewBoard(), unbalanced, true},
+		{"region id out of range", lib.NewBoard(), outOfRange, true},
+		{"nil board", nil, irregular, true},
+		{"not a 9x9 board", small, irregular, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := constraints.AddJigsawRegions(tt.board, tt.layout)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				if tt.board != nil && len(tt.board.GetConstraints()) != 0 {
+					t.Errorf("invalid layout added %d constraints, want none", len(tt.board.GetConstraints()))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			added := tt.board.GetConstraints()
+			if len(added) != 27 {
+				t.Fatalf("added %d constraints, want 27", len(added))
+			}
+			regions := 0
+			for _, c := range added {
+				if strings.HasPrefix(c.GetName(), "Box") {
+					t.Errorf("standard box %s should not be added", c.GetName())
+				}
+				if _, ok := c.(*constraints.JigsawRegionConstraint); ok {
+					regions++
+				}
+			}
+			if regions != 9 {
+				t.Errorf("added %d region constraints, want 9", regions)
+			}
+		})
+	}
+}
+
+func TestAddJigsawRegionsPropagation(t *testing.T) {
+	layout := boxRegionMap()
+	layout[2], layout[3] = 1, 0
+
+	board := lib.NewBoard()
+	if err := constraints.AddJigsawRegions(board, layout); err != nil {
+		t.Fatalf("AddJigsawRegions() returned error: %v", err)
+	}
+
+	board.Set(1, 0, 7)
+
+	// R1C4 is in R2C1's region, R1C3 is not (and shares neither row nor column)
+	if board.GetCellAt(0, 3).HasCandidate(7) {
+		t.Error("R1C4 is in the same region as R2C1 and should not have candidate 7")
+	}
+	if !board.GetCellAt(0, 2).HasCandidate(7) {
+		t.Error("R1C3 is no longer in R2C1's region and should keep candidate 7")
+	}
+	// Rows and columns still apply
+	if board.GetCellAt(1, 8).HasCandidate(7) || board.GetCellAt(8, 0).HasCandidate(7) {
+		t.Error("the row and column of R2C1 should lose candidate 7")
+	}
+}
@@ -1,6 +1,7 @@
 package constraints_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/eftil/sudoku-solver.git/lib"
@@ -161,3 +162,219 @@ func TestKillerCageConstraintIsValidNilBoard(t *testing.T) {
 		t.Error("expected invalid result for nil board")
 	}
 }
+
+func TestKillerCageConstraintViolations(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []int
+		wantCells []int
+		wantValue int
+	}{
+		{"complete cage with wrong sum", []int{1, 2, 4}, []int{0, 1, 2}, 7},
+		{"partial cage over target", []int{5, 6, 0}, []int{0, 1}, 11},
+		{"duplicate value", []int{3, 3, 0}, []int{0, 1}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kc, err := constraints.NewKillerCageConstraint([]int{0, 1, 2}, 10)
+			if err != nil {
+				t.Fatalf("failed to create constraint: %v", err)
+			}
+
+			board := lib.NewBoard()
+			for i, val := range tt.values {
+				board.Set(0, i, val)
+			}
+
+			violations := kc.Violations(board)
+			if len(violations) != 1 {
+				t.Fatalf("expected 1 violation, got %d: %+v", len(violations), violations)
+			}
+			v := violations[0]
+			if v.Value != tt.wantValue {
+				t.Errorf("Value = %d, want %d", v.Value, tt.wantValue)
+			}
+			if len(v.Cells) != len(tt.wantCells) {
+				t.Fatalf("Cells = %v, want %v", v.Cells, tt.wantCells)
+			}
+			for i := range v.Cells {
+				if v.Cells[i] != tt.wantCells[i] {
+					t.Errorf("Cells = %v, want %v", v.Cells, tt.wantCells)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestKillerCageConstraintImpactScore(t *testing.T) {
+	kc, err := constraints.NewKillerCageConstraint([]int{0, 1}, 3)
+	if err != nil {
+		t.Fatalf("failed to create constraint: %v", err)
+	}
+
+	// Only 1 and 2 can make 3, so 3-9 are ruled out in both cells
+	board := lib.NewBoard()
+	if score := kc.ImpactScore(board); score != 14 {
+		t.Errorf("ImpactScore() = %d, want 14", score)
+	}
+
+	// Once propagated there is nothing left to eliminate
+	attached := lib.NewBoard()
+	attached.AddConstraint(kc)
+	attached.Set(0, 0, 1)
+	if score := kc.ImpactScore(attached); score != 0 {
+		t.Errorf("ImpactScore() after propagation = %d, want 0", score)
+	}
+}
+
+func TestKillerCageConstraintRequiredDigits(t *testing.T) {
+	tests := []struct {
+		name   string
+		cells  []int
+		sum    int
+		values map[int]int // cell index -> value set before the check
+		want   string
+	}{
+		{"two cells sum 17", []int{0, 1}, 17, nil, "[8 9]"},
+		{"three cells sum 6", []int{0, 1, 2}, 6, nil, "[1 2 3]"},
+		{"several combinations", []int{0, 1, 2}, 10, nil, "[]"},
+		{"three cells sum 23", []int{0, 1, 2}, 23, nil, "[6 8 9]"},
+		{"placed digits are excluded", []int{0, 1, 2}, 6, map[int]int{0: 2}, "[1 3]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			kc, err := constraints.NewKillerCageConstraint(tt.cells, tt.sum)
+			if err != nil {
+				t.Fatalf("NewKillerCageConstraint() returned error: %v", err)
+			}
+			board.AddConstraint(kc)
+			for idx, value := range tt.values {
+				board.Set(idx/9, idx%9, value)
+			}
+
+			if got := fmt.Sprint(kc.RequiredDigits(board)); got != tt.want {
+				t.Errorf("RequiredDigits() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKillerCageConstraintCellValueBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		cells   []int
+		sum     int
+		values  map[int]int // cell index -> value set before the check
+		cell    int
+		wantMin int
+		wantMax int
+	}{
+		{"two cells sum 17", []int{0, 1}, 17, nil, 0, 8, 9},
+		{"two cells sum 3", []int{0, 1}, 3, nil, 1, 1, 2},
+		{"three cells sum 10", []int{0, 1, 2}, 10, nil, 2, 1, 7},
+		{"fill narrows the rest", []int{0, 1, 2}, 10, map[int]int{0: 6}, 1, 1, 3},
+		{"filled cell returns its value", []int{0, 1, 2}, 10, map[int]int{0: 6}, 0, 6, 6},
+		{"cell outside the cage", []int{0, 1}, 17, nil, 2, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			kc, err := constraints.NewKillerCageConstraint(tt.cells, tt.sum)
+			if err != nil {
+				t.Fatalf("NewKillerCageConstraint() returned error: %v", err)
+			}
+			board.AddConstraint(kc)
+			for idx, value := range tt.values {
+				board.Set(idx/9, idx%9, value)
+			}
+
+			if got := kc.MinCellValue(board, tt.cell); got != tt.wantMin {
+				t.Errorf("MinCellValue(%d) = %d, want %d", tt.cell, got, tt.wantMin)
+			}
+			if got := kc.MaxCellValue(board, tt.cell); got != tt.wantMax {
+				t.Errorf("MaxCellValue(%d) = %d, want %d", tt.cell, got, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestKillerCageConstraintPropagateValueChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		cells  []int
+		sum    int
+		values map[int]int // cell index -> value set on the board
+		want   string      // candidates of every empty cage cell
+	}{
+		{"last cell is forced", []int{0, 1}, 10, map[int]int{0: 3}, "[7]"},
+		{"pair must reach 9 without repeating 1", []int{0, 1, 2}, 10, map[int]int{0: 1}, "[2 3 4 5 6 7]"},
+		{"pair must reach 10 without repeating 5", []int{0, 1, 2}, 15, map[int]int{0: 5}, "[1 2 3 4 6 7 8 9]"},
+		{"high triple", []int{0, 1, 2, 3}, 30, map[int]int{0: 6}, "[7 8 9]"},
+		{"low triple", []int{0, 1, 2, 3}, 10, map[int]int{3: 4}, "[1 2 3]"},
+		{"two values set", []int{0, 1, 2, 3}, 20, map[int]int{0: 9, 1: 8}, "[1 2]"},
+		{"sum already unreachable", []int{0, 1}, 3, map[int]int{0: 3}, "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			kc, err := constraints.NewKillerCageConstraint(tt.cells, tt.sum)
+			if err != nil {
+				t.Fatalf("NewKillerCageConstraint() returned error: %v", err)
+			}
+			board.AddConstraint(kc)
+			for idx, value := range tt.values {
+				board.Set(idx/9, idx%9, value)
+			}
+
+			for _, idx := range tt.cells {
+				if _, set := tt.values[idx]; set {
+					continue
+				}
+				if got := fmt.Sprint(board.GetCell(idx).CandidateSlice()); got != tt.want {
+					t.Errorf("cell %d candidates = %s, want %s", idx, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestKillerCageConstraintPencilMarksEmptyCage(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells []int
+		sum   int
+		want  string
+	}{
+		{"two cells sum 17", []int{0, 1}, 17, "[8 9]"},
+		{"two cells sum 3", []int{0, 1}, 3, "[1 2]"},
+		{"two cells sum 10", []int{0, 1}, 10, "[1 2 3 4 6 7 8 9]"},
+		{"three cells sum 24", []int{0, 1, 2}, 24, "[7 8 9]"},
+		{"four cells sum 10", []int{0, 1, 2, 3}, 10, "[1 2 3 4]"},
+		{"five cells sum 35", []int{0, 1, 2, 3, 4}, 35, "[5 6 7 8 9]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			kc, err := constraints.NewKillerCageConstraint(tt.cells, tt.sum)
+			if err != nil {
+				t.Fatalf("NewKillerCageConstraint() returned error: %v", err)
+			}
+			board.AddConstraint(kc)
+
+			// Nothing is propagated until a value is set
+			kc.ApplyPencilMarkConstraints(board)
+			for _, idx := range tt.cells {
+				if got := fmt.Sprint(board.GetCell(idx).CandidateSlice()); got != tt.want {
+					t.Errorf("cell %d candidates = %s, want %s", idx, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package constraints_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewKropkiConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		cellA     int
+		cellB     int
+		kind      constraints.KropkiKind
+		shouldErr bool
+	}{
+		{"white dot", 0, 1, constraints.White, false},
+		{"black dot", 0, 9, constraints.Black, false},
+		{"same cell", 0, 0, constraints.White, true},
+		{"not adjacent", 0, 2, constraints.White, true},
+		{"diagonal", 0, 10, constraints.Black, true},
+		{"across row wrap", 8, 9, constraints.White, true},
+		{"invalid cell index", 80, 81, constraints.Black, true},
+		{"invalid kind", 0, 1, constraints.KropkiKind(5), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kc, err := constraints.NewKropkiConstraint(tt.cellA, tt.cellB, tt.kind)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if kc.Kind() != tt.kind {
+				t.Errorf("Kind() = %v, want %v", kc.Kind(), tt.kind)
+			}
+			if kc.RequiresUniqueness() {
+				t.Error("kropki dot should not require uniqueness")
+			}
+		})
+	}
+}
+
+func TestKropkiConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name string
+		kind constraints.KropkiKind
+		a, b int
+		want bool
+	}{
+		{"white empty", constraints.White, 0, 0, true},
+		{"white one empty", constraints.White, 5, 0, true},
+		{"white consecutive", constraints.White, 5, 6, true},
+		{"white consecutive descending", constraints.White, 6, 5, true},
+		{"white gap of two", constraints.White, 4, 6, false},
+		{"black 1-2", constraints.Black, 1, 2, true},
+		{"black 4-2", constraints.Black, 4, 2, true},
+		{"black 3-6", constraints.Black, 3, 6, true},
+		{"black 8-4", constraints.Black, 8, 4, true},
+		{"black consecutive", constraints.Black, 5, 6, false},
+		{"black ratio three", constraints.Black, 3, 9, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			kc, err := constraints.NewKropkiConstraint(0, 1, tt.kind)
+			if err != nil {
+				t.Fatalf("NewKropkiConstraint() returned error: %v", err)
+			}
+			board.Set(0, 0, tt.a)
+			board.Set(0, 1, tt.b)
+
+			got, err := kc.IsValid(board)
+			if err != nil {
+				t.Fatalf("IsValid() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+			if violations := kc.Violations(board); (len(violations) == 0) != tt.want {
+				t.Errorf("Violations() = %+v, want none only when valid", violations)
+			}
+		})
+	}
+}
+
+func TestKropkiConstraintPropagateValueChange(t *testing.T) {
+	tests := []struct {
+		name  string
+		kind  constraints.KropkiKind
+		value int
+		want  string
+	}{
+		{"white 5", constraints.White, 5, "[4 6]"},
+		{"white 1", constraints.White, 1, "[2]"},
+		{"white 9", constraints.White, 9, "[8]"},
+		{"black 1", constraints.Black, 1, "[2]"},
+		{"black 2", constraints.Black, 2, "[1 4]"},
+		{"black 3", constraints.Black, 3, "[6]"},
+		{"black 4", constraints.Black, 4, "[2 8]"},
+		{"black 6", constraints.Black, 6, "[3]"},
+		{"black 8", constraints.Black, 8, "[4]"},
+		{"black 7", constraints.Black, 7, "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			kc, err := constraints.NewKropkiConstraint(0, 9, tt.kind)
+			if err != nil {
+				t.Fatalf("NewKropkiConstraint() returned error: %v", err)
+			}
+			board.AddConstraint(kc)
+
+			// Either side of the dot propagates to the other
+			board.Set(1, 0, tt.value)
+			if got := fmt.Sprint(board.GetCell(0).CandidateSlice()); got != tt.want {
+				t.Errorf("partner candidates = %s, want %s", got, tt.want)
+			}
+			if board.GetCell(1).CandidateCount() != 9 {
+				t.Error("cells off the dot should keep all candidates")
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package constraints_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewLittleKillerConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		cells     []int
+		sum       int
+		shouldErr bool
+	}{
+		{"down right", []int{0, 10, 20}, 15, false},
+		{"up right", []int{72, 64, 56, 48}, 20, false},
+		{"single cell", []int{8}, 9, false},
+		{"no cells", nil, 5, true},
+		{"not diagonal", []int{0, 1, 2}, 10, true},
+		{"changes direction", []int{0, 10, 2}, 10, true},
+		{"wraps around the grid", []int{8, 18}, 10, true},
+		{"invalid cell index", []int{72, 82}, 10, true},
+		{"sum too small", []int{0, 10, 20}, 2, true},
+		{"sum too big", []int{0, 10, 20}, 28, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lk, err := constraints.NewLittleKillerConstraint(tt.cells, tt.sum)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if lk.RequiresUniqueness() {
+				t.Error("little killer should not require uniqueness")
+			}
+		})
+	}
+}
+
+func TestLittleKillerConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[int]int // position on the diagonal -> value
+		want   bool
+	}{
+		{"empty", nil, true},
+		{"complete and correct", map[int]int{0: 5, 1: 5, 2: 2}, true},
+		{"complete and wrong", map[int]int{0: 5, 1: 5, 2: 3}, false},
+		{"partial sum still fits", map[int]int{0: 9}, true},
+		{"partial sum too big", map[int]int{0: 9, 1: 3}, false},
+		{"low start still fits", map[int]int{0: 1, 1: 2}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			cells := []int{0, 10, 20}
+			lk, err := constraints.NewLittleKillerConstraint(cells, 12)
+			if err != nil {
+				t.Fatalf("NewLittleKillerConstraint() returned error: %v", err)
+			}
+			for pos, value := range tt.values {
+				board.Set(cells[pos]/9, cells[pos]%9, value)
+			}
+
+			got, err := lk.IsValid(board)
+			if err != nil {
+				t.Fatalf("IsValid() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+			if violations := lk.Violations(board); (len(violations) == 0) != tt.want {
+				t.Errorf("Violations() = %+v, want none only when valid", violations)
+			}
+		})
+	}
+}
+
+func TestLittleKillerConstraintUnreachableMinimum(t *testing.T) {
+	board := lib.NewBoard()
+	lk, err := constraints.NewLittleKillerConstraint([]int{0, 10, 20}, 25)
+	if err != nil {
+		t.Fatalf("NewLittleKillerConstraint() returned error: %v", err)
+	}
+	board.Set(0, 0, 1)
+	board.Set(1, 1, 2) // 3 + at most 9 can't reach 25
+
+	if valid, _ := lk.IsValid(board); valid {
+		t.Error("IsValid() should fail when the empty cells can't make up the rest")
+	}
+}
+
+func TestLittleKillerConstraintBoundsCandidates(t *testing.T) {
+	board := lib.NewBoard()
+	cells := []int{0, 10, 20}
+	lk, err := constraints.NewLittleKillerConstraint(cells, 24)
+	if err != nil {
+		t.Fatalf("NewLittleKillerConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(lk)
+
+	// Two other cells add at most 18, so each needs at least 6
+	if got := fmt.Sprint(board.GetCell(0).CandidateSlice()); got != "[6 7 8 9]" {
+		t.Errorf("candidates when added = %s, want [6 7 8 9]", got)
+	}
+
+	board.Set(0, 0, 9) // The other two make 15: at least 6 each
+	board.Set(1, 1, 7) // The last must be 8
+	if got := fmt.Sprint(board.GetCell(20).CandidateSlice()); got != "[8]" {
+		t.Errorf("last cell candidates = %s, want [8]", got)
+	}
+}
@@ -0,0 +1,122 @@
+package constraints_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+// loShu is a 3x3 magic square, row by row
+var loShu = [9]int{2, 7, 6, 9, 5, 1, 4, 3, 8}
+
+func TestNewMagicSquareConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		topLeft   int
+		wantCells []int
+		shouldErr bool
+	}{
+		{"first box", 0, []int{0, 1, 2, 9, 10, 11, 18, 19, 20}, false},
+		{"across boxes", 31, []int{31, 32, 33, 40, 41, 42, 49, 50, 51}, false},
+		{"last fitting cell", 60, []int{60, 61, 62, 69, 70, 71, 78, 79, 80}, false},
+		{"past the right edge", 7, nil, true},
+		{"past the bottom edge", 63, nil, true},
+		{"invalid cell index", 81, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, err := constraints.NewMagicSquareConstraint(tt.topLeft)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := fmt.Sprint(ms.GetCells()); got != fmt.Sprint(tt.wantCells) {
+				t.Errorf("GetCells() = %s, want %v", got, tt.wantCells)
+			}
+			if !ms.RequiresUniqueness() {
+				t.Error("magic square should require uniqueness")
+			}
+		})
+	}
+}
+
+func TestMagicSquareConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		values [9]int
+		want   bool
+	}{
+		{"empty", [9]int{}, true},
+		{"complete magic square", loShu, true},
+		{"partial magic square", [9]int{2, 7, 0, 0, 5, 0, 0, 0, 0}, true},
+		{"partial row already too large", [9]int{9, 8, 0, 0, 0, 0, 0, 0, 0}, false},
+		{"complete row sums to 14", [9]int{1, 7, 6, 0, 0, 0, 0, 0, 0}, false},
+		{"broken diagonal", [9]int{2, 0, 0, 0, 6, 0, 0, 0, 8}, false},
+		{"duplicate digit", [9]int{2, 0, 0, 2, 0, 0, 0, 0, 0}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			ms, err := constraints.NewMagicSquareConstraint(0)
+			if err != nil {
+				t.Fatalf("NewMagicSquareConstraint() returned error: %v", err)
+			}
+			for i, value := range tt.values {
+				board.Set(i/3, i%3, value)
+			}
+
+			got, err := ms.IsValid(board)
+			if err != nil {
+				t.Fatalf("IsValid() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+			if violations := ms.Violations(board); (len(violations) == 0) != tt.want {
+				t.Errorf("Violations() = %+v, want none only when valid", violations)
+			}
+		})
+	}
+}
+
+func TestMagicSquareConstraintPropagation(t *testing.T) {
+	board := lib.NewBoard()
+	ms, err := constraints.NewMagicSquareConstraint(0)
+	if err != nil {
+		t.Fatalf("NewMagicSquareConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(ms)
+
+	// Initialize: 5 in the center, even corners, odd edges
+	for _, tc := range []struct {
+		cell int
+		want string
+	}{
+		{10, "[5]"},
+		{0, "[2 4 6 8]"},
+		{1, "[1 3 7 9]"},
+	} {
+		if got := fmt.Sprint(board.GetCell(tc.cell).CandidateSlice()); got != tc.want {
+			t.Errorf("cell %d candidates = %s, want %s", tc.cell, got, tc.want)
+		}
+	}
+
+	// With the center and a corner placed, the opposite corner completes the diagonal
+	board.Set(1, 1, 5)
+	board.Set(0, 0, 2)
+	if got := fmt.Sprint(board.GetCell(20).CandidateSlice()); got != "[8]" {
+		t.Errorf("opposite corner candidates = %s, want [8]", got)
+	}
+	if board.GetCell(2).HasCandidate(2) {
+		t.Error("placed value should be removed from the rest of the square")
+	}
+}
@@ -0,0 +1,103 @@
+package constraints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewNonConsecutiveConstraint(t *testing.T) {
+	nc := constraints.NewNonConsecutiveConstraint()
+	if len(nc.GetCells()) != 81 {
+		t.Errorf("GetCells() has %d cells, want the whole board", len(nc.GetCells()))
+	}
+	if nc.RequiresUniqueness() {
+		t.Error("non-consecutive rule should not require uniqueness")
+	}
+}
+
+func TestNonConsecutiveConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[int]int // cell index -> value
+		want   bool
+	}{
+		{"empty", nil, true},
+		{"apart by two", map[int]int{0: 3, 1: 5}, true},
+		{"consecutive in a row", map[int]int{40: 4, 41: 5}, false},
+		{"consecutive in a column", map[int]int{40: 6, 49: 5}, false},
+		{"consecutive diagonally", map[int]int{40: 4, 50: 5}, true},
+		{"consecutive across the row end", map[int]int{8: 1, 9: 2}, true},
+		{"equal neighbors", map[int]int{0: 7, 1: 7}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			nc := constraints.NewNonConsecutiveConstraint()
+			for idx, value := range tt.values {
+				board.Set(idx/9, idx%9, value)
+			}
+
+			got, err := nc.IsValid(board)
+			if err != nil {
+				t.Fatalf("IsValid() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+			if violations := nc.Violations(board); (len(violations) == 0) != tt.want {
+				t.Errorf("Violations() = %+v, want none only when valid", violations)
+			}
+		})
+	}
+
+	if _, err := constraints.NewNonConsecutiveConstraint().IsValid(nil); err == nil {
+		t.Error("expected error for nil board, got none")
+	}
+}
+
+func TestNonConsecutiveConstraintPropagation(t *testing.T) {
+	board := lib.NewBoard()
+	board.Set(0, 0, 1) // Placed before the rule is added
+	board.AddConstraint(constraints.NewNonConsecutiveConstraint())
+
+	if board.GetCell(1).HasCandidate(2) || board.GetCell(9).HasCandidate(2) {
+		t.Error("neighbors of R1C1 should lose 2 when the rule is added")
+	}
+
+	board.Set(4, 4, 5)
+	for _, idx := range []int{31, 49, 39, 41} {
+		if got := fmt.Sprint(board.GetCell(idx).CandidateSlice()); got != "[1 2 3 5 7 8 9]" {
+			t.Errorf("cell %d candidates = %s, want [1 2 3 5 7 8 9]", idx, got)
+		}
+	}
+	if !board.GetCell(30).HasCandidate(4) {
+		t.Error("diagonal neighbor R4C4 should keep 4")
+	}
+}
+
+func TestNonConsecutiveConstraintJSONRoundTrip(t *testing.T) {
+	board := lib.NewBoard()
+	board.AddConstraint(constraints.NewNonConsecutiveConstraint())
+
+	data, err := json.Marshal(board)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	loaded, err := lib.LoadBoardJSON(data)
+	if err != nil {
+		t.Fatalf("LoadBoardJSON() returned error: %v", err)
+	}
+
+	got := loaded.GetConstraints()
+	if len(got) != 1 {
+		t.Fatalf("loaded %d constraints, want 1", len(got))
+	}
+	if _, ok := got[0].(*constraints.NonConsecutiveConstraint); !ok {
+		t.Errorf("loaded %T, want *constraints.NonConsecutiveConstraint", got[0])
+	}
+}
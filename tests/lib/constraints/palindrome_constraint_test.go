@@ -0,0 +1,130 @@
+package constraints_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewPalindromeConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		cells     []int
+		shouldErr bool
+	}{
+		{"even length", []int{0, 1, 2, 3}, false},
+		{"odd length", []int{0, 10, 20}, false},
+		{"single cell", []int{0}, true},
+		{"invalid cell index", []int{0, 81}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc, err := constraints.NewPalindromeConstraint(tt.cells)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pc.RequiresUniqueness() {
+				t.Error("palindrome should not require uniqueness")
+			}
+		})
+	}
+}
+
+func TestPalindromeConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[int]int // position on the line -> value
+		want   bool
+	}{
+		{"empty", nil, true},
+		{"one side filled", map[int]int{0: 3, 1: 5}, true},
+		{"mirrored", map[int]int{0: 3, 4: 3, 1: 5, 3: 5}, true},
+		{"center is free", map[int]int{2: 9}, true},
+		{"outer pair differs", map[int]int{0: 3, 4: 4}, false},
+		{"inner pair differs", map[int]int{0: 3, 4: 3, 1: 5, 3: 6}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			cells := []int{0, 1, 2, 3, 4}
+			pc, err := constraints.NewPalindromeConstraint(cells)
+			if err != nil {
+				t.Fatalf("NewPalindromeConstraint() returned error: %v", err)
+			}
+			for pos, value := range tt.values {
+				board.Set(0, cells[pos], value)
+			}
+
+			got, err := pc.IsValid(board)
+			if err != nil {
+				t.Fatalf("IsValid() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+			if violations := pc.Violations(board); (len(violations) == 0) != tt.want {
+				t.Errorf("Violations() = %+v, want none only when valid", violations)
+			}
+		})
+	}
+}
+
+func TestPalindromeConstraintPropagateValueChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[int]int // position on the line -> value
+		want   map[int]string
+	}{
+		{"start mirrors onto the end", map[int]int{0: 4}, map[int]string{4: "[4]", 1: "[1 2 3 4 5 6 7 8 9]"}},
+		{"end mirrors onto the start", map[int]int{3: 7}, map[int]string{1: "[7]"}},
+		{"center mirrors itself", map[int]int{2: 5}, map[int]string{0: "[1 2 3 4 5 6 7 8 9]", 4: "[1 2 3 4 5 6 7 8 9]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			cells := []int{0, 10, 20, 30, 40} // Diagonal, so no standard peers are involved
+			pc, err := constraints.NewPalindromeConstraint(cells)
+			if err != nil {
+				t.Fatalf("NewPalindromeConstraint() returned error: %v", err)
+			}
+			board.AddConstraint(pc)
+
+			for pos, value := range tt.values {
+				board.Set(cells[pos]/9, cells[pos]%9, value)
+			}
+
+			for pos, want := range tt.want {
+				if got := fmt.Sprint(board.GetCell(cells[pos]).CandidateSlice()); got != want {
+					t.Errorf("position %d candidates = %s, want %s", pos, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPalindromeConstraintCatchesSolvedPartner(t *testing.T) {
+	board := lib.NewBoard()
+	pc, err := constraints.NewPalindromeConstraint([]int{0, 1, 2})
+	if err != nil {
+		t.Fatalf("NewPalindromeConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(pc)
+
+	board.Set(0, 2, 6)
+	board.Set(0, 0, 2) // Partner already holds 6; propagation can't help
+
+	if valid, _ := pc.IsValid(board); valid {
+		t.Error("IsValid() should catch mirrored cells holding different values")
+	}
+}
@@ -0,0 +1,87 @@
+package constraints_test
+
+import (
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewParityCountConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		cells     []int
+		digit     int
+		shouldErr bool
+	}{
+		{"valid region", []int{0, 1, 2, 3}, 5, false},
+		{"empty cells", []int{}, 5, true},
+		{"invalid cell index", []int{0, 81}, 5, true},
+		{"digit too small", []int{0, 1}, 0, true},
+		{"digit too large", []int{0, 1}, 10, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc, err := constraints.NewParityCountConstraint(tt.cells, tt.digit, true)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if pc.RequiresUniqueness() {
+				t.Error("parity count should not require uniqueness")
+			}
+		})
+	}
+}
+
+func TestParityCountConstraintIsValid(t *testing.T) {
+	// Region across the first column so the same digit can repeat
+	cells := []int{0, 9, 18, 27}
+
+	tests := []struct {
+		name      string
+		values    []int
+		wantEven  bool
+		wantValid bool
+	}{
+		{"incomplete region is not checked", []int{5, 0, 0, 0}, true, true},
+		{"even count wanted, none present", []int{1, 2, 3, 4}, true, true},
+		{"even count wanted, one present", []int{5, 2, 3, 4}, true, false},
+		{"odd count wanted, one present", []int{5, 2, 3, 4}, false, true},
+		{"odd count wanted, none present", []int{1, 2, 3, 4}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc, err := constraints.NewParityCountConstraint(cells, 5, tt.wantEven)
+			if err != nil {
+				t.Fatalf("failed to create constraint: %v", err)
+			}
+
+			board := lib.NewBoard()
+			for i, cellIdx := range cells {
+				board.Set(cellIdx/9, cellIdx%9, tt.values[i])
+			}
+
+			valid, err := pc.IsValid(board)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if valid != tt.wantValid {
+				t.Errorf("IsValid() = %v, want %v for values %v", valid, tt.wantValid, tt.values)
+			}
+		})
+	}
+
+	pc, _ := constraints.NewParityCountConstraint(cells, 5, true)
+	if _, err := pc.IsValid(nil); err == nil {
+		t.Error("expected error for nil board, got none")
+	}
+}
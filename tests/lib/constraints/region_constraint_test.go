@@ -0,0 +1,122 @@
+package constraints_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+// mainDiagonal is the 9 cells from R1C1 to R9C9
+var mainDiagonal = []int{0, 10, 20, 30, 40, 50, 60, 70, 80}
+
+func TestNewRegionConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		region    string
+		cells     []int
+		wantName  string
+		shouldErr bool
+	}{
+		{"full region", "Diagonal", mainDiagonal, "Diagonal", false},
+		{"extra region of 4 cells", "Window", []int{10, 11, 19, 20}, "Window", false},
+		{"default name", "", []int{0}, "Region", false},
+		{"empty cells", "Region", []int{}, "", true},
+		{"too many cells", "Region", []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, "", true},
+		{"duplicate cell", "Region", []int{0, 1, 0}, "", true},
+		{"invalid cell index", "Region", []int{0, 81}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc, err := constraints.NewRegionConstraint(tt.region, tt.cells)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rc.GetName() != tt.wantName {
+				t.Errorf("GetName() = %q, want %q", rc.GetName(), tt.wantName)
+			}
+			if !rc.RequiresUniqueness() {
+				t.Error("region should require uniqueness")
+			}
+		})
+	}
+}
+
+func TestRegionConstraintIsValid(t *testing.T) {
+	rc, err := constraints.NewRegionConstraint("Diagonal", mainDiagonal)
+	if err != nil {
+		t.Fatalf("NewRegionConstraint() returned error: %v", err)
+	}
+
+	board := lib.NewBoard()
+	board.Set(0, 0, 4)
+	board.Set(4, 4, 7)
+	if valid, _ := rc.IsValid(board); !valid {
+		t.Error("distinct values should be valid")
+	}
+
+	board.Set(8, 8, 4)
+	if valid, _ := rc.IsValid(board); valid {
+		t.Error("a repeated value should be invalid")
+	}
+	if violations := rc.Violations(board); len(violations) != 1 || violations[0].Value != 4 {
+		t.Errorf("Violations() = %+v, want the repeated 4", violations)
+	}
+	if _, err := rc.IsValid(nil); err == nil {
+		t.Error("expected error for nil board, got none")
+	}
+}
+
+func TestRegionConstraintSolvesLikeAUnit(t *testing.T) {
+	rc, err := constraints.NewRegionConstraint("Diagonal", mainDiagonal)
+	if err != nil {
+		t.Fatalf("NewRegionConstraint() returned error: %v", err)
+	}
+	board := lib.NewBoard()
+	board.AddConstraint(rc)
+
+	// Placing a value propagates along the region only
+	board.Set(0, 0, 6)
+	if board.GetCell(40).HasCandidate(6) {
+		t.Error("R5C5 shares the region with R1C1 and should not have candidate 6")
+	}
+	if !board.GetCell(1).HasCandidate(6) {
+		t.Error("R1C2 is outside the region and should keep candidate 6")
+	}
+
+	// 9 is left only in R9C9, which a full region reports as a hidden single
+	for _, idx := range mainDiagonal[1:8] {
+		board.GetCell(idx).RemoveCandidate(9)
+	}
+	singles := board.HiddenSingleCandidates()
+	if len(singles) != 1 || singles[0].Index != 80 || singles[0].Value != 9 || singles[0].Unit != "Diagonal" {
+		t.Errorf("HiddenSingleCandidates() = %+v, want 9 in R9C9 from Diagonal", singles)
+	}
+}
+
+func TestRegionConstraintJSONRoundTrip(t *testing.T) {
+	board := lib.NewBoard()
+	rc, _ := constraints.NewRegionConstraint("Window", []int{10, 11, 19, 20})
+	board.AddConstraint(rc)
+
+	data, err := json.Marshal(board)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	loaded, err := lib.LoadBoardJSON(data)
+	if err != nil {
+		t.Fatalf("LoadBoardJSON() returned error: %v", err)
+	}
+	got := loaded.GetConstraints()
+	if len(got) != 1 || got[0].GetName() != "Window" || len(got[0].GetCells()) != 4 {
+		t.Errorf("loaded %+v, want the Window region", got)
+	}
+}
@@ -149,3 +149,51 @@ func TestRenbanConstraintIsValidNilBoard(t *testing.T) {
 		t.Error("expected invalid result for nil board")
 	}
 }
+
+func TestRenbanConstraintViolations(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []int
+		wantCount int
+	}{
+		{"consecutive", []int{4, 2, 3}, 0},
+		{"gap", []int{1, 2, 4}, 1},
+		{"duplicate", []int{5, 5, 0}, 1},
+		{"partial without repeats", []int{1, 9, 0}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc, err := constraints.NewRenbanConstraint([]int{0, 1, 2})
+			if err != nil {
+				t.Fatalf("failed to create constraint: %v", err)
+			}
+
+			board := lib.NewBoard()
+			for i, val := range tt.values {
+				board.Set(0, i, val)
+			}
+
+			if got := rc.Violations(board); len(got) != tt.wantCount {
+				t.Errorf("expected %d violation(s), got %d: %+v", tt.wantCount, len(got), got)
+			}
+		})
+	}
+}
+
+func TestRenbanConstraintPencilMarksKeepCandidates(t *testing.T) {
+	board := lib.NewBoard()
+	rc, err := constraints.NewRenbanConstraint([]int{9, 10, 11})
+	if err != nil {
+		t.Fatalf("NewRenbanConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(rc)
+
+	// A short line need not contain every digit, so hidden subsets must not apply
+	rc.ApplyPencilMarkConstraints(board)
+	for _, idx := range rc.Cells {
+		if got := board.GetCell(idx).CandidateCount(); got != 9 {
+			t.Errorf("cell %d has %d candidates, want 9", idx, got)
+		}
+	}
+}
@@ -134,3 +134,52 @@ func TestRowConstraintIsValidNilBoard(t *testing.T) {
 		t.Error("expected invalid result for nil board")
 	}
 }
+
+func TestRowConstraintViolations(t *testing.T) {
+	rc, err := constraints.NewRowConstraint(0)
+	if err != nil {
+		t.Fatalf("failed to create constraint: %v", err)
+	}
+
+	board := lib.NewBoard()
+	board.Set(0, 1, 4)
+	board.Set(0, 6, 4)
+	board.Set(0, 8, 2)
+
+	violations := rc.Violations(board)
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %d: %+v", len(violations), violations)
+	}
+	v := violations[0]
+	if v.Value != 4 || len(v.Cells) != 2 || v.Cells[0] != 1 || v.Cells[1] != 6 {
+		t.Errorf("expected duplicate 4 at cells [1 6], got %d at %v", v.Value, v.Cells)
+	}
+	if v.ConstraintName != "Row 1" {
+		t.Errorf("ConstraintName = %q, want %q", v.ConstraintName, "Row 1")
+	}
+}
+
+func TestRowConstraintImpactScore(t *testing.T) {
+	rc, err := constraints.NewRowConstraint(0)
+	if err != nil {
+		t.Fatalf("failed to create constraint: %v", err)
+	}
+
+	board := lib.NewBoard()
+	if score := rc.ImpactScore(board); score != 0 {
+		t.Errorf("empty row should have no impact, got %d", score)
+	}
+
+	// Not attached to the board, so the 5 hasn't been eliminated from the rest of the row yet
+	board.Set(0, 0, 5)
+	if score := rc.ImpactScore(board); score != 8 {
+		t.Errorf("expected 8 pending eliminations, got %d", score)
+	}
+
+	attached := lib.NewBoard()
+	attached.AddConstraint(rc)
+	attached.Set(0, 0, 5)
+	if score := rc.ImpactScore(attached); score != 0 {
+		t.Errorf("propagated row should have no pending eliminations, got %d", score)
+	}
+}
@@ -0,0 +1,97 @@
+package constraints_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewSameParityConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		cells     []int
+		shouldErr bool
+	}{
+		{"valid group", []int{0, 10, 20}, false},
+		{"two cells", []int{0, 1}, false},
+		{"single cell", []int{0}, true},
+		{"invalid cell index", []int{0, 81}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp, err := constraints.NewSameParityConstraint(tt.cells)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sp.RequiresUniqueness() {
+				t.Error("same parity group should not require uniqueness")
+			}
+		})
+	}
+}
+
+func TestSameParityConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[int]int
+		want   bool
+	}{
+		{"empty", nil, true},
+		{"one value", map[int]int{0: 3}, true},
+		{"all odd", map[int]int{0: 3, 1: 5, 2: 9}, true},
+		{"all even with a repeat", map[int]int{0: 2, 1: 8, 2: 2}, true},
+		{"mixed", map[int]int{0: 3, 2: 4}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			sp, err := constraints.NewSameParityConstraint([]int{0, 1, 2})
+			if err != nil {
+				t.Fatalf("NewSameParityConstraint() returned error: %v", err)
+			}
+			for idx, value := range tt.values {
+				board.Set(idx/9, idx%9, value)
+			}
+
+			got, err := sp.IsValid(board)
+			if err != nil {
+				t.Fatalf("IsValid() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+			if violations := sp.Violations(board); (len(violations) == 0) != tt.want {
+				t.Errorf("Violations() = %+v, want none only when valid", violations)
+			}
+		})
+	}
+}
+
+func TestSameParityConstraintPropagateValueChange(t *testing.T) {
+	board := lib.NewBoard()
+	sp, err := constraints.NewSameParityConstraint([]int{0, 10, 20})
+	if err != nil {
+		t.Fatalf("NewSameParityConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(sp)
+
+	board.Set(1, 1, 4)
+	for _, idx := range []int{0, 20} {
+		if got := fmt.Sprint(board.GetCell(idx).CandidateSlice()); got != "[2 4 6 8]" {
+			t.Errorf("cell %d candidates = %s, want [2 4 6 8]", idx, got)
+		}
+	}
+	if board.GetCell(1).CandidateCount() != 9 {
+		t.Error("cells outside the group should keep all candidates")
+	}
+}
@@ -0,0 +1,97 @@
+package constraints_test
+
+import (
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+// rowCells returns the cell indices of a row, left to right
+func rowCells(row int) []int {
+	cells := make([]int, 9)
+	for col := range cells {
+		cells[col] = row*9 + col
+	}
+	return cells
+}
+
+func TestNewSandwichConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		cells     []int
+		sum       int
+		shouldErr bool
+	}{
+		{"row", rowCells(0), 10, false},
+		{"column", []int{4, 13, 22, 31, 40, 49, 58, 67, 76}, 0, false},
+		{"row right to left", []int{17, 16, 15, 14, 13, 12, 11, 10, 9}, 35, false},
+		{"too few cells", []int{0, 1, 2}, 5, true},
+		{"not a line", []int{0, 1, 2, 3, 4, 5, 6, 7, 17}, 5, true},
+		{"wraps into the next row", []int{5, 6, 7, 8, 9, 10, 11, 12, 13}, 5, true},
+		{"invalid cell index", []int{73, 74, 75, 76, 77, 78, 79, 80, 81}, 5, true},
+		{"sum too big", rowCells(0), 36, true},
+		{"negative sum", rowCells(0), -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := constraints.NewSandwichConstraint(tt.cells, tt.sum)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sc.RequiresUniqueness() {
+				t.Error("sandwich should not require uniqueness")
+			}
+		})
+	}
+}
+
+func TestSandwichConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		sum    int
+		values []int // Row 1, 0 for empty
+		want   bool
+	}{
+		{"empty row", 10, []int{0, 0, 0, 0, 0, 0, 0, 0, 0}, true},
+		{"9 still missing", 10, []int{1, 8, 0, 0, 0, 0, 0, 0, 0}, true},
+		{"complete and correct", 10, []int{1, 4, 6, 9, 2, 3, 5, 7, 8}, true},
+		{"complete and wrong", 10, []int{1, 4, 7, 9, 2, 3, 5, 6, 8}, false},
+		{"9 before the 1", 5, []int{2, 9, 5, 1, 0, 0, 0, 0, 0}, true},
+		{"adjacent for a zero clue", 0, []int{0, 9, 1, 0, 0, 0, 0, 0, 0}, true},
+		{"adjacent for a non-zero clue", 5, []int{0, 9, 1, 0, 0, 0, 0, 0, 0}, false},
+		{"partial sum still fits", 12, []int{1, 4, 0, 0, 9, 0, 0, 0, 0}, true},
+		{"partial sum already too big", 8, []int{1, 7, 0, 9, 0, 0, 0, 0, 0}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			sc, err := constraints.NewSandwichConstraint(rowCells(0), tt.sum)
+			if err != nil {
+				t.Fatalf("NewSandwichConstraint() returned error: %v", err)
+			}
+			for col, value := range tt.values {
+				board.Set(0, col, value)
+			}
+
+			got, err := sc.IsValid(board)
+			if err != nil {
+				t.Fatalf("IsValid() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+			if violations := sc.Violations(board); (len(violations) == 0) != tt.want {
+				t.Errorf("Violations() = %+v, want none only when valid", violations)
+			}
+		})
+	}
+}
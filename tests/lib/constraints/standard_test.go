@@ -0,0 +1,57 @@
+package constraints_test
+
+import (
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestStandardConstraints(t *testing.T) {
+	standard, err := constraints.StandardConstraints()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(standard) != 27 {
+		t.Fatalf("expected 27 constraints, got %d", len(standard))
+	}
+
+	names := make(map[string]bool)
+	coverage := make(map[int]int)
+	for _, c := range standard {
+		names[c.GetName()] = true
+		for _, idx := range c.GetCells() {
+			coverage[idx]++
+		}
+	}
+	if len(names) != 27 {
+		t.Errorf("expected 27 distinct constraint names, got %d", len(names))
+	}
+	for idx := 0; idx < 81; idx++ {
+		if coverage[idx] != 3 {
+			t.Errorf("cell %d is covered by %d constraints, want 3", idx, coverage[idx])
+		}
+	}
+}
+
+func TestAddStandardConstraints(t *testing.T) {
+	board := lib.NewBoard()
+	if err := constraints.AddStandardConstraints(board); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(board.GetConstraints()) != 27 {
+		t.Errorf("expected 27 constraints on the board, got %d", len(board.GetConstraints()))
+	}
+
+	// Constraints are attached and propagate
+	board.Set(4, 4, 3)
+	for _, peer := range [][2]int{{4, 0}, {0, 4}, {3, 3}} {
+		if board.GetCellAt(peer[0], peer[1]).HasCandidate(3) {
+			t.Errorf("R%dC%d should have lost candidate 3", peer[0]+1, peer[1]+1)
+		}
+	}
+
+	if err := constraints.AddStandardConstraints(nil); err == nil {
+		t.Error("expected error for nil board, got none")
+	}
+}
@@ -0,0 +1,296 @@
+package constraints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewThermoConstraint(t *testing.T) {
+	tests := []struct {
+		name      string
+		cells     []int
+		shouldErr bool
+	}{
+		{"valid thermometer", []int{0, 1, 2, 3}, false},
+		{"valid two cells", []int{0, 9}, false},
+		{"single cell", []int{0}, true},
+		{"too many cells", []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, true},
+		{"invalid cell index negative", []int{0, -1}, true},
+		{"invalid cell index too large", []int{0, 81}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, err := constraints.NewThermoConstraint(tt.cells, true)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if tc == nil {
+				t.Errorf("expected constraint but got nil")
+				return
+			}
+			if tc.RequiresUniqueness() {
+				t.Error("thermometer should not require uniqueness")
+			}
+		})
+	}
+}
+
+func TestThermoConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name      string
+		cells     []int
+		values    []int
+		wantValid bool
+	}{
+		{"empty thermometer", []int{0, 1, 2, 3}, []int{0, 0, 0, 0}, true},
+		{"complete increasing", []int{0, 1, 2, 3}, []int{1, 3, 4, 9}, true},
+		{"partial increasing", []int{0, 1, 2, 3}, []int{2, 0, 5, 0}, true},
+		{"equal adjacent values", []int{0, 1, 2, 3}, []int{3, 3, 0, 0}, false},
+		{"equal values across a gap", []int{0, 1, 2, 3}, []int{4, 0, 4, 0}, false},
+		{"decreasing adjacent values", []int{0, 1, 2, 3}, []int{5, 4, 0, 0}, false},
+		{"gap too small for the empty cell", []int{0, 1, 2, 3}, []int{3, 0, 4, 0}, false},
+		{"gap just large enough", []int{0, 1, 2, 3}, []int{3, 0, 5, 0}, true},
+		{"bulb too large for the thermometer", []int{0, 1, 2, 3}, []int{7, 0, 0, 0}, false},
+		{"tip too small for the thermometer", []int{0, 1, 2, 3}, []int{0, 0, 0, 3}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, err := constraints.NewThermoConstraint(tt.cells, true)
+			if err != nil {
+				t.Fatalf("failed to create constraint: %v", err)
+			}
+
+			board := lib.NewBoard()
+			for i, cellIdx := range tt.cells {
+				row := cellIdx / 9
+				col := cellIdx % 9
+				err := board.Set(row, col, tt.values[i])
+				if err != nil {
+					t.Fatalf("Set(%d, %d, %d) failed: %v", row, col, tt.values[i], err)
+				}
+			}
+
+			valid, err := tc.IsValid(board)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if valid != tt.wantValid {
+				t.Errorf("IsValid() = %v, want %v for values %v", valid, tt.wantValid, tt.values)
+			}
+		})
+	}
+}
+
+func TestThermoConstraintIsValidNilBoard(t *testing.T) {
+	tc, err := constraints.NewThermoConstraint([]int{0, 1, 2}, true)
+	if err != nil {
+		t.Fatalf("failed to create constraint: %v", err)
+	}
+
+	valid, err := tc.IsValid(nil)
+	if err == nil {
+		t.Error("expected error for nil board, got none")
+	}
+	if valid {
+		t.Error("expected invalid result for nil board")
+	}
+}
+
+func TestThermoConstraintViolations(t *testing.T) {
+	tc, err := constraints.NewThermoConstraint([]int{0, 1, 2, 3}, true)
+	if err != nil {
+		t.Fatalf("failed to create constraint: %v", err)
+	}
+
+	board := lib.NewBoard()
+	board.Set(0, 0, 3)
+	board.Set(0, 2, 4) // needs at least 5 to leave room for the empty cell between
+
+	violations := tc.Violations(board)
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %d: %+v", len(violations), violations)
+	}
+	v := violations[0]
+	if len(v.Cells) != 2 || v.Cells[0] != 0 || v.Cells[1] != 2 {
+		t.Errorf("expected offending pair [0 2], got %v", v.Cells)
+	}
+	if v.Value != 4 {
+		t.Errorf("Value = %d, want 4", v.Value)
+	}
+}
+
+func TestThermoConstraintPropagateValueChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[int]int // position on the thermometer -> value
+		want   map[int]string
+	}{
+		{
+			name:   "bulb side and tip side bounds",
+			values: map[int]int{1: 4},
+			want:   map[int]string{0: "[1 2 3]", 2: "[5 6 7 8]", 3: "[6 7 8 9]"},
+		},
+		{
+			name:   "squeezed between two values",
+			values: map[int]int{1: 4, 3: 7},
+			want:   map[int]string{0: "[1 2 3]", 2: "[5 6]"},
+		},
+		{
+			name:   "bulb sets the minimum",
+			values: map[int]int{0: 5},
+			want:   map[int]string{1: "[6 7]", 2: "[7 8]", 3: "[8 9]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			cells := []int{0, 1, 2, 3}
+			tc, err := constraints.NewThermoConstraint(cells, true)
+			if err != nil {
+				t.Fatalf("NewThermoConstraint() returned error: %v", err)
+			}
+			board.AddConstraint(tc)
+
+			for pos, value := range tt.values {
+				board.Set(0, cells[pos], value)
+			}
+
+			for pos, want := range tt.want {
+				if got := fmt.Sprint(board.GetCell(cells[pos]).CandidateSlice()); got != want {
+					t.Errorf("position %d candidates = %s, want %s", pos, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestThermoConstraintInitialize(t *testing.T) {
+	board := lib.NewBoard()
+	cells := []int{0, 1, 2, 3, 4, 5, 6}
+	tc, err := constraints.NewThermoConstraint(cells, true)
+	if err != nil {
+		t.Fatalf("NewThermoConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(tc)
+
+	// Seven cells: each position leaves room for the cells before and after it
+	want := []string{"[1 2 3]", "[2 3 4]", "[3 4 5]", "[4 5 6]", "[5 6 7]", "[6 7 8]", "[7 8 9]"}
+	for pos, cellIdx := range cells {
+		if got := fmt.Sprint(board.GetCell(cellIdx).CandidateSlice()); got != want[pos] {
+			t.Errorf("position %d candidates = %s, want %s", pos, got, want[pos])
+		}
+	}
+}
+
+func TestSlowThermoConstraint(t *testing.T) {
+	if _, err := constraints.NewThermoConstraint([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 17}, false); err != nil {
+		t.Errorf("a slow thermometer may have more than 9 cells, got error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		values    []int
+		wantValid bool
+	}{
+		{"equal adjacent values", []int{3, 3, 0, 0}, true},
+		{"equal values across a gap", []int{4, 0, 4, 0}, true},
+		{"all equal", []int{5, 5, 5, 5}, true},
+		{"decreasing adjacent values", []int{5, 4, 0, 0}, false},
+		{"bulb may be 9", []int{9, 0, 0, 0}, true},
+		{"tip may be 1", []int{0, 0, 0, 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cells := []int{0, 9, 18, 27}
+			tc, err := constraints.NewThermoConstraint(cells, false)
+			if err != nil {
+				t.Fatalf("failed to create constraint: %v", err)
+			}
+			if tc.Strict() || tc.GetName() != "Slow Thermometer" {
+				t.Errorf("Strict(), GetName() = %v, %q, want false, \"Slow Thermometer\"", tc.Strict(), tc.GetName())
+			}
+
+			board := lib.NewBoard()
+			for i, cellIdx := range cells {
+				board.Set(cellIdx/9, cellIdx%9, tt.values[i])
+			}
+
+			valid, err := tc.IsValid(board)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if valid != tt.wantValid {
+				t.Errorf("IsValid() = %v, want %v for values %v", valid, tt.wantValid, tt.values)
+			}
+			if violations := tc.Violations(board); (len(violations) == 0) != tt.wantValid {
+				t.Errorf("Violations() = %+v, want none only when valid", violations)
+			}
+		})
+	}
+}
+
+func TestSlowThermoConstraintPropagation(t *testing.T) {
+	board := lib.NewBoard()
+	cells := []int{0, 9, 18, 27}
+	tc, err := constraints.NewThermoConstraint(cells, false)
+	if err != nil {
+		t.Fatalf("NewThermoConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(tc)
+
+	// No position bounds before any value is placed
+	for pos, cellIdx := range cells {
+		if got := fmt.Sprint(board.GetCell(cellIdx).CandidateSlice()); got != "[1 2 3 4 5 6 7 8 9]" {
+			t.Errorf("position %d candidates = %s, want all digits", pos, got)
+		}
+	}
+
+	board.Set(1, 0, 4)
+	board.Set(3, 0, 6)
+	want := map[int]string{0: "[1 2 3 4]", 2: "[4 5 6]"}
+	for pos, w := range want {
+		if got := fmt.Sprint(board.GetCell(cells[pos]).CandidateSlice()); got != w {
+			t.Errorf("position %d candidates = %s, want %s", pos, got, w)
+		}
+	}
+}
+
+func TestThermoConstraintJSONRoundTrip(t *testing.T) {
+	for _, strict := range []bool{true, false} {
+		board := lib.NewBoard()
+		tc, err := constraints.NewThermoConstraint([]int{0, 1, 2}, strict)
+		if err != nil {
+			t.Fatalf("NewThermoConstraint() returned error: %v", err)
+		}
+		board.AddConstraint(tc)
+
+		data, err := json.Marshal(board)
+		if err != nil {
+			t.Fatalf("json.Marshal() returned error: %v", err)
+		}
+		loaded, err := lib.LoadBoardJSON(data)
+		if err != nil {
+			t.Fatalf("LoadBoardJSON() returned error: %v", err)
+		}
+
+		got, ok := loaded.GetConstraints()[0].(*constraints.ThermoConstraint)
+		if !ok || got.Strict() != strict {
+			t.Errorf("strict %v thermometer loaded as %+v", strict, loaded.GetConstraints()[0])
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package constraints_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestNewXVConstraint(t *testing.T) {
+	tests := []struct {
+		name         string
+		cellA, cellB int
+		target       int
+		shouldErr    bool
+	}{
+		{"V in a row", 0, 1, constraints.V, false},
+		{"X in a column", 40, 49, constraints.X, false},
+		{"diagonal", 0, 10, constraints.X, true},
+		{"same cell", 0, 0, constraints.X, true},
+		{"wraps into the next row", 8, 9, constraints.V, true},
+		{"not adjacent", 0, 2, constraints.V, true},
+		{"invalid cell index", 80, 81, constraints.V, true},
+		{"invalid target", 0, 1, 7, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xv, err := constraints.NewXVConstraint(tt.cellA, tt.cellB, tt.target)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if xv.Target() != tt.target {
+				t.Errorf("Target() = %d, want %d", xv.Target(), tt.target)
+			}
+			if xv.RequiresUniqueness() {
+				t.Error("xv clue should not require uniqueness")
+			}
+		})
+	}
+}
+
+func TestXVConstraintIsValid(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     int
+		valA, valB int
+		want       bool
+	}{
+		{"empty", constraints.X, 0, 0, true},
+		{"one value", constraints.X, 3, 0, true},
+		{"X sums to 10", constraints.X, 3, 7, true},
+		{"X sums to 9", constraints.X, 3, 6, false},
+		{"V sums to 5", constraints.V, 1, 4, true},
+		{"V sums to 10", constraints.V, 3, 7, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			xv, err := constraints.NewXVConstraint(0, 1, tt.target)
+			if err != nil {
+				t.Fatalf("NewXVConstraint() returned error: %v", err)
+			}
+			board.Set(0, 0, tt.valA)
+			board.Set(0, 1, tt.valB)
+
+			got, err := xv.IsValid(board)
+			if err != nil {
+				t.Fatalf("IsValid() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+			if violations := xv.Violations(board); (len(violations) == 0) != tt.want {
+				t.Errorf("Violations() = %+v, want none only when valid", violations)
+			}
+		})
+	}
+}
+
+func TestXVConstraintPropagation(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      int
+		set         int    // Value placed in R1C1, 0 for none
+		wantPartner string // Candidates of R1C2 afterwards
+	}{
+		{"V without values", constraints.V, 0, "[1 2 3 4]"},
+		{"X without values", constraints.X, 0, "[1 2 3 4 5 6 7 8 9]"},
+		{"V completes the sum", constraints.V, 1, "[4]"},
+		{"X completes the sum", constraints.X, 3, "[7]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := lib.NewBoard()
+			xv, err := constraints.NewXVConstraint(0, 1, tt.target)
+			if err != nil {
+				t.Fatalf("NewXVConstraint() returned error: %v", err)
+			}
+			board.AddConstraint(xv)
+			if tt.set != 0 {
+				board.Set(0, 0, tt.set)
+			}
+
+			if got := fmt.Sprint(board.GetCell(1).CandidateSlice()); got != tt.wantPartner {
+				t.Errorf("partner candidates = %s, want %s", got, tt.wantPartner)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package lib_test
+
+import (
+	"testing"
+)
+
+func TestBoardUndoRedo(t *testing.T) {
+	board := newStandardBoard(t)
+	board.SetHistoryEnabled(true)
+
+	empty := board.CandidatesString()
+	board.Set(0, 0, 5)
+	afterFirst := board.CandidatesString()
+	board.Set(4, 4, 3)
+
+	if err := board.Undo(); err != nil {
+		t.Fatalf("Undo() returned error: %v", err)
+	}
+	if board.Get(4, 4) != 0 {
+		t.Error("Undo() should clear R5C5")
+	}
+	if board.CandidatesString() != afterFirst {
+		t.Error("Undo() should restore the candidates of R5C5's peers")
+	}
+
+	if err := board.Undo(); err != nil {
+		t.Fatalf("Undo() returned error: %v", err)
+	}
+	if board.CandidatesString() != empty {
+		t.Error("undoing every move should restore the empty board")
+	}
+	if err := board.Undo(); err == nil {
+		t.Error("Undo() with no history should return an error")
+	}
+
+	if err := board.Redo(); err != nil {
+		t.Fatalf("Redo() returned error: %v", err)
+	}
+	if board.Get(0, 0) != 5 || board.CandidatesString() != afterFirst {
+		t.Error("Redo() should reapply R1C1 = 5 with its eliminations")
+	}
+
+	// A new move discards what could have been redone
+	board.Set(8, 8, 1)
+	if err := board.Redo(); err == nil {
+		t.Error("Redo() after a new Set should return an error")
+	}
+}
+
+func TestBoardHistoryDisabledByDefault(t *testing.T) {
+	board := newStandardBoard(t)
+	if board.HistoryEnabled() {
+		t.Fatal("history should be off by default")
+	}
+
+	board.Set(0, 0, 5)
+	if err := board.Undo(); err == nil {
+		t.Error("Undo() should have nothing to undo when history is off")
+	}
+}
+
+func TestBoardClearHistory(t *testing.T) {
+	board := newStandardBoard(t)
+	board.SetHistoryEnabled(true)
+	board.Set(0, 0, 5)
+	board.Set(0, 1, 6)
+	board.Undo()
+
+	board.ClearHistory()
+	if err := board.Undo(); err == nil {
+		t.Error("Undo() after ClearHistory should return an error")
+	}
+	if err := board.Redo(); err == nil {
+		t.Error("Redo() after ClearHistory should return an error")
+	}
+	if board.Get(0, 0) != 5 {
+		t.Error("ClearHistory should not change the board")
+	}
+}
+
+func TestBoardUndoKeepsGivens(t *testing.T) {
+	board := newStandardBoard(t)
+	board.SetHistoryEnabled(true)
+
+	board.SetGiven(0, 0, 5)
+	board.Set(4, 4, 3)
+
+	if err := board.Undo(); err != nil {
+		t.Fatalf("Undo() returned error: %v", err)
+	}
+	if err := board.Undo(); err == nil {
+		t.Error("Undo() should refuse to revert a given")
+	}
+	if board.Get(0, 0) != 5 || !board.GetCellAt(0, 0).IsGiven() {
+		t.Error("the given R1C1 = 5 should be kept")
+	}
+}
@@ -0,0 +1,252 @@
+package lib_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+)
+
+func TestBoardJSONRoundTrip(t *testing.T) {
+	board := newStandardBoard(t)
+
+	killer, _ := constraints.NewKillerCageConstraint([]int{2, 3}, 10)
+	arrow, _ := constraints.NewArrowConstraint([]int{40}, []int{41, 50})
+	thermo, _ := constraints.NewThermoConstraint([]int{60, 61, 62}, true)
+	kropki, _ := constraints.NewKropkiConstraint(70, 71, constraints.Black)
+	parity, _ := constraints.NewParityCountConstraint([]int{72, 73, 74}, 5, false)
+	renban, _ := constraints.NewRenbanConstraint([]int{12, 13, 14})
+	for _, c := range []lib.Constraint{killer, arrow, thermo, kropki, parity, renban} {
+		board.AddConstraint(c)
+	}
+	setGrid(t, board, easyPuzzle)
+	board.GetCell(0).MarkGiven()
+
+	data, err := json.Marshal(board)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	if !strings.Contains(string(data), `"version":1`) {
+		t.Errorf("saved board should carry the format version, got %s", data)
+	}
+
+	loaded, err := lib.LoadBoardJSON(data)
+	if err != nil {
+		t.Fatalf("LoadBoardJSON() returned error: %v", err)
+	}
+
+	if loaded.String() != board.String() {
+		t.Errorf("values = %s, want %s", loaded.String(), board.String())
+	}
+	if loaded.CandidatesString() != board.CandidatesString() {
+		t.Error("candidates should be rebuilt the same way by propagation")
+	}
+	if !loaded.GetCell(0).IsGiven() || loaded.GetCell(1).IsGiven() {
+		t.Error("givens should round-trip")
+	}
+
+	want, got := board.GetConstraints(), loaded.GetConstraints()
+	if len(got) != len(want) {
+		t.Fatalf("loaded %d constraints, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].GetDescription() != want[i].GetDescription() {
+			t.Errorf("constraint %d = %q, want %q", i, got[i].GetDescription(), want[i].GetDescription())
+		}
+	}
+
+	// Saving the loaded board again gives the same document
+	again, err := json.Marshal(loaded)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("re-saved board differs:\n%s\n%s", again, data)
+	}
+}
+
+func TestBoardJSONJigsawRoundTrip(t *testing.T) {
+	var regions [81]int
+	for i := range regions {
+		regions[i] = i / 9 // Each row is its own region
+	}
+	jigsaw, err := constraints.NewJigsawConstraints(regions)
+	if err != nil {
+		t.Fatalf("NewJigsawConstraints() returned error: %v", err)
+	}
+
+	board := lib.NewBoard()
+	for _, c := range jigsaw {
+		board.AddConstraint(c)
+	}
+
+	data, err := json.Marshal(board)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	loaded, err := lib.LoadBoardJSON(data)
+	if err != nil {
+		t.Fatalf("LoadBoardJSON() returned error: %v", err)
+	}
+	if len(loaded.GetConstraints()) != 9 {
+		t.Errorf("loaded %d regions, want 9", len(loaded.GetConstraints()))
+	}
+}
+
+func TestBoardJSONSizedRoundTrip(t *testing.T) {
+	board, err := lib.NewBoardOfSize(6)
+	if err != nil {
+		t.Fatalf("NewBoardOfSize() returned error: %v", err)
+	}
+	if err := constraints.AddStandardConstraints(board); err != nil {
+		t.Fatalf("AddStandardConstraints() returned error: %v", err)
+	}
+	if err := board.LoadString("12345.456123231564564231312645645312"); err != nil {
+		t.Fatalf("LoadString() returned error: %v", err)
+	}
+
+	data, err := json.Marshal(board)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	loaded, err := lib.LoadBoardJSON(data)
+	if err != nil {
+		t.Fatalf("LoadBoardJSON() returned error: %v", err)
+	}
+	if loaded.Size() != 6 || loaded.String() != board.String() {
+		t.Errorf("loaded %dx%d board %s, want 6x6 %s", loaded.Size(), loaded.Size(), loaded.String(), board.String())
+	}
+	if got := fmt.Sprint(loaded.GetCell(5).CandidateSlice()); got != "[6]" {
+		t.Errorf("R1C6 candidates = %s, want [6]", got)
+	}
+}
+
+func TestLoadBoardJSONErrors(t *testing.T) {
+	grid := strings.Repeat(".", 81)
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not JSON", `{`},
+		{"missing version", `{"grid":"` + grid + `","constraints":[]}`},
+		{"future version", `{"version":99,"grid":"` + grid + `","constraints":[]}`},
+		{"unknown type", `{"version":1,"grid":"` + grid + `","constraints":[{"type":"sandwich","cells":[0]}]}`},
+		{"missing params", `{"version":1,"grid":"` + grid + `","constraints":[{"type":"row","cells":[0]}]}`},
+		{"cells do not match params", `{"version":1,"grid":"` + grid + `","constraints":[{"type":"row","cells":[0,1],"params":{"row":0}}]}`},
+		{"invalid constraint", `{"version":1,"grid":"` + grid + `","constraints":[{"type":"killer_cage","cells":[0],"params":{"sum":50}}]}`},
+		{"short grid", `{"version":1,"grid":"123","constraints":[]}`},
+		{"given on an empty cell", `{"version":1,"grid":"` + grid + `","givens":[0],"constraints":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := lib.LoadBoardJSON([]byte(tt.data)); err == nil {
+				t.Error("expected error but got none")
+			}
+		})
+	}
+}
+
+func TestBoardSaveLoadRoundTrip(t *testing.T) {
+	board := newStandardBoard(t)
+	killer, _ := constraints.NewKillerCageConstraint([]int{2, 3}, 10)
+	board.AddConstraint(killer)
+	setGrid(t, board, easyPuzzle)
+
+	var buf bytes.Buffer
+	if err := board.Save(&buf); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "}\n") {
+		t.Errorf("Save() should write a JSON document ending in a newline, got %q", buf.String())
+	}
+
+	loaded, err := lib.LoadBoard(&buf)
+	if err != nil {
+		t.Fatalf("LoadBoard() returned error: %v", err)
+	}
+	if !loaded.EqualsWithCandidates(board) {
+		t.Errorf("loaded board %s, want %s", loaded.String(), board.String())
+	}
+	if got, want := len(loaded.GetConstraints()), len(board.GetConstraints()); got != want {
+		t.Errorf("loaded %d constraints, want %d", got, want)
+	}
+}
+
+func TestLoadBoardPlainGrid(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantSize int
+		want     string
+	}{
+		{"81 characters", easyPuzzle, 9, strings.ReplaceAll(easyPuzzle, "0", ".")},
+		{"grid over several lines", "\n1.34\n3.12\n2143\n4321\n", 4, "1.343.1221434321"},
+		{"6x6 grid", "12345.456123231564564231312645645312", 6, "12345.456123231564564231312645645312"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := lib.LoadBoard(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("LoadBoard() returned error: %v", err)
+			}
+			if board.Size() != tt.wantSize || board.String() != tt.want {
+				t.Errorf("loaded %dx%d board %s, want %dx%d %s",
+					board.Size(), board.Size(), board.String(), tt.wantSize, tt.wantSize, tt.want)
+			}
+			if len(board.GetConstraints()) != 0 {
+				t.Error("a plain grid should load without constraints")
+			}
+		})
+	}
+}
+
+// failingReader returns an error on every read
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("disk unplugged")
+}
+
+func TestLoadBoardErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		r    io.Reader
+	}{
+		{"nil reader", nil},
+		{"empty input", strings.NewReader("")},
+		{"only whitespace", strings.NewReader(" \n\t")},
+		{"invalid character", strings.NewReader(strings.Repeat("x", 81))},
+		{"wrong cell count", strings.NewReader("123")},
+		{"malformed JSON", strings.NewReader(`{"version":1,`)},
+		{"read error", failingReader{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := lib.LoadBoard(tt.r); err == nil {
+				t.Error("expected error but got none")
+			}
+		})
+	}
+
+	if err := lib.NewBoard().Save(nil); err == nil {
+		t.Error("Save(nil) should return an error")
+	}
+}
+
+func TestRegisteredConstraintTypes(t *testing.T) {
+	types := strings.Join(lib.RegisteredConstraintTypes(), ",")
+	for _, name := range []string{constraints.TypeRow, constraints.TypeKillerCage, constraints.TypeKropki} {
+		if !strings.Contains(types, name) {
+			t.Errorf("RegisteredConstraintTypes() = %s, missing %s", types, name)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package lib_test
+
+import (
+	"testing"
+)
+
+func TestBoardLinkCell(t *testing.T) {
+	first := newStandardBoard(t)
+	second := newStandardBoard(t)
+
+	// R9C9 of the first grid gives R1C1 of the second
+	first.LinkCell(80, second, 0)
+	first.Set(8, 8, 4)
+
+	if got := second.Get(0, 0); got != 4 {
+		t.Fatalf("linked cell = %d, want 4", got)
+	}
+	if second.GetCellAt(0, 1).HasCandidate(4) {
+		t.Error("linked value should propagate through the second board's constraints")
+	}
+
+	// Single direction: solving the target does not flow back
+	second.Set(0, 1, 7)
+	if got := first.Get(8, 7); got != 0 {
+		t.Errorf("unlinked cell on the first board changed to %d", got)
+	}
+}
+
+func TestBoardLinkCellBidirectional(t *testing.T) {
+	first := newStandardBoard(t)
+	second := newStandardBoard(t)
+
+	first.LinkCell(40, second, 40)
+	second.LinkCell(40, first, 40)
+
+	second.Set(4, 4, 6)
+	if got := first.Get(4, 4); got != 6 {
+		t.Errorf("first board R5C5 = %d, want 6", got)
+	}
+	if got := second.Get(4, 4); got != 6 {
+		t.Errorf("second board R5C5 = %d, want 6", got)
+	}
+}
+
+func TestBoardLinkCellAlreadySolved(t *testing.T) {
+	first := newStandardBoard(t)
+	second := newStandardBoard(t)
+
+	first.Set(0, 0, 9)
+	first.LinkCell(0, second, 10)
+
+	if got := second.Get(1, 1); got != 9 {
+		t.Errorf("linking a solved cell should forward its value, got %d", got)
+	}
+}
+
+func TestBoardLinkCellDoesNotOverwrite(t *testing.T) {
+	first := newStandardBoard(t)
+	second := newStandardBoard(t)
+
+	second.Set(0, 0, 2)
+	first.LinkCell(0, second, 0)
+	first.Set(0, 0, 3)
+
+	if got := second.Get(0, 0); got != 2 {
+		t.Errorf("linked cell should keep its value 2, got %d", got)
+	}
+}
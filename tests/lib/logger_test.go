@@ -0,0 +1,151 @@
+package lib_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib/logger"
+)
+
+// captureLog runs fn with the logger writing to a buffer in the given format and level
+func captureLog(t *testing.T, format logger.Format, fn func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	level, previous := logger.GetLevel(), logger.GetFormat()
+	logger.SetOutput(&buf)
+	logger.SetFormat(format)
+	logger.SetLevel(logger.DEBUG)
+	defer func() {
+		logger.SetOutput(os.Stdout)
+		logger.SetFormat(previous)
+		logger.SetLevel(level)
+	}()
+
+	fn()
+	return buf.String()
+}
+
+func TestLoggerJSONFormat(t *testing.T) {
+	out := captureLog(t, logger.JSON, func() {
+		logger.SolvingStep("X-Wing", "Found X-Wing for candidate %d", 5)
+		logger.CandidateElimination(0, 8, 5, "X-Wing in rows 1 and 5")
+		logger.Warn("plain message")
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d:\n%s", len(lines), out)
+	}
+
+	var entries []map[string]any
+	for _, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("line is not JSON: %s (%v)", line, err)
+		}
+		for _, field := range []string{"time", "level", "technique", "cell", "message"} {
+			if _, ok := entry[field]; !ok {
+				t.Errorf("line %s has no %q field", line, field)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	if entries[0]["technique"] != "X-Wing" || entries[0]["message"] != "Found X-Wing for candidate 5" {
+		t.Errorf("solving step fields = %v", entries[0])
+	}
+	if entries[1]["level"] != "DEBUG" || entries[1]["cell"] != "R1C9" ||
+		entries[1]["candidate"] != float64(5) || entries[1]["reason"] != "X-Wing in rows 1 and 5" {
+		t.Errorf("elimination fields = %v", entries[1])
+	}
+	if entries[2]["level"] != "WARN" || entries[2]["message"] != "plain message" {
+		t.Errorf("warning fields = %v", entries[2])
+	}
+}
+
+func TestLoggerTextFormatUnchanged(t *testing.T) {
+	out := captureLog(t, logger.Text, func() {
+		logger.SolvingStep("X-Wing", "Found pattern")
+		logger.CandidateElimination(0, 8, 5, "pointing pair")
+		logger.InfoCell(2, 3, "Value set to %d", 7)
+	})
+
+	want := []string{
+		"[INFO] [SOLVING: X-Wing] Found pattern",
+		"[DEBUG] [Cell R1C9] Eliminated candidate 5 - Reason: pointing pair",
+		"[INFO] [Cell R3C4] Value set to 7",
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(want), len(lines), out)
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, want[i]) {
+			t.Errorf("line %d = %q, want it to end with %q", i, line, want[i])
+		}
+	}
+}
+
+func TestLoggerCategories(t *testing.T) {
+	defer logger.ResetCategories()
+
+	logAll := func() {
+		logger.SolvingStep("X-Wing", "x-wing step")
+		logger.SolvingStep("Propagation", "propagation step")
+		logger.DebugConstraint("Row 1", "row message")
+		logger.Debug("uncategorized message")
+	}
+	tests := []struct {
+		name    string
+		setup   func()
+		want    []string
+		notWant []string
+	}{
+		{"no filters", func() {}, []string{"x-wing", "propagation", "row", "uncategorized"}, nil},
+		{"disabled category", func() { logger.DisableCategory("Propagation") },
+			[]string{"x-wing", "row", "uncategorized"}, []string{"propagation"}},
+		{"enabled category only", func() { logger.EnableCategory("X-Wing") },
+			[]string{"x-wing", "uncategorized"}, []string{"propagation", "row"}},
+		{"enabling a disabled category", func() {
+			logger.DisableCategory("Row 1")
+			logger.EnableCategory("Row 1")
+		}, []string{"row", "uncategorized"}, []string{"x-wing", "propagation"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger.ResetCategories()
+			tt.setup()
+			out := captureLog(t, logger.Text, logAll)
+			for _, s := range tt.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output should contain %q:\n%s", s, out)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(out, s) {
+					t.Errorf("output should not contain %q:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggerFilteredLevelDoesNotAllocate(t *testing.T) {
+	level := logger.GetLevel()
+	logger.SetLevel(logger.INFO)
+	defer logger.SetLevel(level)
+
+	row, col, value := 3, 4, 7
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Debug("Trying %d", value)
+		logger.DebugCell(row, col, "Removed candidate %d", value)
+		logger.CandidateElimination(row, col, value, "naked single")
+	})
+	if allocs != 0 {
+		t.Errorf("filtered DEBUG calls made %v allocation(s), want 0", allocs)
+	}
+}
@@ -1,6 +1,7 @@
 package lib_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/eftil/sudoku-solver.git/lib"
@@ -82,6 +83,47 @@ func TestCellNotifierNotifications(t *testing.T) {
 	}
 }
 
+// bulkObserver also takes several eliminations in one call
+type bulkObserver struct {
+	MockObserver
+	bulkCalls [][]int
+	remaining []int
+}
+
+func (bo *bulkObserver) OnCandidatesEliminated(row, col int, candidates []int, remainingCount int) {
+	bo.bulkCalls = append(bo.bulkCalls, candidates)
+	bo.remaining = append(bo.remaining, remainingCount)
+}
+
+func TestCellNotifierCandidatesEliminated(t *testing.T) {
+	notifier := observer.NewCellNotifier()
+	plain := &MockObserver{}
+	bulk := &bulkObserver{}
+	notifier.AddObserver(plain)
+	notifier.AddObserver(bulk)
+
+	notifier.NotifyCandidatesEliminated(2, 3, []int{1, 4, 7}, 5)
+
+	if len(bulk.bulkCalls) != 1 || len(bulk.candidateEliminatedCalls) != 0 {
+		t.Fatalf("CandidatesObserver got %d bulk and %d single calls, want 1 and 0",
+			len(bulk.bulkCalls), len(bulk.candidateEliminatedCalls))
+	}
+	if got := bulk.bulkCalls[0]; len(got) != 3 || got[0] != 1 || got[2] != 7 || bulk.remaining[0] != 5 {
+		t.Errorf("OnCandidatesEliminated got %v with %d remaining, want [1 4 7] with 5", got, bulk.remaining[0])
+	}
+
+	// Other observers get each candidate with the final count
+	if len(plain.candidateEliminatedCalls) != 3 {
+		t.Fatalf("Expected 3 OnCandidateEliminated calls, got %d", len(plain.candidateEliminatedCalls))
+	}
+	for i, want := range []int{1, 4, 7} {
+		call := plain.candidateEliminatedCalls[i]
+		if call.row != 2 || call.col != 3 || call.candidate != want || call.remainingCount != 5 {
+			t.Errorf("call %d = %+v, want candidate %d with 5 remaining", i, call, want)
+		}
+	}
+}
+
 func TestCellNotifierClearObservers(t *testing.T) {
 	notifier := observer.NewCellNotifier()
 	mock1 := &MockObserver{}
@@ -100,6 +142,126 @@ func TestCellNotifierClearObservers(t *testing.T) {
 	}
 }
 
+// PanickingObserver panics on every notification
+type PanickingObserver struct{}
+
+func (po *PanickingObserver) OnSingleCandidate(row, col, candidate int) {
+	panic("single candidate")
+}
+
+func (po *PanickingObserver) OnCellSolved(row, col, value int) {
+	panic("cell solved")
+}
+
+func (po *PanickingObserver) OnCandidateEliminated(row, col, candidate, remainingCount int) {
+	panic("candidate eliminated")
+}
+
+func TestCellNotifierRecoversFromPanickingObserver(t *testing.T) {
+	notifier := observer.NewCellNotifier()
+	before := &MockObserver{}
+	after := &MockObserver{}
+
+	notifier.AddObserver(before)
+	notifier.AddObserver(&PanickingObserver{})
+	notifier.AddObserver(after)
+
+	notifier.NotifySingleCandidate(0, 0, 1)
+	notifier.NotifyCellSolved(0, 0, 1)
+	notifier.NotifyCandidateEliminated(0, 1, 1, 8)
+
+	for name, mock := range map[string]*MockObserver{"before": before, "after": after} {
+		if len(mock.singleCandidateCalls) != 1 {
+			t.Errorf("observer %s: expected 1 single candidate notification, got %d", name, len(mock.singleCandidateCalls))
+		}
+		if len(mock.cellSolvedCalls) != 1 {
+			t.Errorf("observer %s: expected 1 cell solved notification, got %d", name, len(mock.cellSolvedCalls))
+		}
+		if len(mock.candidateEliminatedCalls) != 1 {
+			t.Errorf("observer %s: expected 1 candidate eliminated notification, got %d", name, len(mock.candidateEliminatedCalls))
+		}
+	}
+}
+
+func TestCellNotifierBatch(t *testing.T) {
+	notifier := observer.NewCellNotifier()
+	mock := &MockObserver{}
+	notifier.AddObserver(mock)
+
+	notifier.BeginBatch()
+	notifier.BeginBatch()
+	if !notifier.InBatch() {
+		t.Error("Notifier should be in a batch after BeginBatch")
+	}
+
+	notifier.NotifySingleCandidate(0, 0, 4)
+	notifier.NotifySingleCandidate(0, 0, 4)
+	notifier.NotifySingleCandidate(0, 0, 6)
+	notifier.NotifyCellSolved(0, 1, 2)
+	if len(mock.singleCandidateCalls) != 0 {
+		t.Errorf("Single candidate notifications should wait for the batch, got %d", len(mock.singleCandidateCalls))
+	}
+	if len(mock.cellSolvedCalls) != 1 {
+		t.Errorf("Cell solved notifications should not be deferred, got %d", len(mock.cellSolvedCalls))
+	}
+
+	notifier.EndBatch()
+	if len(mock.singleCandidateCalls) != 0 {
+		t.Errorf("Inner EndBatch should not flush, got %d notifications", len(mock.singleCandidateCalls))
+	}
+
+	notifier.EndBatch()
+	if notifier.InBatch() {
+		t.Error("Notifier should not be in a batch after the outer EndBatch")
+	}
+	if len(mock.singleCandidateCalls) != 2 {
+		t.Fatalf("Expected 2 flushed notifications, got %d", len(mock.singleCandidateCalls))
+	}
+	if mock.singleCandidateCalls[0].candidate != 4 || mock.singleCandidateCalls[1].candidate != 6 {
+		t.Errorf("Notifications flushed out of order: %+v", mock.singleCandidateCalls)
+	}
+
+	// An unbalanced EndBatch is ignored
+	notifier.EndBatch()
+	notifier.NotifySingleCandidate(0, 0, 9)
+	if len(mock.singleCandidateCalls) != 3 {
+		t.Errorf("Notifications outside a batch should be sent at once, got %d", len(mock.singleCandidateCalls))
+	}
+}
+
+// reentrantObserver notifies the same notifier again from every OnCellSolved
+type reentrantObserver struct {
+	notifier *observer.CellNotifier
+	calls    int
+}
+
+func (ro *reentrantObserver) OnSingleCandidate(row, col, candidate int) {}
+
+func (ro *reentrantObserver) OnCellSolved(row, col, value int) {
+	ro.calls++
+	ro.notifier.NotifyCellSolved(row, col, value)
+}
+
+func (ro *reentrantObserver) OnCandidateEliminated(row, col, candidate, remainingCount int) {}
+
+func TestCellNotifierBoundsReentrantNotifications(t *testing.T) {
+	notifier := observer.NewCellNotifier()
+	reentrant := &reentrantObserver{notifier: notifier}
+	notifier.AddObserver(reentrant)
+
+	notifier.NotifyCellSolved(0, 0, 1)
+	if reentrant.calls != observer.MaxNotifyDepth {
+		t.Errorf("Expected %d nested notifications, got %d", observer.MaxNotifyDepth, reentrant.calls)
+	}
+
+	// The depth unwinds, so the next notification goes through again
+	reentrant.calls = 0
+	notifier.NotifyCellSolved(0, 0, 1)
+	if reentrant.calls != observer.MaxNotifyDepth {
+		t.Errorf("Expected %d nested notifications after unwinding, got %d", observer.MaxNotifyDepth, reentrant.calls)
+	}
+}
+
 func TestAutoSolverObserver(t *testing.T) {
 	autoSolver := observer.NewAutoSolverObserver()
 
@@ -154,6 +316,41 @@ func TestAutoSolverObserverCellSolved(t *testing.T) {
 	}
 }
 
+func TestAutoSolverObserverProgressCounts(t *testing.T) {
+	autoSolver := observer.NewAutoSolverObserver()
+
+	// R1C1 is detected as a naked single (twice), then solved
+	autoSolver.OnCandidateEliminated(0, 0, 3, 2)
+	autoSolver.OnCandidateEliminated(0, 0, 4, 1)
+	autoSolver.OnSingleCandidate(0, 0, 5)
+	autoSolver.OnSingleCandidate(0, 0, 5)
+	autoSolver.OnCellSolved(0, 0, 5)
+
+	// R2C2 is set from outside without a detection
+	autoSolver.OnCellSolved(1, 1, 7)
+
+	if got := autoSolver.GetNakedSingleCount(); got != 1 {
+		t.Errorf("GetNakedSingleCount() = %d, want 1", got)
+	}
+	if got := autoSolver.GetExternalSetCount(); got != 1 {
+		t.Errorf("GetExternalSetCount() = %d, want 1", got)
+	}
+	if got := autoSolver.GetEliminationCount(); got != 2 {
+		t.Errorf("GetEliminationCount() = %d, want 2", got)
+	}
+	if got := autoSolver.GetSolutionCount(); got != 2 {
+		t.Errorf("GetSolutionCount() = %d, want 2", got)
+	}
+
+	// Disabled observers count nothing
+	autoSolver.Disable()
+	autoSolver.OnCandidateEliminated(2, 2, 1, 8)
+	autoSolver.OnSingleCandidate(3, 3, 1)
+	if autoSolver.GetEliminationCount() != 2 || autoSolver.GetNakedSingleCount() != 1 {
+		t.Error("disabled observer should not update its counts")
+	}
+}
+
 func TestAutoSolverObserverEnableDisable(t *testing.T) {
 	autoSolver := observer.NewAutoSolverObserver()
 
@@ -218,3 +415,109 @@ func TestBoardObserver(t *testing.T) {
 		t.Error("New observer should receive notifications")
 	}
 }
+
+func TestSolveRecorder(t *testing.T) {
+	board := newStandardBoard(t)
+	recorder := observer.NewSolveRecorder()
+	board.AddObserver(recorder)
+
+	setGrid(t, board, easyPuzzle)
+	if len(recorder.GetSteps()) != 0 {
+		t.Errorf("loading the puzzle is not a solving step, got %d step(s)", len(recorder.GetSteps()))
+	}
+
+	solved, _ := board.SolveLogically()
+	if !solved {
+		t.Fatal("easy puzzle should solve logically")
+	}
+
+	steps := recorder.GetSteps()
+	if len(steps) == 0 {
+		t.Fatal("GetSteps() returned no steps")
+	}
+
+	// Replaying the solves on the puzzle gives the solution
+	replay := newStandardBoard(t)
+	setGrid(t, replay, easyPuzzle)
+	for _, step := range steps {
+		if step.Technique == "" || len(step.Cells) != 1 {
+			t.Fatalf("step %+v should name its technique and cell", step)
+		}
+		idx := step.Cells[0]
+		switch step.Action {
+		case observer.ActionSolve:
+			replay.Set(idx/9, idx%9, step.Candidate)
+		case observer.ActionEliminate:
+			if step.Candidate < 1 || step.Candidate > 9 {
+				t.Errorf("step %+v eliminates an invalid candidate", step)
+			}
+		default:
+			t.Errorf("step %+v has unknown action", step)
+		}
+	}
+	if replay.String() != board.String() {
+		t.Errorf("replayed grid = %s, want %s", replay.String(), board.String())
+	}
+
+	// Singles explain themselves
+	if steps[0].Reason == "" {
+		t.Errorf("first step %+v should carry the technique's reason", steps[0])
+	}
+
+	recorder.Clear()
+	if len(recorder.GetSteps()) != 0 {
+		t.Error("Clear() should remove all steps")
+	}
+}
+
+func TestSolveOrderObserver(t *testing.T) {
+	tests := []struct {
+		name string
+		grid string
+	}{
+		{"logical solve", easyPuzzle},
+		{"solve finished by search", strings.Repeat("0", 81)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			order := observer.NewSolveOrderObserver()
+			board.AddObserver(order)
+
+			setGrid(t, board, tt.grid)
+			if len(order.GetOrder()) != 0 {
+				t.Errorf("loading the puzzle should not be recorded, got %v", order.GetOrder())
+			}
+
+			if solved, err := board.Solve(); err != nil || !solved {
+				t.Fatalf("Solve() = (%v, %v), want solved", solved, err)
+			}
+
+			// Every empty cell exactly once, with its final value
+			got := order.GetOrder()
+			if want := strings.Count(tt.grid, "0"); len(got) != want {
+				t.Fatalf("recorded %d cells, want %d", len(got), want)
+			}
+			seen := make(map[int]bool)
+			for _, cell := range got {
+				idx := cell.Row*9 + cell.Col
+				if seen[idx] {
+					t.Errorf("R%dC%d recorded twice", cell.Row+1, cell.Col+1)
+				}
+				seen[idx] = true
+				if tt.grid[idx] != '0' {
+					t.Errorf("given R%dC%d should not be recorded", cell.Row+1, cell.Col+1)
+				}
+				if want := board.Get(cell.Row, cell.Col); cell.Value != want {
+					t.Errorf("R%dC%d recorded as %d, solved as %d", cell.Row+1, cell.Col+1, cell.Value, want)
+				}
+			}
+
+			order.Clear()
+			if len(order.GetOrder()) != 0 {
+				t.Error("Clear() should remove all recorded cells")
+			}
+		})
+	}
+}
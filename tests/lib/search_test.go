@@ -0,0 +1,296 @@
+package lib_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+	"github.com/eftil/sudoku-solver.git/lib/logger"
+)
+
+const bugPlusOneSolution = "926817435438569172571324869354178296692453781817296543765941328189732654243685917"
+
+func TestBoardCountSolutions(t *testing.T) {
+	tests := []struct {
+		name  string
+		grid  string
+		limit int
+		want  int
+	}{
+		{"complete grid", bugPlusOneSolution, 2, 1},
+		{"unique puzzle", bugPlusOneGrid, 2, 1},
+		{"empty grid stops at limit", "000000000000000000000000000000000000000000000000000000000000000000000000000000000", 3, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			setGrid(t, board, tt.grid)
+			before := board.CandidatesString()
+
+			got, err := board.CountSolutions(tt.limit)
+			if err != nil {
+				t.Fatalf("CountSolutions() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CountSolutions(%d) = %d, want %d", tt.limit, got, tt.want)
+			}
+			if board.CandidatesString() != before {
+				t.Error("CountSolutions() should leave the board unchanged")
+			}
+		})
+	}
+}
+
+func TestBoardCountSolutionsKeepsSearchOutOfLog(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, bugPlusOneGrid)
+
+	out := captureLog(t, logger.Text, func() {
+		if _, err := board.CountSolutions(2); err != nil {
+			t.Fatalf("CountSolutions() returned error: %v", err)
+		}
+		if level := logger.GetLevel(); level != logger.DEBUG {
+			t.Errorf("log level after CountSolutions() = %v, want DEBUG", level)
+		}
+	})
+	if out != "" {
+		t.Errorf("CountSolutions() logged the search's trial moves:\n%s", out)
+	}
+
+	// The board's own logging is unaffected
+	out = captureLog(t, logger.Text, func() {
+		board.Set(0, 0, board.Get(0, 0))
+	})
+	if out == "" {
+		t.Error("the board should still log its own moves after a search")
+	}
+}
+
+func TestBoardCountSolutionsHonorsVariantConstraints(t *testing.T) {
+	tests := []struct {
+		name string
+		sum  int
+		want int
+	}{
+		{"cage matching the solution", 11, 1}, // R1C1 + R1C2 = 9 + 2
+		{"cage contradicting the solution", 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			cage, err := constraints.NewKillerCageConstraint([]int{0, 1}, tt.sum)
+			if err != nil {
+				t.Fatalf("NewKillerCageConstraint() returned error: %v", err)
+			}
+			board.AddConstraint(cage)
+			setGrid(t, board, bugPlusOneGrid)
+
+			got, err := board.CountSolutions(2)
+			if err != nil {
+				t.Fatalf("CountSolutions() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CountSolutions(2) = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoardCountSolutionsInvalidLimit(t *testing.T) {
+	board := newStandardBoard(t)
+	if _, err := board.CountSolutions(0); err == nil {
+		t.Error("expected error for limit 0, got none")
+	}
+}
+
+func TestBoardMinimalClues(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, bugPlusOneSolution)
+	board.SetRandomSeed(42)
+
+	puzzle := board.MinimalClues()
+	if puzzle == nil {
+		t.Fatal("MinimalClues() returned nil")
+	}
+
+	clues := 0
+	for i := 0; i < 81; i++ {
+		value := puzzle.Get(i/9, i%9)
+		if given := puzzle.GetCell(i).IsGiven(); given != (value != 0) {
+			t.Errorf("R%dC%d IsGiven() = %v with value %d, want clues and only clues marked as givens", i/9+1, i%9+1, given, value)
+		}
+		if value == 0 {
+			continue
+		}
+		clues++
+		if want := int(bugPlusOneSolution[i] - '0'); value != want {
+			t.Errorf("clue at R%dC%d = %d, want %d from the solution", i/9+1, i%9+1, value, want)
+		}
+	}
+	if clues < 17 || clues >= 81 {
+		t.Errorf("unexpected clue count %d", clues)
+	}
+
+	count, err := puzzle.CountSolutions(2)
+	if err != nil {
+		t.Fatalf("CountSolutions() returned error: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("minimal puzzle has %d solutions, want 1", count)
+	}
+
+	// Same seed, same puzzle
+	again := newStandardBoard(t)
+	setGrid(t, again, bugPlusOneSolution)
+	again.SetRandomSeed(42)
+	if other := again.MinimalClues(); other == nil || other.CandidatesString() != puzzle.CandidatesString() {
+		t.Error("MinimalClues() should be reproducible with the same seed")
+	}
+}
+
+func TestBoardLikelyUnique(t *testing.T) {
+	tests := []struct {
+		name           string
+		grid           string
+		wantUnique     bool
+		wantConclusive bool
+	}{
+		{"unique puzzle", bugPlusOneGrid, true, true},
+		{"ambiguous puzzle", "000000000000000000000000000000000000000000000000000000000000000000000000000000000", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			setGrid(t, board, tt.grid)
+
+			unique, conclusive := board.LikelyUnique(10 * time.Second)
+			if unique != tt.wantUnique || conclusive != tt.wantConclusive {
+				t.Errorf("LikelyUnique() = (%v, %v), want (%v, %v)", unique, conclusive, tt.wantUnique, tt.wantConclusive)
+			}
+		})
+	}
+}
+
+func TestBoardLikelyUniqueTimeout(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, bugPlusOneGrid)
+
+	// An expired budget stops the search before it can conclude anything
+	if _, conclusive := board.LikelyUnique(0); conclusive {
+		t.Error("expected a non-conclusive result with no time budget")
+	}
+}
+
+func TestBoardGivensOnly(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, easyPuzzle)
+	for i := 0; i < 81; i++ {
+		if easyPuzzle[i] != '0' {
+			board.GetCell(i).MarkGiven()
+		}
+	}
+	if _, err := board.SolveUntilCell(4, 4); err != nil {
+		t.Fatalf("SolveUntilCell() returned error: %v", err)
+	}
+
+	givens := board.GivensOnly()
+	for i := 0; i < 81; i++ {
+		want := int(easyPuzzle[i] - '0')
+		if got := givens.Get(i/9, i%9); got != want {
+			t.Errorf("R%dC%d = %d, want %d", i/9+1, i%9+1, got, want)
+		}
+		if got := givens.GetCell(i).IsGiven(); got != (want != 0) {
+			t.Errorf("R%dC%d IsGiven() = %v, want %v", i/9+1, i%9+1, got, want != 0)
+		}
+	}
+
+	// Candidates come from the givens alone, not from the solving progress
+	fresh := newStandardBoard(t)
+	setGrid(t, fresh, easyPuzzle)
+	if givens.CandidatesString() != fresh.CandidatesString() {
+		t.Error("GivensOnly() candidates should match a board loaded with just the givens")
+	}
+	if len(givens.GetConstraints()) != len(board.GetConstraints()) {
+		t.Errorf("GivensOnly() has %d constraints, want %d", len(givens.GetConstraints()), len(board.GetConstraints()))
+	}
+
+	// The copy is independent of the original
+	before := board.Get(0, 2)
+	givens.Set(0, 2, 9)
+	if board.Get(0, 2) != before {
+		t.Error("changing the givens board should not affect the original")
+	}
+}
+
+func TestBoardClone(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, easyPuzzle)
+	board.GetCell(0).MarkGiven()
+	mock := &MockObserver{}
+	board.AddObserver(mock)
+
+	clone := board.Clone()
+	if clone.String() != board.String() || clone.CandidatesString() != board.CandidatesString() {
+		t.Fatal("Clone() should copy values and candidates")
+	}
+	if !clone.GetCell(0).IsGiven() {
+		t.Error("Clone() should copy givens")
+	}
+	if len(clone.GetConstraints()) != len(board.GetConstraints()) {
+		t.Errorf("Clone() has %d constraints, want %d", len(clone.GetConstraints()), len(board.GetConstraints()))
+	}
+
+	// Set a value on the clone; constraints propagate on the clone only
+	values, candidates := board.String(), board.CandidatesString()
+	if err := clone.Set(0, 2, 4); err != nil {
+		t.Fatalf("Set() on clone returned error: %v", err)
+	}
+	if clone.GetCell(5).HasCandidate(4) || !board.GetCell(5).HasCandidate(4) {
+		t.Error("clone constraints should remove 4 from the clone's row only")
+	}
+	if board.String() != values || board.CandidatesString() != candidates {
+		t.Error("changing the clone should not affect the original")
+	}
+	if len(mock.cellSolvedCalls) != 0 || len(mock.candidateEliminatedCalls) != 0 {
+		t.Error("observers of the original should not be carried over to the clone")
+	}
+}
+
+func TestBoardSnapshotRestore(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, easyPuzzle)
+
+	snapshot := board.Snapshot()
+	values := board.String()
+	candidates := board.CandidatesString()
+
+	board.Set(0, 2, 4)
+	board.GetCellAt(8, 0).RemoveCandidate(3)
+
+	mock := &MockObserver{}
+	board.GetCellAt(0, 2).AddObserver(mock)
+	board.Restore(snapshot)
+
+	if board.String() != values {
+		t.Errorf("Restore() values = %s, want %s", board.String(), values)
+	}
+	if board.CandidatesString() != candidates {
+		t.Error("Restore() should bring back the candidates exactly as saved")
+	}
+	if len(mock.cellSolvedCalls)+len(mock.candidateEliminatedCalls)+len(mock.singleCandidateCalls) != 0 {
+		t.Error("Restore() should not notify observers")
+	}
+	if snapshot.Value(0) != 5 || snapshot.Value(2) != 0 {
+		t.Errorf("snapshot values R1C1 = %d, R1C3 = %d, want 5 and 0", snapshot.Value(0), snapshot.Value(2))
+	}
+
+	// A snapshot can be restored more than once
+	board.Set(0, 2, 4)
+	board.Restore(snapshot)
+	if board.Get(0, 2) != 0 {
+		t.Error("restoring the same snapshot again should clear R1C3")
+	}
+}
@@ -0,0 +1,557 @@
+package lib_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eftil/sudoku-solver.git/lib"
+	"github.com/eftil/sudoku-solver.git/lib/constraints"
+	"github.com/eftil/sudoku-solver.git/lib/observer"
+)
+
+const (
+	easyPuzzle   = "530070000600195000098000060800060003400803001700020006060000280000419005000080079"
+	easySolution = "534678912672195348198342567859761423426853791713924856961537284287419635345286179"
+
+	// xWingPuzzle needs an X-Wing before R9C9 can be reached
+	xWingPuzzle = "000000094760910050090002081070050010000709000080030060240100070010090045900000000"
+
+	// hardPuzzle stalls the logical techniques and needs the search
+	hardPuzzle = "800000000003600000070090200050007000000045700000100030001000068008500010090000400"
+)
+
+func TestBoardSolveUntilCell(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, easyPuzzle)
+
+	result, err := board.SolveUntilCell(4, 4)
+	if err != nil {
+		t.Fatalf("SolveUntilCell() returned error: %v", err)
+	}
+	if !result.Solved {
+		t.Fatal("expected the target cell to be reached by logic")
+	}
+	if got := board.Get(4, 4); got != 5 {
+		t.Errorf("R5C5 = %d, want 5", got)
+	}
+	if len(result.Steps) == 0 {
+		t.Fatal("expected a non-empty step trace")
+	}
+
+	// The last step is the one that placed the target
+	last := result.Steps[len(result.Steps)-1]
+	placedTarget := false
+	for _, change := range last.Changes {
+		if change.Index == 40 && change.Value == 5 {
+			placedTarget = true
+		}
+	}
+	if !placedTarget {
+		t.Errorf("last step %q should place R5C5, got %+v", last.Technique, last.Changes)
+	}
+
+	for i, step := range result.Steps {
+		if step.Technique == "" || len(step.Changes) == 0 {
+			t.Errorf("step %d has no technique or changes: %+v", i, step)
+		}
+	}
+
+	// Solving stops at the target, and everything placed so far matches the solution
+	empty := 0
+	for i := 0; i < 81; i++ {
+		value := board.Get(i/9, i%9)
+		if value == 0 {
+			empty++
+			continue
+		}
+		if want := int(easySolution[i] - '0'); value != want {
+			t.Errorf("cell %d = %d, want %d", i, value, want)
+		}
+	}
+	if empty == 0 {
+		t.Error("solving should stop once the target is set, not complete the grid")
+	}
+}
+
+func TestBoardSolveUntilCellStalls(t *testing.T) {
+	board := newStandardBoard(t)
+
+	result, err := board.SolveUntilCell(0, 0)
+	if err != nil {
+		t.Fatalf("SolveUntilCell() returned error: %v", err)
+	}
+	if result.Solved {
+		t.Error("an empty board cannot be solved logically")
+	}
+	if board.Get(0, 0) != 0 {
+		t.Error("target cell should remain empty")
+	}
+}
+
+func TestBoardSolveUntilCellInvalidPosition(t *testing.T) {
+	board := newStandardBoard(t)
+	if _, err := board.SolveUntilCell(9, 0); err == nil {
+		t.Error("expected error for invalid position")
+	}
+}
+
+func TestBoardSolveTraceIsDeterministic(t *testing.T) {
+	solve := func() []lib.SolveStep {
+		board := newStandardBoard(t)
+		setGrid(t, board, xWingPuzzle)
+		result, err := board.SolveUntilCell(8, 8)
+		if err != nil {
+			t.Fatalf("SolveUntilCell() returned error: %v", err)
+		}
+		return result.Steps
+	}
+
+	first := solve()
+	usesXWing := false
+	for _, step := range first {
+		if step.Technique == "X-Wing" {
+			usesXWing = true
+		}
+	}
+	if !usesXWing {
+		t.Fatal("expected the trace to include an X-Wing step")
+	}
+
+	for run := 0; run < 10; run++ {
+		if steps := solve(); !reflect.DeepEqual(steps, first) {
+			t.Fatalf("run %d produced a different trace:\n%+v\nwant:\n%+v", run+2, steps, first)
+		}
+	}
+}
+
+func TestBoardSolve(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, easyPuzzle)
+
+	solved, err := board.Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned error: %v", err)
+	}
+	if !solved {
+		t.Fatal("expected the puzzle to be solved")
+	}
+	for i := 0; i < 81; i++ {
+		if got, want := board.Get(i/9, i%9), int(easySolution[i]-'0'); got != want {
+			t.Errorf("R%dC%d = %d, want %d", i/9+1, i%9+1, got, want)
+		}
+	}
+}
+
+func TestBoardSolveWithVariantConstraints(t *testing.T) {
+	// An empty grid stalls logic, so the search has to respect the variant constraints
+	board := newStandardBoard(t)
+	cage, err := constraints.NewKillerCageConstraint([]int{0, 1}, 17)
+	if err != nil {
+		t.Fatalf("NewKillerCageConstraint() returned error: %v", err)
+	}
+	renban, err := constraints.NewRenbanConstraint([]int{9, 10, 11})
+	if err != nil {
+		t.Fatalf("NewRenbanConstraint() returned error: %v", err)
+	}
+	board.AddConstraint(cage)
+	board.AddConstraint(renban)
+
+	solved, err := board.Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned error: %v", err)
+	}
+	if !solved {
+		t.Fatal("expected the board to be solved")
+	}
+
+	if sum := board.Get(0, 0) + board.Get(0, 1); sum != 17 {
+		t.Errorf("cage sum = %d, want 17", sum)
+	}
+	valid, err := board.ValidateAll()
+	if err != nil || !valid {
+		t.Errorf("ValidateAll() = %v, %v after Solve()", valid, err)
+	}
+}
+
+func TestBoardSolveNoSolution(t *testing.T) {
+	// R1C9 can only be 9, but column 9 already has a 9
+	board := newStandardBoard(t)
+	setGrid(t, board, "123456780000000009000000000000000000000000000000000000000000000000000000000000000")
+	before := board.CandidatesString()
+
+	solved, err := board.Solve()
+	if err != nil {
+		t.Fatalf("Solve() returned error: %v", err)
+	}
+	if solved {
+		t.Error("expected no solution")
+	}
+	if board.CandidatesString() != before || board.Get(0, 8) != 0 {
+		t.Error("Solve() should restore the original board when there is no solution")
+	}
+}
+
+// countdownContext reports cancellation once Err has been checked more than n times
+type countdownContext struct {
+	context.Context
+	n int
+}
+
+func (c *countdownContext) Err() error {
+	if c.n <= 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+func TestBoardSolveContext(t *testing.T) {
+	board := newStandardBoard(t)
+	solved, err := board.SolveContext(context.Background())
+	if err != nil || !solved {
+		t.Fatalf("SolveContext() = %v, %v, want a solved empty grid", solved, err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	board = newStandardBoard(t)
+	setGrid(t, board, easyPuzzle)
+	before := board.CandidatesString()
+	if solved, err := board.SolveContext(cancelled); err != context.Canceled || solved {
+		t.Errorf("SolveContext() with a cancelled context = %v, %v, want false, %v", solved, err, context.Canceled)
+	}
+	if board.CandidatesString() != before {
+		t.Error("a cancelled solve should leave the board unchanged")
+	}
+}
+
+func TestBoardSolveContextCancelledDuringSearch(t *testing.T) {
+	// Logic can't start on an empty grid, so the search runs; the context gives out a
+	// few nodes in and the next node must notice
+	board := newStandardBoard(t)
+	ctx := &countdownContext{Context: context.Background(), n: 5}
+
+	solved, err := board.SolveContext(ctx)
+	if err != context.Canceled || solved {
+		t.Errorf("SolveContext() = %v, %v, want false, %v", solved, err, context.Canceled)
+	}
+	if board.TotalCandidates() != 729 {
+		t.Error("a cancelled search should restore the board")
+	}
+}
+
+func TestBoardSolveSearchHidesTrialValues(t *testing.T) {
+	// The search's guesses and rollbacks stay on a copy; observers only see the solution
+	board := newStandardBoard(t)
+	setGrid(t, board, hardPuzzle)
+	mock := &MockObserver{}
+	board.AddObserver(mock)
+
+	result, err := board.SolveHybrid()
+	if err != nil || !result.Solved || !result.ComputerAssisted {
+		t.Fatalf("SolveHybrid() = %+v, %v, want a search-assisted solve", result, err)
+	}
+	if want := strings.Count(hardPuzzle, "0"); len(mock.cellSolvedCalls) != want {
+		t.Errorf("observer saw %d solved cells, want %d", len(mock.cellSolvedCalls), want)
+	}
+	for _, call := range mock.cellSolvedCalls {
+		if board.Get(call.row, call.col) != call.value {
+			t.Errorf("observer saw R%dC%d = %d, board holds %d",
+				call.row+1, call.col+1, call.value, board.Get(call.row, call.col))
+		}
+	}
+}
+
+func TestBoardSolveHybrid(t *testing.T) {
+	tests := []struct {
+		name         string
+		grid         string
+		wantAssisted bool
+	}{
+		{"logic is enough", easyPuzzle, false},
+		{"logic stalls on an empty grid", "000000000000000000000000000000000000000000000000000000000000000000000000000000000", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			setGrid(t, board, tt.grid)
+
+			result, err := board.SolveHybrid()
+			if err != nil {
+				t.Fatalf("SolveHybrid() returned error: %v", err)
+			}
+			if !result.Solved {
+				t.Fatal("expected the board to be solved")
+			}
+			if result.ComputerAssisted != tt.wantAssisted {
+				t.Errorf("ComputerAssisted = %v, want %v", result.ComputerAssisted, tt.wantAssisted)
+			}
+
+			searchSteps := 0
+			for i, step := range result.Steps {
+				if step.Technique != lib.SearchTechnique {
+					continue
+				}
+				searchSteps++
+				if i != len(result.Steps)-1 {
+					t.Errorf("search step at %d should be the last of %d", i, len(result.Steps))
+				}
+				for _, change := range step.Changes {
+					if change.Value == 0 {
+						t.Errorf("search step should only place values, got %+v", change)
+					}
+				}
+			}
+			if want := map[bool]int{false: 0, true: 1}[tt.wantAssisted]; searchSteps != want {
+				t.Errorf("got %d search step(s), want %d", searchSteps, want)
+			}
+
+			valid, err := board.ValidateAll()
+			if err != nil || !valid {
+				t.Errorf("ValidateAll() = %v, %v after SolveHybrid()", valid, err)
+			}
+		})
+	}
+}
+
+func TestBoardSolveLogically(t *testing.T) {
+	tests := []struct {
+		name   string
+		puzzle string
+		solved bool
+	}{
+		{"singles only", easyPuzzle, true},
+		{"empty grid stalls", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newStandardBoard(t)
+			if tt.puzzle != "" {
+				setGrid(t, board, tt.puzzle)
+			}
+
+			solved, iterations := board.SolveLogically()
+			if solved != tt.solved {
+				t.Fatalf("SolveLogically() solved = %v, want %v", solved, tt.solved)
+			}
+			if iterations != board.StepCount() {
+				t.Errorf("SolveLogically() iterations = %d, StepCount() = %d", iterations, board.StepCount())
+			}
+			if !solved {
+				return
+			}
+
+			valid, err := board.ValidateAll()
+			if err != nil || !valid {
+				t.Errorf("solved grid should be valid, got %v (%v)", valid, err)
+			}
+			if tt.puzzle == easyPuzzle && board.String() != easySolution {
+				t.Errorf("SolveLogically() = %s, want %s", board.String(), easySolution)
+			}
+		})
+	}
+}
+
+func TestBoardStepCount(t *testing.T) {
+	board := newStandardBoard(t)
+	if got := board.StepCount(); got != 0 {
+		t.Errorf("StepCount() before solving = %d, want 0", got)
+	}
+
+	setGrid(t, board, easyPuzzle)
+	result, err := board.SolveHybrid()
+	if err != nil {
+		t.Fatalf("SolveHybrid() returned error: %v", err)
+	}
+	if got := board.StepCount(); got != len(result.Steps) || got == 0 {
+		t.Errorf("StepCount() = %d, want %d", got, len(result.Steps))
+	}
+
+	// The search that finishes a stalled solve is not a logical step
+	empty := newStandardBoard(t)
+	result, err = empty.SolveHybrid()
+	if err != nil {
+		t.Fatalf("SolveHybrid() returned error: %v", err)
+	}
+	if !result.ComputerAssisted {
+		t.Fatal("expected the empty grid to need a search")
+	}
+	if got := empty.StepCount(); got != len(result.Steps)-1 {
+		t.Errorf("StepCount() = %d, want %d", got, len(result.Steps)-1)
+	}
+}
+
+func TestBoardNextHint(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, easyPuzzle)
+	before, candidates := board.String(), board.TotalCandidates()
+
+	hint, found := board.NextHint()
+	if !found {
+		t.Fatal("expected a hint for the easy puzzle")
+	}
+	if hint.Action != observer.ActionSolve || len(hint.Cells) != 1 || len(hint.Candidates) != 1 {
+		t.Fatalf("expected a single value to place, got %+v", hint)
+	}
+	switch hint.Technique {
+	case "Full House", "Naked Single", "Hidden Single":
+	default:
+		t.Errorf("the easy puzzle should start with a single, got %s", hint.Technique)
+	}
+	if want := int(easySolution[hint.Cells[0]] - '0'); hint.Candidates[0] != want {
+		t.Errorf("hint places %d in cell %d, solution has %d", hint.Candidates[0], hint.Cells[0], want)
+	}
+	if hint.Explanation == "" {
+		t.Error("hint should explain the deduction")
+	}
+	if board.String() != before || board.TotalCandidates() != candidates {
+		t.Error("NextHint changed the board")
+	}
+
+	// Applying the hint and asking again makes progress
+	board.Set(hint.Cells[0]/9, hint.Cells[0]%9, hint.Candidates[0])
+	if next, found := board.NextHint(); !found || reflect.DeepEqual(next, hint) {
+		t.Errorf("expected a new hint after applying the first, got %+v", next)
+	}
+}
+
+func TestBoardNextHintEscalates(t *testing.T) {
+	board := newStandardBoard(t)
+
+	// Nothing simpler than the X-Wing on 5 in rows 1 and 5 applies
+	for _, row := range []int{0, 4} {
+		for col := 0; col < 9; col++ {
+			if col != 0 && col != 4 {
+				board.GetCellAt(row, col).RemoveCandidate(5)
+			}
+		}
+	}
+
+	hint, found := board.NextHint()
+	if !found {
+		t.Fatal("expected the X-Wing hint")
+	}
+	if hint.Technique != "X-Wing" || hint.Action != observer.ActionEliminate {
+		t.Fatalf("expected an X-Wing elimination, got %+v", hint)
+	}
+	if !reflect.DeepEqual(hint.Candidates, []int{5}) || len(hint.Cells) != 14 {
+		t.Errorf("expected 5 removed from 14 cells, got %v from %v", hint.Candidates, hint.Cells)
+	}
+	for _, idx := range hint.Cells {
+		if !board.GetCell(idx).HasCandidate(5) {
+			t.Errorf("NextHint removed 5 from cell %d", idx)
+		}
+	}
+}
+
+func TestBoardNextHintNakedPair(t *testing.T) {
+	board := newStandardBoard(t)
+	for _, idx := range []int{0, 1} {
+		for digit := 3; digit <= 9; digit++ {
+			board.GetCell(idx).RemoveCandidate(digit)
+		}
+	}
+
+	// The pair sits in row 1 and box 1; the hint covers one of them only
+	hint, found := board.NextHint()
+	if !found {
+		t.Fatal("expected the naked pair hint")
+	}
+	if hint.Technique != "Naked Pair" || hint.Action != observer.ActionEliminate {
+		t.Fatalf("expected a naked pair elimination, got %+v", hint)
+	}
+	if !reflect.DeepEqual(hint.Candidates, []int{1, 2}) || len(hint.Cells) != 7 {
+		t.Errorf("expected 1 and 2 removed from 7 cells, got %v from %v", hint.Candidates, hint.Cells)
+	}
+	if !strings.Contains(hint.Explanation, "R1C1, R1C2 can only hold [1 2]") {
+		t.Errorf("explanation should name the pair and its digits, got %q", hint.Explanation)
+	}
+	for _, idx := range hint.Cells {
+		if idx == 0 || idx == 1 {
+			t.Errorf("the pair's own cell %d should not lose candidates", idx)
+		}
+	}
+}
+
+func TestBoardNextHintNone(t *testing.T) {
+	if _, found := newStandardBoard(t).NextHint(); found {
+		t.Error("an empty board has no logical step")
+	}
+
+	board := newStandardBoard(t)
+	setGrid(t, board, easySolution)
+	if _, found := board.NextHint(); found {
+		t.Error("a complete board has no hint")
+	}
+}
+
+func TestBoardApplyHint(t *testing.T) {
+	board := newStandardBoard(t)
+	setGrid(t, board, easyPuzzle)
+	recorder := observer.NewSolveRecorder()
+	board.AddObserver(recorder)
+
+	hint, _ := board.NextHint()
+	if err := board.ApplyHint(hint); err != nil {
+		t.Fatalf("ApplyHint() returned error: %v", err)
+	}
+	idx := hint.Cells[0]
+	if got := board.Get(idx/9, idx%9); got != hint.Candidates[0] {
+		t.Errorf("cell %d = %d, want %d", idx, got, hint.Candidates[0])
+	}
+	steps := recorder.GetSteps()
+	if len(steps) == 0 || steps[len(steps)-1].Technique != hint.Technique {
+		t.Errorf("observers should see the hint's technique, got %+v", steps)
+	}
+
+	// The same hint again no longer fits the board
+	if err := board.ApplyHint(hint); err == nil {
+		t.Error("applying a stale hint should fail")
+	}
+	if err := board.ApplyHint(nil); err == nil {
+		t.Error("applying a nil hint should fail")
+	}
+	if err := board.ApplyHint(&lib.Hint{Action: "guess"}); err == nil {
+		t.Error("applying a hint with an unknown action should fail")
+	}
+}
+
+func TestBoardApplyHintEliminations(t *testing.T) {
+	board := newStandardBoard(t)
+	for _, row := range []int{0, 4} {
+		for col := 0; col < 9; col++ {
+			if col != 0 && col != 4 {
+				board.GetCellAt(row, col).RemoveCandidate(5)
+			}
+		}
+	}
+
+	hint, _ := board.NextHint()
+	stale := *hint
+
+	// A candidate removed underneath the hint makes it stale, and nothing is applied
+	board.GetCell(hint.Cells[0]).RemoveCandidate(5)
+	if err := board.ApplyHint(&stale); err == nil {
+		t.Fatal("applying a hint after the board changed should fail")
+	}
+	if !board.GetCell(hint.Cells[1]).HasCandidate(5) {
+		t.Error("a failed ApplyHint should not remove any candidates")
+	}
+
+	board.GetCell(hint.Cells[0]).AddCandidate(5)
+	if err := board.ApplyHint(hint); err != nil {
+		t.Fatalf("ApplyHint() returned error: %v", err)
+	}
+	for _, idx := range hint.Cells {
+		if board.GetCell(idx).HasCandidate(5) {
+			t.Errorf("cell %d still has candidate 5", idx)
+		}
+	}
+	if !board.GetCellAt(1, 1).HasCandidate(5) {
+		t.Error("cells outside the hint should keep their candidates")
+	}
+}